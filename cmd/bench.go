@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"the-gym-app/internal/services"
+	"the-gym-app/internal/services/storebench"
+)
+
+// runBench implements the bench subcommand, which measures the workout read path
+// against a throwaway SQLite database, and against the postgres database of
+// GYM_APP_CONFORMANCE_POSTGRES_DSN after wiping it when that is set:
+//
+//	bench [-sizes 10,100,1000] [-runs 5]
+//
+// It fails when a call needs more queries for a longer history.
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	sizeList := flags.String("sizes", "10,100,1000", "comma separated numbers of workouts to seed")
+	runs := flags.Int("runs", 5, "times each call is measured")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	var sizes []int
+	for _, value := range strings.Split(*sizeList, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || size <= 0 {
+			return fmt.Errorf("sizes must be positive numbers")
+		}
+		sizes = append(sizes, size)
+	}
+	if *runs <= 0 {
+		return fmt.Errorf("runs must be positive")
+	}
+
+	dir, err := os.MkdirTemp("", "gym-app-bench")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	backends := []services.Config{{Driver: string(services.DialectSQLite), DSN: filepath.Join(dir, "bench.db")}}
+	if dsn := os.Getenv(conformancePostgresEnv); dsn != "" {
+		backends = append(backends, services.Config{Driver: string(services.DialectPostgres), DSN: dsn})
+	}
+
+	var failed bool
+	for _, config := range backends {
+		log.Printf("benchmarking %s", config.Driver)
+		if err := runBenchOn(config, sizes, *runs); err != nil {
+			log.Printf("%s: %v", config.Driver, err)
+			failed = true
+		}
+	}
+	if failed {
+		return fmt.Errorf("bench failed")
+	}
+	return nil
+}
+
+func runBenchOn(config services.Config, sizes []int, runs int) error {
+	dbService, err := services.NewDatabaseService(config)
+	if err != nil {
+		return err
+	}
+	defer dbService.Close()
+
+	if err := dbService.Reset(); err != nil {
+		return err
+	}
+	_, err = storebench.Run(dbService, sizes, runs, log.Printf)
+	return err
+}
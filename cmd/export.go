@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"the-gym-app/internal/exporter"
+	"the-gym-app/internal/services"
+)
+
+const exportUsage = "usage: export -user USERNAME [-format csv|json|zip] [-o FILE]"
+
+// runExport implements the export subcommand, which writes the data of any user in
+// the formats of /api/export, to stdout unless an output file is given:
+//
+//	export -user alice -format zip -o alice.zip
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	username := flags.String("user", "", "username to export the data of")
+	format := flags.String("format", "json", "export format: csv, json or zip")
+	output := flags.String("o", "", "file to write to instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" || flags.NArg() != 0 {
+		return fmt.Errorf(exportUsage)
+	}
+	exportFormat, err := exporter.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	dbService, err := services.NewDatabaseService(services.ConfigFromEnv())
+	if err != nil {
+		return err
+	}
+	defer dbService.Close()
+	userID, err := dbService.GetUserIdFromUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %s: %v", *username, err)
+	}
+
+	exportService := services.NewExportService(dbService)
+	if *output == "" {
+		return exportService.Export(userID, *username, exportFormat, os.Stdout)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := exportService.Export(userID, *username, exportFormat, file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
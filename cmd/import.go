@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"the-gym-app/internal/importer"
+	"the-gym-app/internal/services"
+	"time"
+)
+
+const importUsage = "usage: import -user USERNAME [-format strong|hevy|fitnotes] [-weight-unit kg|lb] [-distance-unit km|mi] [-timezone ZONE] [-mapping FILE] [-dry-run] FILE"
+
+// runImport implements the import subcommand, which loads a Strong, Hevy or FitNotes
+// CSV export into the history of an existing user and prints the import report:
+//
+//	import -user alice -weight-unit lb strong.csv
+//	import -user alice -dry-run -mapping names.json hevy.csv
+//
+// The mapping file is a JSON object from exported exercise names to catalog names.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	username := flags.String("user", "", "username to import the workouts for")
+	format := flags.String("format", "", "export format, detected from the header when empty")
+	weightUnit := flags.String("weight-unit", "kg", "unit to store weights in; the unit of Strong exports")
+	distanceUnit := flags.String("distance-unit", "km", "distance unit of Strong exports")
+	timezone := flags.String("timezone", "UTC", "time zone of the timestamps in the export")
+	mappingFile := flags.String("mapping", "", "JSON file mapping exported exercise names to catalog names")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without writing it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" || flags.NArg() != 1 {
+		return fmt.Errorf(importUsage)
+	}
+
+	var opts services.ImportOptions
+	var err error
+	if opts.Format, err = importer.ParseFormat(*format); err != nil {
+		return err
+	}
+	opts.WeightUnit = *weightUnit
+	opts.DistanceUnit = *distanceUnit
+	opts.DryRun = *dryRun
+	if opts.Location, err = time.LoadLocation(*timezone); err != nil {
+		return err
+	}
+	if *mappingFile != "" {
+		data, err := os.ReadFile(*mappingFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &opts.Mapping); err != nil {
+			return fmt.Errorf("mapping file: %v", err)
+		}
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dbService, err := services.NewDatabaseService(services.ConfigFromEnv())
+	if err != nil {
+		return err
+	}
+	defer dbService.Close()
+	userID, err := dbService.GetUserIdFromUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %s: %v", *username, err)
+	}
+
+	report, err := services.NewImportService(dbService).Import(userID, file, opts)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -7,18 +7,66 @@ import (
 	"net/http"
 	"the-gym-app/internal/handlers"
 	"the-gym-app/internal/middleware"
+	"the-gym-app/internal/models"
 	"the-gym-app/internal/services"
+	"time"
 )
 
 func main() {
 	//capturing flag for db cleanup
 	var cleanup bool
-	flag.BoolVar(&cleanup, "cleanup", false, "Delete db structure and data")
+	flag.BoolVar(&cleanup, "cleanup", false, "Delete all logged workouts, keeping users and settings")
+	//capturing flag to bootstrap the first admin
+	var promoteAdmin string
+	flag.StringVar(&promoteAdmin, "promote-admin", "", "Give the admin role to an existing username")
+	//capturing flag for how often training is scanned for plateaus
+	var insightInterval time.Duration
+	flag.DurationVar(&insightInterval, "insight-interval", 6*time.Hour, "How often to scan every user for plateaus and regressions, 0 to disable")
 
 	flag.Parse()
 
+	//migrate subcommand manages the schema without starting the server
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(flag.Args()[1:]); err != nil {
+			log.Fatal("migrate: ", err)
+		}
+		return
+	}
+
+	//conformance subcommand checks the storage backends against the shared suite
+	if flag.Arg(0) == "conformance" {
+		if err := runConformance(); err != nil {
+			log.Fatal("conformance: ", err)
+		}
+		return
+	}
+
+	//bench subcommand checks that reading workouts takes a fixed number of queries
+	if flag.Arg(0) == "bench" {
+		if err := runBench(flag.Args()[1:]); err != nil {
+			log.Fatal("bench: ", err)
+		}
+		return
+	}
+
+	//import subcommand loads another app's export into a user's history
+	if flag.Arg(0) == "import" {
+		if err := runImport(flag.Args()[1:]); err != nil {
+			log.Fatal("import: ", err)
+		}
+		return
+	}
+
+	//export subcommand writes a user's data for admins, e.g. for data access requests
+	if flag.Arg(0) == "export" {
+		if err := runExport(flag.Args()[1:]); err != nil {
+			log.Fatal("export: ", err)
+		}
+		return
+	}
+
 	//Initialise database
-	dbService, err := services.NewDatabaseService()
+	dbService, err := services.NewDatabaseService(services.ConfigFromEnv())
 	if err != nil {
 		log.Fatal("Failed to initialize database: ", err)
 	}
@@ -32,20 +80,60 @@ func main() {
 		}
 	}
 
+	//promote a user to admin, there is no other way to create the first one
+	if promoteAdmin != "" {
+		userID, err := dbService.GetUserIdFromUsername(promoteAdmin)
+		if err != nil {
+			log.Fatal("Failed to promote admin : ", err)
+		}
+		roles, err := dbService.GetUserRoles(promoteAdmin)
+		if err != nil {
+			log.Fatal("Failed to promote admin : ", err)
+		}
+		if !models.HasRole(roles, models.RoleAdmin) {
+			roles = append(roles, models.RoleAdmin)
+		}
+		if err := dbService.SetUserRoles(userID, roles); err != nil {
+			log.Fatal("Failed to promote admin : ", err)
+		}
+		log.Printf("%s now has roles %v", promoteAdmin, roles)
+	}
+
+	//let the jwt middleware reject tokens revoked on logout
+	middleware.SetRevocationChecker(dbService)
+
 	//Initialise handlers
-	workoutHandler := handlers.NewWorkoutHandler(dbService)
+	workoutHandler := handlers.NewWorkoutHandler(dbService, services.NewRecordService(dbService))
 	loginHandler := handlers.NewLoginHandler(dbService)
+	adminHandler := handlers.NewAdminHandler(dbService)
+	coachHandler := handlers.NewCoachHandler(dbService)
+	profileHandler := handlers.NewProfileHandler(dbService)
+	exerciseHandler := handlers.NewExerciseHandler(dbService)
+	templateHandler := handlers.NewTemplateHandler(dbService, services.NewTemplateService(dbService))
+	programHandler := handlers.NewProgramHandler(dbService, services.NewProgramService(dbService))
+	analyticsHandler := handlers.NewAnalyticsHandler(dbService, services.NewAnalyticsService(dbService))
+	importHandler := handlers.NewImportHandler(dbService, services.NewImportService(dbService))
+	insightService := services.NewInsightService(dbService)
+	insightHandler := handlers.NewInsightHandler(dbService, insightService)
+	exportHandler := handlers.NewExportHandler(dbService, services.NewExportService(dbService))
+	plateHandler := handlers.NewPlateHandler(dbService, services.NewPlateService(dbService))
 
 	http.HandleFunc("/signup", loginHandler.Signup)
 
 	http.HandleFunc("/login", loginHandler.Login)
 
+	//endpoint to exchange a refresh token for a new token pair
+	http.HandleFunc("/token/refresh", loginHandler.Refresh)
+
+	//endpoint to revoke the current access token and its refresh token family
+	http.Handle("/logout", middleware.MiddlewareHandler(http.HandlerFunc(loginHandler.Logout)))
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Welcome to The Gym App")
 	})
 
-	//endpoint to log workouts
-	http.Handle("/api/workouts", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.LogWorkout)))
+	//endpoint to log workouts and page through, filter and sort the workout history
+	http.Handle("/api/workouts", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.Workouts)))
 
 	//endpoint to find a user's entire history of workouts
 	http.Handle("/api/workouts/findAll", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.GetAllWorkouts)))
@@ -53,6 +141,87 @@ func main() {
 	//endpoint to find user's set rep maxes
 	http.Handle("/api/workouts/setRep", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.GetSetRepMax)))
 
+	//endpoint to find a user's estimated one rep maxes and rep max tables
+	http.Handle("/api/analytics/1rm", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetOneRepMaxes)))
+
+	//endpoints to find a user's cardio distance per period and best paces per distance
+	http.Handle("/api/analytics/cardio/volume", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetCardioVolume)))
+	http.Handle("/api/analytics/cardio/paces", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetBestPaces)))
+
+	//endpoints to find a user's weekly volume per muscle group and set their volume landmarks
+	http.Handle("/api/analytics/muscle-volume", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetMuscleVolume)))
+	http.Handle("/api/analytics/muscle-volume/landmarks", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.VolumeLandmarks)))
+
+	//endpoint to find a user's daily training load, workload ratio and fitness/fatigue
+	http.Handle("/api/analytics/training-load", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetTrainingLoad)))
+
+	//endpoint to recommend the weight and reps of the next session of an exercise
+	http.Handle("/api/analytics/next-session", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetNextSession)))
+
+	//endpoint to list the plateaus and regressions found in a user's training, or scan for them now
+	http.Handle("/api/insights", middleware.MiddlewareHandler(http.HandlerFunc(insightHandler.Insights)))
+
+	//endpoint to score a user's squat, bench and deadlift against their bodyweight
+	http.Handle("/api/analytics/strength-standards", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetStrengthStandards)))
+
+	//endpoint to find a user's personal record history
+	http.Handle("/api/prs", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetPersonalRecords)))
+
+	//endpoints to fetch, edit or delete a single workout and its nested exercises and sets
+	http.Handle("/api/workouts/{id}", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.WorkoutByID)))
+	http.Handle("/api/workouts/{id}/exercises/{exerciseID}", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.ExerciseByID)))
+	http.Handle("/api/workouts/{id}/exercises/{exerciseID}/sets/{setID}", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.SetByID)))
+
+	//endpoints to browse the exercise catalog and manage custom exercises
+	http.Handle("/api/exercises", middleware.MiddlewareHandler(http.HandlerFunc(exerciseHandler.Exercises)))
+	http.Handle("/api/exercises/resolve", middleware.MiddlewareHandler(http.HandlerFunc(exerciseHandler.ResolveExercise)))
+	http.Handle("/api/exercises/{id}", middleware.MiddlewareHandler(http.HandlerFunc(exerciseHandler.ExerciseByID)))
+
+	//endpoints to manage workout templates, start a workout from one and get its next session targets
+	http.Handle("/api/templates", middleware.MiddlewareHandler(http.HandlerFunc(templateHandler.Templates)))
+	http.Handle("/api/templates/{id}", middleware.MiddlewareHandler(http.HandlerFunc(templateHandler.TemplateByID)))
+	http.Handle("/api/templates/{id}/start", middleware.MiddlewareHandler(http.HandlerFunc(templateHandler.StartTemplate)))
+	http.Handle("/api/templates/{id}/recommendations", middleware.MiddlewareHandler(http.HandlerFunc(templateHandler.TemplateRecommendations)))
+
+	//endpoints to manage the user's bar and plates and work out plate loading and warm-ups
+	http.Handle("/api/plates/inventory", middleware.MiddlewareHandler(http.HandlerFunc(plateHandler.Inventory)))
+	http.Handle("/api/plates/calculate", middleware.MiddlewareHandler(http.HandlerFunc(plateHandler.Calculate)))
+
+	//endpoint to import workout history from Strong, Hevy or FitNotes exports
+	http.Handle("/api/import", middleware.MiddlewareHandler(http.HandlerFunc(importHandler.Import)))
+
+	//endpoint to download workouts as CSV or JSON, or the whole account as a zip
+	http.Handle("/api/export", middleware.MiddlewareHandler(http.HandlerFunc(exportHandler.Export)))
+
+	//endpoints to browse training programs, enroll in one and feed it logged workouts
+	http.Handle("/api/programs", middleware.MiddlewareHandler(http.HandlerFunc(programHandler.ListPrograms)))
+	http.Handle("/api/programs/{key}", middleware.MiddlewareHandler(http.HandlerFunc(programHandler.GetProgram)))
+	http.Handle("/api/programs/active", middleware.MiddlewareHandler(http.HandlerFunc(programHandler.ActiveProgram)))
+	http.Handle("/api/programs/active/sessions", middleware.MiddlewareHandler(http.HandlerFunc(programHandler.CompleteSession)))
+
+	//endpoint to read and update the user's profile
+	http.Handle("/api/profile", middleware.MiddlewareHandler(http.HandlerFunc(profileHandler.Profile)))
+
+	//endpoints to log and chart body measurements
+	http.Handle("/api/measurements", middleware.MiddlewareHandler(http.HandlerFunc(profileHandler.Measurements)))
+	http.Handle("/api/measurements/trend", middleware.MiddlewareHandler(http.HandlerFunc(profileHandler.MeasurementTrend)))
+	http.Handle("/api/measurements/{id}", middleware.MiddlewareHandler(http.HandlerFunc(profileHandler.MeasurementByID)))
+
+	//coach only endpoints to list their clients and read an assigned client's workout history and weekly muscle volume
+	http.Handle("/api/coach/clients", middleware.MiddlewareHandler(middleware.RequirePermission(models.PermViewClientWorkouts)(http.HandlerFunc(coachHandler.ListClients))))
+	http.Handle("/api/coach/clients/{id}/workouts", middleware.MiddlewareHandler(middleware.RequirePermission(models.PermViewClientWorkouts)(http.HandlerFunc(workoutHandler.GetClientWorkouts))))
+	http.Handle("/api/coach/clients/{id}/analytics/muscle-volume", middleware.MiddlewareHandler(middleware.RequirePermission(models.PermViewClientWorkouts)(http.HandlerFunc(analyticsHandler.GetClientMuscleVolume))))
+
+	//admin only endpoints to list users, change their roles and assign clients to coaches
+	http.Handle("/api/admin/users", middleware.MiddlewareHandler(middleware.RequirePermission(models.PermManageUsers)(http.HandlerFunc(adminHandler.ListUsers))))
+	http.Handle("/api/admin/users/{id}/roles", middleware.MiddlewareHandler(middleware.RequirePermission(models.PermManageUsers)(http.HandlerFunc(adminHandler.UpdateUserRoles))))
+	http.Handle("/api/admin/coaches/{id}/clients/{clientID}", middleware.MiddlewareHandler(middleware.RequirePermission(models.PermManageUsers)(http.HandlerFunc(adminHandler.CoachClients))))
+
+	//scan for plateaus in the background while serving
+	if insightInterval > 0 {
+		go insightService.Run(insightInterval, nil)
+	}
+
 	fmt.Println("Server starting on :8080...")
 	if err := http.ListenAndServe(":8080", nil); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"the-gym-app/internal/services"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | link-exercises"
+
+// runMigrate implements the migrate subcommand:
+//
+//	migrate up            apply every pending migration
+//	migrate down [steps]  roll back the latest steps migrations (default 1)
+//	migrate status        list migrations and whether they are applied
+//	migrate link-exercises  link exercises logged as free text to the catalog
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+
+	dbService, err := services.OpenDatabaseService(services.ConfigFromEnv())
+	if err != nil {
+		return err
+	}
+	defer dbService.Close()
+	migrator := dbService.Migrator()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return fmt.Errorf("steps must be a positive number")
+			}
+		}
+		rolledBack, err := migrator.Down(steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.ChecksumMismatch {
+				state += " (checksum mismatch)"
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	case "link-exercises":
+		//the names are matched against the catalog, so it has to be seeded first
+		if err := dbService.SeedExerciseCatalog(); err != nil {
+			return err
+		}
+		linked, err := dbService.LinkLoggedExercises()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("linked %d exercises\n", linked)
+	default:
+		return fmt.Errorf(migrateUsage)
+	}
+	return nil
+}
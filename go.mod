@@ -5,3 +5,7 @@ go 1.23.2
 require github.com/mattn/go-sqlite3 v1.14.28
 
 require github.com/golang-jwt/jwt/v5 v5.2.2
+
+require golang.org/x/crypto v0.36.0
+
+require github.com/lib/pq v1.10.9
@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"math"
+	"the-gym-app/internal/models"
+)
+
+// StandardDistance is a race distance best paces are reported for.
+type StandardDistance struct {
+	Name   string
+	Meters float64
+}
+
+// StandardDistances are the distances BestPaces looks at, shortest first.
+var StandardDistances = []StandardDistance{
+	{Name: "400m", Meters: 400},
+	{Name: "1k", Meters: 1000},
+	{Name: "mile", Meters: 1609.344},
+	{Name: "5k", Meters: 5000},
+	{Name: "10k", Meters: 10000},
+	{Name: "half marathon", Meters: 21097.5},
+	{Name: "marathon", Meters: 42195},
+}
+
+// distanceTolerance lets a tracked 4.97 km run count as a 5k.
+const distanceTolerance = 0.01
+
+// CardioByPeriod buckets the distance, duration and calories of completed sets by
+// period. sets must be ordered by time, as GetSetHistory returns them.
+func CardioByPeriod(sets []models.LoggedSet, period string) models.CardioVolume {
+	volume := models.CardioVolume{Period: period, Points: []models.CardioPeriod{}}
+	//pace is averaged over the sets that have both values, weighted by their distance
+	var pacedDistance float64
+	var pacedSeconds int
+	lastWorkout := 0
+	for _, set := range sets {
+		if !set.Counted() || (set.DistanceMeters == 0 && set.DurationSeconds == 0) {
+			continue
+		}
+		start := PeriodStart(set.PerformedAt, period)
+		n := len(volume.Points)
+		if n == 0 || !volume.Points[n-1].PeriodStart.Equal(start) {
+			if n > 0 {
+				volume.Points[n-1].AvgPaceSecondsPerKm = models.Pace(pacedSeconds, pacedDistance)
+			}
+			volume.Points = append(volume.Points, models.CardioPeriod{PeriodStart: start})
+			pacedDistance, pacedSeconds, lastWorkout = 0, 0, 0
+			n++
+		}
+		point := &volume.Points[n-1]
+		point.DistanceMeters += set.DistanceMeters
+		point.DurationSeconds += set.DurationSeconds
+		point.Calories += set.Calories
+		if set.WorkoutID != lastWorkout {
+			point.Sessions++
+			lastWorkout = set.WorkoutID
+		}
+		if set.DistanceMeters > 0 && set.DurationSeconds > 0 {
+			pacedDistance += set.DistanceMeters
+			pacedSeconds += set.DurationSeconds
+		}
+		volume.TotalDistanceMeters += set.DistanceMeters
+	}
+	if n := len(volume.Points); n > 0 {
+		volume.Points[n-1].AvgPaceSecondsPerKm = models.Pace(pacedSeconds, pacedDistance)
+	}
+	for i := range volume.Points {
+		volume.Points[i].DistanceMeters = Round(volume.Points[i].DistanceMeters, 0.1)
+	}
+	volume.TotalDistanceMeters = Round(volume.TotalDistanceMeters, 0.1)
+	return volume
+}
+
+// BestPaces returns, for every standard distance that was covered in a single set,
+// the fastest average pace of a set at least that long. A pace held over a longer
+// set is a conservative estimate for the shorter distance.
+func BestPaces(sets []models.LoggedSet) []models.BestPace {
+	paces := []models.BestPace{}
+	for _, distance := range StandardDistances {
+		var best *models.LoggedSet
+		bestPace := 0.0
+		for i, set := range sets {
+			if !set.Counted() || set.DistanceMeters < distance.Meters*(1-distanceTolerance) {
+				continue
+			}
+			pace := models.Pace(set.DurationSeconds, set.DistanceMeters)
+			if pace > 0 && (best == nil || pace < bestPace) {
+				best, bestPace = &sets[i], pace
+			}
+		}
+		if best == nil {
+			continue
+		}
+		paces = append(paces, models.BestPace{
+			Distance:         distance.Name,
+			DistanceMeters:   distance.Meters,
+			PaceSecondsPerKm: bestPace,
+			EstimatedSeconds: int(math.Round(bestPace * distance.Meters / 1000)),
+			BasedOn:          *best,
+		})
+	}
+	return paces
+}
@@ -0,0 +1,128 @@
+package analytics
+
+import (
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// DefaultSecondaryCredit is the share of a set credited to a secondary muscle.
+const DefaultSecondaryCredit = 0.5
+
+// hardSetMinRPE is the effort from which a set with a logged RPE counts as hard,
+// four reps or fewer short of failure. Sets without an RPE are taken to be hard.
+const hardSetMinRPE = 6
+
+// DefaultVolumeLandmarks are weekly hard set landmarks per muscle for an intermediate
+// lifter, after the commonly cited Renaissance Periodization guidelines. Muscles that
+// compound lifts train enough on their own have an MEV of 0.
+var DefaultVolumeLandmarks = map[string]models.VolumeLandmark{
+	"chest":       {MEV: 8, MRV: 22},
+	"front delts": {MEV: 0, MRV: 12},
+	"side delts":  {MEV: 8, MRV: 26},
+	"rear delts":  {MEV: 6, MRV: 22},
+	"lats":        {MEV: 10, MRV: 25},
+	"upper back":  {MEV: 10, MRV: 25},
+	"traps":       {MEV: 0, MRV: 26},
+	"biceps":      {MEV: 8, MRV: 26},
+	"triceps":     {MEV: 6, MRV: 18},
+	"forearms":    {MEV: 0, MRV: 25},
+	"abs":         {MEV: 0, MRV: 25},
+	"obliques":    {MEV: 0, MRV: 16},
+	"lower back":  {MEV: 0, MRV: 12},
+	"glutes":      {MEV: 0, MRV: 16},
+	"quads":       {MEV: 8, MRV: 20},
+	"hamstrings":  {MEV: 6, MRV: 20},
+	"adductors":   {MEV: 0, MRV: 16},
+	"calves":      {MEV: 8, MRV: 20},
+}
+
+// VolumeLandmarks returns the landmarks of every muscle, in the order of
+// models.Muscles, with custom ones replacing the defaults.
+func VolumeLandmarks(custom []models.VolumeLandmark) []models.VolumeLandmark {
+	byMuscle := make(map[string]models.VolumeLandmark, len(custom))
+	for _, landmark := range custom {
+		landmark.Custom = true
+		byMuscle[landmark.Muscle] = landmark
+	}
+	landmarks := make([]models.VolumeLandmark, len(models.Muscles))
+	for i, muscle := range models.Muscles {
+		landmark, ok := byMuscle[muscle]
+		if !ok {
+			landmark = DefaultVolumeLandmarks[muscle]
+			landmark.Muscle = muscle
+		}
+		landmarks[i] = landmark
+	}
+	return landmarks
+}
+
+// HardSet reports whether a set counts towards the hard sets of its muscles: a
+// completed set other than a warm-up that was not logged as easy.
+func HardSet(set models.LoggedSet) bool {
+	return set.Counted() && (set.RPE == 0 || set.RPE >= hardSetMinRPE)
+}
+
+// MuscleVolume credits the hard sets and tonnage of sets to the muscles of their
+// catalog exercise and reports them per week from the week of from to the week of
+// to, against landmarks as returned by VolumeLandmarks. Sets outside the range are
+// ignored, as are cardio exercises, whose sets are not strength volume.
+func MuscleVolume(sets []models.LoggedSet, exercises map[int]models.CatalogExercise, landmarks []models.VolumeLandmark, from, to time.Time, secondaryCredit float64) models.MuscleVolumeReport {
+	report := models.MuscleVolumeReport{From: from, To: to, SecondaryCredit: secondaryCredit, Weeks: []models.MuscleWeek{}}
+
+	//one entry per week, each with a load per muscle in the order of landmarks
+	index := make(map[string]int, len(landmarks))
+	for i, landmark := range landmarks {
+		index[landmark.Muscle] = i
+	}
+	weeks := make(map[time.Time]int)
+	for start := PeriodStart(from, PeriodWeek); !start.After(to); start = start.AddDate(0, 0, 7) {
+		week := models.MuscleWeek{WeekStart: start, Muscles: make([]models.MuscleLoad, len(landmarks))}
+		for i, landmark := range landmarks {
+			week.Muscles[i] = models.MuscleLoad{Muscle: landmark.Muscle, MEV: landmark.MEV, MRV: landmark.MRV}
+		}
+		weeks[start] = len(report.Weeks)
+		report.Weeks = append(report.Weeks, week)
+	}
+
+	for _, set := range sets {
+		if set.PerformedAt.Before(from) || set.PerformedAt.After(to) || !HardSet(set) {
+			continue
+		}
+		exercise, ok := exercises[set.CatalogID]
+		if !ok {
+			report.UnattributedSets++
+			continue
+		}
+		if exercise.ExerciseType == models.ExerciseTypeCardio {
+			continue
+		}
+		week := &report.Weeks[weeks[PeriodStart(set.PerformedAt, PeriodWeek)]]
+		credit := func(muscles []string, share float64) {
+			for _, muscle := range muscles {
+				if i, ok := index[muscle]; ok {
+					week.Muscles[i].HardSets += share
+					week.Muscles[i].Tonnage += share * set.Weight * float64(set.Reps)
+				}
+			}
+		}
+		credit(exercise.PrimaryMuscles, 1)
+		credit(exercise.SecondaryMuscles, secondaryCredit)
+	}
+
+	for w := range report.Weeks {
+		for i := range report.Weeks[w].Muscles {
+			load := &report.Weeks[w].Muscles[i]
+			load.HardSets = Round(load.HardSets, 0.1)
+			load.Tonnage = Round(load.Tonnage, 0.1)
+			switch {
+			case load.HardSets < load.MEV:
+				load.Status = models.VolumeBelowMEV
+			case load.HardSets > load.MRV:
+				load.Status = models.VolumeAboveMRV
+			default:
+				load.Status = models.VolumeProductive
+			}
+		}
+	}
+	return report
+}
@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Formula selects how a one-rep max is estimated from a submaximal set.
+type Formula string
+
+const (
+	Epley    Formula = "epley"
+	Brzycki  Formula = "brzycki"
+	Lombardi Formula = "lombardi"
+	RPE      Formula = "rpe"
+)
+
+// DefaultFormula is used when a caller does not ask for a specific formula.
+const DefaultFormula = Epley
+
+// MaxTableReps is the highest rep count included in a rep-max table.
+const MaxTableReps = 12
+
+// rpePercentages is the RTS style %1RM chart flattened into half-rep steps.
+// Index (reps-1 + (10-rpe)) * 2 gives the percentage for a set of reps at an rpe,
+// so 1 rep @10 is index 0 and 12 reps @6 is index 30.
+var rpePercentages = []float64{
+	100.0, 97.8, 95.5, 93.9, 92.2, 90.7, 89.2, 87.8, 86.3, 85.0,
+	83.7, 82.4, 81.1, 79.9, 78.6, 77.4, 76.2, 75.1, 73.9, 72.3,
+	70.7, 69.4, 68.0, 66.7, 65.3, 64.0, 62.6, 61.3, 59.9, 58.6,
+	57.2,
+}
+
+// ParseFormula turns a query value into a Formula, defaulting to DefaultFormula when empty.
+func ParseFormula(value string) (Formula, error) {
+	switch f := Formula(strings.ToLower(strings.TrimSpace(value))); f {
+	case "":
+		return DefaultFormula, nil
+	case Epley, Brzycki, Lombardi, RPE:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown formula: %s", value)
+	}
+}
+
+// EstimateOneRepMax estimates a one-rep max from weight lifted for reps.
+// rpe is only used by the RPE formula; a zero rpe is treated as an all out set.
+func EstimateOneRepMax(formula Formula, weight float64, reps int, rpe float64) float64 {
+	if weight <= 0 || reps <= 0 {
+		return 0
+	}
+	switch formula {
+	case Brzycki:
+		if reps == 1 {
+			return weight
+		}
+		if reps >= 37 {
+			return EstimateOneRepMax(Epley, weight, reps, rpe)
+		}
+		return weight * 36 / float64(37-reps)
+	case Lombardi:
+		return weight * math.Pow(float64(reps), 0.10)
+	case RPE:
+		pct, ok := rpePercentage(reps, rpe)
+		if !ok {
+			return EstimateOneRepMax(Epley, weight, reps, rpe)
+		}
+		return weight * 100 / pct
+	default:
+		if reps == 1 {
+			return weight
+		}
+		return weight * (1 + float64(reps)/30)
+	}
+}
+
+// WeightForReps is the inverse of EstimateOneRepMax at maximal effort: the weight
+// that should be liftable for reps given a one-rep max.
+func WeightForReps(formula Formula, oneRepMax float64, reps int) float64 {
+	if oneRepMax <= 0 || reps <= 0 {
+		return 0
+	}
+	return WeightForRepsAtRPE(formula, oneRepMax, reps, 10)
+}
+
+// WeightForRepsAtRPE returns the load for reps at the given rpe. Formulas other than
+// RPE have no notion of effort, so reps in reserve are added to the rep count instead.
+func WeightForRepsAtRPE(formula Formula, oneRepMax float64, reps int, rpe float64) float64 {
+	if oneRepMax <= 0 || reps <= 0 {
+		return 0
+	}
+	if formula == RPE {
+		if pct, ok := rpePercentage(reps, rpe); ok {
+			return oneRepMax * pct / 100
+		}
+		formula = Epley
+	}
+	effectiveReps := reps
+	if rpe > 0 && rpe < 10 {
+		effectiveReps += int(math.Round(10 - rpe))
+	}
+	estimateForOne := EstimateOneRepMax(formula, 1, effectiveReps, 10)
+	if estimateForOne == 0 {
+		return 0
+	}
+	return oneRepMax / estimateForOne
+}
+
+// RepMaxTable returns the estimated maximal load for 1 to MaxTableReps reps.
+func RepMaxTable(formula Formula, oneRepMax float64) []float64 {
+	table := make([]float64, MaxTableReps)
+	for reps := 1; reps <= MaxTableReps; reps++ {
+		table[reps-1] = Round(WeightForReps(formula, oneRepMax, reps), 0.1)
+	}
+	return table
+}
+
+// Round rounds value to the nearest multiple of step.
+func Round(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	rounded := math.Round(value/step) * step
+	//trim float noise such as 85.30000000000001
+	return math.Round(rounded*1000) / 1000
+}
+
+func rpePercentage(reps int, rpe float64) (float64, bool) {
+	if rpe <= 0 {
+		rpe = 10
+	}
+	if rpe < 6 || rpe > 10 {
+		return 0, false
+	}
+	idx := int(math.Round((float64(reps-1) + (10 - rpe)) * 2))
+	if idx < 0 || idx >= len(rpePercentages) {
+		return 0, false
+	}
+	return rpePercentages[idx], true
+}
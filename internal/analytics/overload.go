@@ -0,0 +1,124 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"the-gym-app/internal/models"
+)
+
+// deloadPercent is how much weight a deload takes off, in percent.
+const deloadPercent = 10
+
+// RecommendNext suggests the next session of one exercise from its history, ordered
+// by time as GetSetHistory returns it. Only completed working sets are considered.
+//
+// When the top set of the last session has an RPE of the last session, the weight moves
+// by one increment per RPE point the set was below or above the target. Otherwise
+// double progression applies: reps climb by one per session until every set reaches
+// the top of the range, then the weight goes up an increment and reps start over at
+// the bottom. Two sessions in a row with a set below the range deload the weight.
+func RecommendNext(history []models.LoggedSet, target models.OverloadTarget) models.OverloadRecommendation {
+	sessions := workingSessions(history)
+	recommendation := models.OverloadRecommendation{Sets: target.Sets, Reps: target.MinReps, TargetRPE: target.TargetRPE}
+	if len(sessions) == 0 {
+		recommendation.Rule = models.RuleNoHistory
+		recommendation.Reason = "no completed working sets logged yet, start light at the bottom of the range"
+		return recommendation
+	}
+
+	last := sessions[len(sessions)-1]
+	top := last[0]
+	for _, set := range last[1:] {
+		if set.Weight > top.Weight || (set.Weight == top.Weight && set.Reps > top.Reps) {
+			top = set
+		}
+	}
+	recommendation.Exercise = top.Exercise
+	recommendation.CatalogID = top.CatalogID
+	recommendation.LastTopSet = &top
+	if recommendation.Sets == 0 {
+		recommendation.Sets = len(last)
+	}
+	if target.MinReps == 0 {
+		target.MinReps, target.MaxReps = top.Reps, top.Reps
+	}
+	recommendation.Reps = clampReps(top.Reps, target)
+	recommendation.Weight = top.Weight
+
+	if target.TargetRPE > 0 && top.RPE > 0 && top.Reps >= target.MinReps {
+		steps := math.Floor(target.TargetRPE - top.RPE)
+		if top.RPE > target.TargetRPE {
+			steps = -math.Ceil(top.RPE - target.TargetRPE - 0.5)
+		}
+		recommendation.Rule = models.RuleRPE
+		recommendation.Weight = math.Max(0, top.Weight+steps*target.Increment)
+		switch {
+		case steps > 0:
+			recommendation.Reason = fmt.Sprintf("last top set was %g x %d at RPE %g, below the target RPE %g: add weight", top.Weight, top.Reps, top.RPE, target.TargetRPE)
+		case steps < 0:
+			recommendation.Reason = fmt.Sprintf("last top set was %g x %d at RPE %g, above the target RPE %g: take weight off", top.Weight, top.Reps, top.RPE, target.TargetRPE)
+		default:
+			recommendation.Reason = fmt.Sprintf("last top set was %g x %d at RPE %g, on target: repeat it", top.Weight, top.Reps, top.RPE)
+		}
+		return recommendation
+	}
+
+	recommendation.Rule = models.RuleDoubleProgression
+	lowest := minReps(last)
+	switch {
+	case lowest >= target.MaxReps:
+		recommendation.Weight = top.Weight + target.Increment
+		recommendation.Reps = target.MinReps
+		recommendation.Reason = fmt.Sprintf("every set reached %d reps at %g: add weight and restart at %d reps", target.MaxReps, top.Weight, target.MinReps)
+	case lowest < target.MinReps && len(sessions) > 1 && minReps(sessions[len(sessions)-2]) < target.MinReps:
+		recommendation.Rule = models.RuleDeload
+		recommendation.Weight = Round(top.Weight*(100-deloadPercent)/100, target.Increment)
+		recommendation.Reps = target.MinReps
+		recommendation.Reason = fmt.Sprintf("two sessions in a row with a set below %d reps: deload by %d%%", target.MinReps, deloadPercent)
+	case lowest < target.MinReps:
+		recommendation.Reps = target.MinReps
+		recommendation.Reason = fmt.Sprintf("a set fell short of %d reps at %g: repeat the weight", target.MinReps, top.Weight)
+	default:
+		recommendation.Reps = clampReps(lowest+1, target)
+		recommendation.Reason = fmt.Sprintf("add a rep to every set until all reach %d at %g", target.MaxReps, top.Weight)
+	}
+	return recommendation
+}
+
+// workingSessions splits the completed working sets of history by workout, in order.
+func workingSessions(history []models.LoggedSet) [][]models.LoggedSet {
+	var sessions [][]models.LoggedSet
+	lastWorkout := 0
+	for _, set := range history {
+		//drop, AMRAP and other special sets would skew the rep range
+		if !set.Counted() || set.Type != models.SetTypeWorking || set.Reps == 0 {
+			continue
+		}
+		if set.WorkoutID != lastWorkout || len(sessions) == 0 {
+			sessions = append(sessions, nil)
+			lastWorkout = set.WorkoutID
+		}
+		sessions[len(sessions)-1] = append(sessions[len(sessions)-1], set)
+	}
+	return sessions
+}
+
+func minReps(sets []models.LoggedSet) int {
+	lowest := sets[0].Reps
+	for _, set := range sets[1:] {
+		if set.Reps < lowest {
+			lowest = set.Reps
+		}
+	}
+	return lowest
+}
+
+func clampReps(reps int, target models.OverloadTarget) int {
+	if reps < target.MinReps {
+		return target.MinReps
+	}
+	if reps > target.MaxReps {
+		return target.MaxReps
+	}
+	return reps
+}
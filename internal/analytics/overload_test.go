@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"testing"
+	"the-gym-app/internal/models"
+)
+
+// session returns completed working sets of weight at rpe for each of reps, all from
+// one workout.
+func session(workoutID int, weight, rpe float64, reps ...int) []models.LoggedSet {
+	var sets []models.LoggedSet
+	for i, rep := range reps {
+		sets = append(sets, models.LoggedSet{WorkoutID: workoutID, Exercise: "Back Squat", CatalogID: 1, Reps: rep, Weight: weight, RPE: rpe, SetNumber: i + 1, Type: models.SetTypeWorking, Completed: true})
+	}
+	return sets
+}
+
+func history(sessions ...[]models.LoggedSet) []models.LoggedSet {
+	var sets []models.LoggedSet
+	for _, session := range sessions {
+		sets = append(sets, session...)
+	}
+	return sets
+}
+
+func TestRecommendNext(t *testing.T) {
+	reps := models.OverloadTarget{Sets: 3, MinReps: 8, MaxReps: 12, Increment: 2.5}
+	rpe := models.OverloadTarget{Sets: 1, MinReps: 5, MaxReps: 5, TargetRPE: 8, Increment: 2.5}
+	warmup := models.LoggedSet{WorkoutID: 1, Reps: 5, Weight: 140, Type: models.SetTypeWarmup, Completed: true}
+	missed := models.LoggedSet{WorkoutID: 1, Reps: 5, Weight: 140, Type: models.SetTypeWorking}
+	for _, test := range []struct {
+		name    string
+		history []models.LoggedSet
+		target  models.OverloadTarget
+		rule    string
+		weight  float64
+		reps    int
+		sets    int
+	}{
+		{"no history", nil, reps, models.RuleNoHistory, 0, 8, 3},
+		{"add a rep", session(1, 100, 0, 10, 9, 9), reps, models.RuleDoubleProgression, 100, 10, 3},
+		{"top of the range", session(1, 100, 0, 12, 12, 12), reps, models.RuleDoubleProgression, 102.5, 8, 3},
+		{"short once", history(session(1, 100, 0, 8, 8, 8), session(2, 100, 0, 8, 7, 6)), reps, models.RuleDoubleProgression, 100, 8, 3},
+		{"short twice", history(session(1, 100, 0, 7, 6, 6), session(2, 100, 0, 7, 6, 5)), reps, models.RuleDeload, 90, 8, 3},
+		//RPE 7 at 100 x 5 is a point below the target
+		{"rpe below the target", session(1, 100, 7, 5), rpe, models.RuleRPE, 102.5, 5, 1},
+		{"rpe two points below", session(1, 100, 6, 5), rpe, models.RuleRPE, 105, 5, 1},
+		{"rpe half a point below", session(1, 100, 7.5, 5), rpe, models.RuleRPE, 100, 5, 1},
+		{"rpe on target", session(1, 100, 8, 5), rpe, models.RuleRPE, 100, 5, 1},
+		{"rpe half a point above", session(1, 100, 8.5, 5), rpe, models.RuleRPE, 100, 5, 1},
+		{"rpe a point above", session(1, 100, 9, 5), rpe, models.RuleRPE, 97.5, 5, 1},
+		{"rpe two points above", session(1, 100, 10, 5), rpe, models.RuleRPE, 95, 5, 1},
+		//RPE only steers sets that reached the rep range
+		{"rpe below the range", session(1, 100, 7, 4), rpe, models.RuleDoubleProgression, 100, 5, 1},
+		{"top set of the session", session(1, 100, 8, 8, 6), rpe, models.RuleRPE, 100, 5, 1},
+		{"no target", session(1, 60, 0, 10, 10), models.OverloadTarget{Increment: 2.5}, models.RuleDoubleProgression, 62.5, 10, 2},
+		{"warm-ups and missed sets are ignored", history([]models.LoggedSet{warmup, missed}, session(1, 100, 0, 10, 10, 10)), reps, models.RuleDoubleProgression, 100, 11, 3},
+	} {
+		recommendation := RecommendNext(test.history, test.target)
+		if recommendation.Rule != test.rule || recommendation.Weight != test.weight || recommendation.Reps != test.reps || recommendation.Sets != test.sets {
+			t.Errorf("%s: recommendation = %s %v x %d for %d sets, want %s %v x %d for %d sets (%s)", test.name, recommendation.Rule, recommendation.Weight, recommendation.Reps, recommendation.Sets, test.rule, test.weight, test.reps, test.sets, recommendation.Reason)
+		}
+		if recommendation.Reason == "" {
+			t.Errorf("%s: no reason", test.name)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+)
+
+// Periods that time series can be bucketed by.
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// ParsePeriod validates a period query value, defaulting to PeriodWeek when empty.
+func ParsePeriod(value string) (string, error) {
+	switch value {
+	case "":
+		return PeriodWeek, nil
+	case PeriodDay, PeriodWeek, PeriodMonth:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown period: %s", value)
+	}
+}
+
+// PeriodStart truncates t to the start of its period in UTC. Weeks start on Monday.
+func PeriodStart(t time.Time, period string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case PeriodWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return day
+	}
+}
@@ -0,0 +1,161 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// PlateauRules tune when a trend counts as a stall: the peak must be at least
+// Sessions sessions and Weeks weeks old. A current estimate Regression percent or
+// more below the peak is a regression rather than a plateau. Exercises not trained
+// in the last ActiveWeeks weeks are left alone.
+type PlateauRules struct {
+	Sessions    int
+	Weeks       int
+	Regression  float64
+	ActiveWeeks int
+}
+
+// DefaultPlateauRules flag four sessions over three weeks without a new best.
+var DefaultPlateauRules = PlateauRules{Sessions: 4, Weeks: 3, Regression: 5, ActiveWeeks: 6}
+
+// recentSessions is how many of the last sessions the current estimate is the best of,
+// so a single off day is not taken for a regression.
+const recentSessions = 3
+
+// sessionEstimate is the best estimated 1RM of one workout.
+type sessionEstimate struct {
+	workoutID int
+	at        time.Time
+	oneRepMax float64
+}
+
+// DetectPlateaus scans the estimated 1RM trend of every exercise in history, ordered
+// by time as GetSetHistory returns it, and returns an insight for each that stalled or
+// regressed as of now, with the interventions of SuggestInterventions. variations
+// returns other catalog exercises of the same movement as a catalog exercise.
+func DetectPlateaus(history []models.LoggedSet, rules PlateauRules, now time.Time, variations func(catalogID int) []string) []models.Insight {
+	var order []string
+	names := make(map[string]models.LoggedSet)
+	trends := make(map[string][]sessionEstimate)
+	sets := make(map[string][]models.LoggedSet)
+	for _, set := range history {
+		if !set.Counted() || set.Reps > MaxTableReps {
+			continue
+		}
+		estimate := EstimateOneRepMax(DefaultFormula, set.Weight, set.Reps, set.RPE)
+		if estimate == 0 {
+			continue
+		}
+		key := models.NormalizeExerciseName(set.Exercise)
+		if _, ok := trends[key]; !ok {
+			order = append(order, key)
+		}
+		names[key] = set
+		sets[key] = append(sets[key], set)
+		sessions := trends[key]
+		if n := len(sessions); n > 0 && sessions[n-1].workoutID == set.WorkoutID {
+			sessions[n-1].oneRepMax = math.Max(sessions[n-1].oneRepMax, estimate)
+			continue
+		}
+		trends[key] = append(sessions, sessionEstimate{workoutID: set.WorkoutID, at: set.PerformedAt, oneRepMax: estimate})
+	}
+
+	insights := []models.Insight{}
+	for _, key := range order {
+		sessions := trends[key]
+		last := sessions[len(sessions)-1]
+		if now.Sub(last.at) > time.Duration(rules.ActiveWeeks)*7*24*time.Hour {
+			continue
+		}
+		peak := 0
+		for i, session := range sessions {
+			if session.oneRepMax > sessions[peak].oneRepMax {
+				peak = i
+			}
+		}
+		since := len(sessions) - 1 - peak
+		weeks := int(last.at.Sub(sessions[peak].at).Hours() / (24 * 7))
+		if since < rules.Sessions || weeks < rules.Weeks {
+			continue
+		}
+
+		current := 0.0
+		for _, session := range sessions[max(peak+1, len(sessions)-recentSessions):] {
+			current = math.Max(current, session.oneRepMax)
+		}
+		change := Round((current-sessions[peak].oneRepMax)/sessions[peak].oneRepMax*100, 0.1)
+		kind := models.InsightPlateau
+		if -change >= rules.Regression {
+			kind = models.InsightRegression
+		}
+		insight := models.Insight{
+			Exercise:         names[key].Exercise,
+			CatalogID:        names[key].CatalogID,
+			Kind:             kind,
+			PeakWorkoutID:    sessions[peak].workoutID,
+			PeakAt:           sessions[peak].at,
+			PeakOneRepMax:    Round(sessions[peak].oneRepMax, 0.1),
+			CurrentOneRepMax: Round(current, 0.1),
+			ChangePercent:    change,
+			SessionsSince:    since,
+			WeeksSince:       weeks,
+		}
+		var swaps []string
+		if insight.CatalogID != 0 && variations != nil {
+			swaps = variations(insight.CatalogID)
+		}
+		insight.Interventions = SuggestInterventions(insight, typicalReps(sets[key]), swaps)
+		insights = append(insights, insight)
+	}
+	return insights
+}
+
+// SuggestInterventions lists ways out of the insight: a deload for both kinds, and
+// for a plateau a change of rep range away from the reps the exercise is usually
+// done for, and a swap to one of variations, other catalog exercises of the same
+// movement. typicalReps may be 0 when unknown.
+func SuggestInterventions(insight models.Insight, typicalReps int, variations []string) []models.Intervention {
+	interventions := []models.Intervention{{
+		Kind:        models.InterventionDeload,
+		Description: fmt.Sprintf("train %s at %d%% of the recent weights for a week before building back up", insight.Exercise, 100-deloadPercent),
+	}}
+	if insight.Kind == models.InsightRegression {
+		interventions[0].Description += ", and check sleep, food and overall training load"
+		return interventions
+	}
+	switch {
+	case typicalReps == 0:
+	case typicalReps <= 5:
+		interventions = append(interventions, models.Intervention{Kind: models.InterventionRepRange, Description: "move from low reps to sets of 6 to 10 for a block"})
+	default:
+		interventions = append(interventions, models.Intervention{Kind: models.InterventionRepRange, Description: "move to heavier sets of 3 to 5 for a block"})
+	}
+	if len(variations) > 0 {
+		description := "swap in a variation such as " + variations[0]
+		for i, variation := range variations[1:] {
+			if i == len(variations)-2 {
+				description += " or " + variation
+			} else {
+				description += ", " + variation
+			}
+		}
+		interventions = append(interventions, models.Intervention{Kind: models.InterventionVariation, Description: description + " for a few weeks"})
+	}
+	return interventions
+}
+
+// typicalReps is the most common rep count of sets, the lowest one on a tie.
+func typicalReps(sets []models.LoggedSet) int {
+	counts := make(map[int]int)
+	best := 0
+	for _, set := range sets {
+		counts[set.Reps]++
+		if counts[set.Reps] > counts[best] || (counts[set.Reps] == counts[best] && set.Reps < best) {
+			best = set.Reps
+		}
+	}
+	return best
+}
@@ -0,0 +1,120 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"the-gym-app/internal/models"
+)
+
+// trend returns one session of 3x5 per weight, the first at loadStart and the rest
+// every days days.
+func trend(days int, weights ...float64) []models.LoggedSet {
+	var sets []models.LoggedSet
+	for i, weight := range weights {
+		for _, set := range session(i+1, weight, 0, 5, 5, 5) {
+			set.PerformedAt = loadStart.AddDate(0, 0, i*days)
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+func TestDetectPlateaus(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		history []models.LoggedSet
+		//now is this many days after the last session
+		after  int
+		want   bool
+		kind   string
+		peak   int
+		since  int
+		weeks  int
+		change float64
+	}{
+		{"progressing", trend(7, 100, 102.5, 105, 107.5, 110), 1, false, "", 0, 0, 0, 0},
+		//equalling the best is not a new one, so the peak is the first 110
+		{"plateau", trend(7, 100, 110, 110, 110, 110, 110), 1, true, models.InsightPlateau, 2, 4, 4, 0},
+		{"too few sessions", trend(7, 100, 110, 110, 110, 110), 1, false, "", 0, 0, 0, 0},
+		{"too few weeks", trend(3, 110, 100, 100, 100, 100), 1, false, "", 0, 0, 0, 0},
+		{"regression", trend(7, 120, 110, 110, 110, 110), 1, true, models.InsightRegression, 1, 4, 4, -8.3},
+		//the current estimate is the best of the last three sessions
+		{"one off day", trend(7, 120, 118, 118, 118, 100), 1, true, models.InsightPlateau, 1, 4, 4, -1.7},
+		{"not trained lately", trend(7, 100, 110, 110, 110, 110, 110), 43, false, "", 0, 0, 0, 0},
+	} {
+		last := test.history[len(test.history)-1].PerformedAt
+		insights := DetectPlateaus(test.history, DefaultPlateauRules, last.AddDate(0, 0, test.after), nil)
+		if !test.want {
+			if len(insights) != 0 {
+				t.Errorf("%s: insights = %+v, want none", test.name, insights)
+			}
+			continue
+		}
+		if len(insights) != 1 {
+			t.Errorf("%s: insights = %+v, want one", test.name, insights)
+			continue
+		}
+		insight := insights[0]
+		if insight.Kind != test.kind || insight.PeakWorkoutID != test.peak || insight.SessionsSince != test.since || insight.WeeksSince != test.weeks || insight.ChangePercent != test.change {
+			t.Errorf("%s: insight = %+v, want %s peaking in workout %d, %d sessions and %d weeks ago, %v%%", test.name, insight, test.kind, test.peak, test.since, test.weeks, test.change)
+		}
+		if insight.Exercise != "Back Squat" || insight.CatalogID != 1 {
+			t.Errorf("%s: insight is for %s (%d)", test.name, insight.Exercise, insight.CatalogID)
+		}
+	}
+}
+
+func TestDetectPlateausSuggestsVariations(t *testing.T) {
+	history := trend(7, 100, 110, 110, 110, 110, 110)
+	variations := func(catalogID int) []string {
+		if catalogID != 1 {
+			t.Errorf("variations of %d", catalogID)
+		}
+		return []string{"Front Squat"}
+	}
+	insights := DetectPlateaus(history, DefaultPlateauRules, history[len(history)-1].PerformedAt, variations)
+	if len(insights) != 1 {
+		t.Fatalf("insights = %+v", insights)
+	}
+	var kinds []string
+	for _, intervention := range insights[0].Interventions {
+		kinds = append(kinds, intervention.Kind)
+	}
+	if want := []string{models.InterventionDeload, models.InterventionRepRange, models.InterventionVariation}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("interventions = %v, want %v", kinds, want)
+	}
+}
+
+func TestSuggestInterventions(t *testing.T) {
+	plateau := models.Insight{Exercise: "Back Squat", Kind: models.InsightPlateau}
+	regression := models.Insight{Exercise: "Back Squat", Kind: models.InsightRegression}
+	for _, test := range []struct {
+		name        string
+		insight     models.Insight
+		typicalReps int
+		variations  []string
+		want        []models.Intervention
+	}{
+		{"low reps", plateau, 5, nil, []models.Intervention{
+			{Kind: models.InterventionDeload, Description: "train Back Squat at 90% of the recent weights for a week before building back up"},
+			{Kind: models.InterventionRepRange, Description: "move from low reps to sets of 6 to 10 for a block"},
+		}},
+		{"high reps and variations", plateau, 8, []string{"Front Squat", "Box Squat", "Pause Squat"}, []models.Intervention{
+			{Kind: models.InterventionDeload, Description: "train Back Squat at 90% of the recent weights for a week before building back up"},
+			{Kind: models.InterventionRepRange, Description: "move to heavier sets of 3 to 5 for a block"},
+			{Kind: models.InterventionVariation, Description: "swap in a variation such as Front Squat, Box Squat or Pause Squat for a few weeks"},
+		}},
+		{"unknown reps", plateau, 0, []string{"Front Squat", "Box Squat"}, []models.Intervention{
+			{Kind: models.InterventionDeload, Description: "train Back Squat at 90% of the recent weights for a week before building back up"},
+			{Kind: models.InterventionVariation, Description: "swap in a variation such as Front Squat or Box Squat for a few weeks"},
+		}},
+		//a regression is a recovery problem, so only the deload is suggested
+		{"regression", regression, 5, []string{"Front Squat"}, []models.Intervention{
+			{Kind: models.InterventionDeload, Description: "train Back Squat at 90% of the recent weights for a week before building back up, and check sleep, food and overall training load"},
+		}},
+	} {
+		if got := SuggestInterventions(test.insight, test.typicalReps, test.variations); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: interventions = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
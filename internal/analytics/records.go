@@ -0,0 +1,137 @@
+package analytics
+
+import "the-gym-app/internal/models"
+
+// Record types reported by DetectRecords.
+const (
+	RecordWeightAtReps  = "weight_at_reps"
+	RecordEstimated1RM  = "estimated_1rm"
+	RecordVolumeSet     = "volume_set"
+	RecordSessionVolume = "session_volume"
+)
+
+// DetectRecords compares the sets of one exercise in a session against the earlier
+// sets of the same exercise and returns every record the session broke.
+// A record needs something to beat, so nothing is reported without previous sets.
+// Warm-ups and sets that were not completed never count.
+func DetectRecords(previous, session []models.LoggedSet) []models.PersonalRecord {
+	previous, session = countedSets(previous), countedSets(session)
+	if len(previous) == 0 || len(session) == 0 {
+		return nil
+	}
+
+	var records []models.PersonalRecord
+	newRecord := func(set models.LoggedSet, recordType string, value, previousValue float64) models.PersonalRecord {
+		return models.PersonalRecord{
+			WorkoutID:     set.WorkoutID,
+			SetID:         set.SetID,
+			Exercise:      set.Exercise,
+			RecordType:    recordType,
+			Reps:          set.Reps,
+			Weight:        set.Weight,
+			Value:         Round(value, 0.1),
+			PreviousValue: Round(previousValue, 0.1),
+			AchievedAt:    set.PerformedAt,
+		}
+	}
+
+	//weight at reps: heavier than anything previously lifted for at least as many reps
+	bestAtReps := make(map[int]models.LoggedSet)
+	for _, set := range session {
+		if set.Reps <= 0 || set.Weight <= 0 {
+			continue
+		}
+		if best, ok := bestAtReps[set.Reps]; !ok || set.Weight > best.Weight {
+			bestAtReps[set.Reps] = set
+		}
+	}
+	for reps := 1; reps <= maxReps(session); reps++ {
+		set, ok := bestAtReps[reps]
+		if !ok {
+			continue
+		}
+		previousBest := 0.0
+		for _, p := range previous {
+			if p.Reps >= reps && p.Weight > previousBest {
+				previousBest = p.Weight
+			}
+		}
+		if set.Weight > previousBest {
+			records = append(records, newRecord(set, RecordWeightAtReps, set.Weight, previousBest))
+		}
+	}
+
+	//estimated one rep max
+	prevE1RM, _ := bestBy(previous, estimatedOneRepMax)
+	if e1rm, set := bestBy(session, estimatedOneRepMax); e1rm > prevE1RM {
+		records = append(records, newRecord(set, RecordEstimated1RM, e1rm, prevE1RM))
+	}
+
+	//best single set volume
+	prevVolume, _ := bestBy(previous, setVolume)
+	if volume, set := bestBy(session, setVolume); volume > prevVolume {
+		records = append(records, newRecord(set, RecordVolumeSet, volume, prevVolume))
+	}
+
+	//session volume compared with every earlier session of the exercise
+	sessionVolumes := make(map[int]float64)
+	for _, p := range previous {
+		sessionVolumes[p.WorkoutID] += setVolume(p)
+	}
+	prevSessionVolume := 0.0
+	for _, volume := range sessionVolumes {
+		if volume > prevSessionVolume {
+			prevSessionVolume = volume
+		}
+	}
+	currentVolume := 0.0
+	for _, set := range session {
+		currentVolume += setVolume(set)
+	}
+	if currentVolume > prevSessionVolume {
+		record := newRecord(session[0], RecordSessionVolume, currentVolume, prevSessionVolume)
+		record.SetID, record.Reps, record.Weight = 0, 0, 0
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func countedSets(sets []models.LoggedSet) []models.LoggedSet {
+	var counted []models.LoggedSet
+	for _, set := range sets {
+		if set.Counted() {
+			counted = append(counted, set)
+		}
+	}
+	return counted
+}
+
+func setVolume(set models.LoggedSet) float64 {
+	return set.Weight * float64(set.Reps)
+}
+
+func estimatedOneRepMax(set models.LoggedSet) float64 {
+	return EstimateOneRepMax(DefaultFormula, set.Weight, set.Reps, set.RPE)
+}
+
+func bestBy(sets []models.LoggedSet, metric func(models.LoggedSet) float64) (float64, models.LoggedSet) {
+	var best float64
+	var bestSet models.LoggedSet
+	for _, set := range sets {
+		if value := metric(set); value > best {
+			best, bestSet = value, set
+		}
+	}
+	return best, bestSet
+}
+
+func maxReps(sets []models.LoggedSet) int {
+	max := 0
+	for _, set := range sets {
+		if set.Reps > max {
+			max = set.Reps
+		}
+	}
+	return max
+}
@@ -0,0 +1,132 @@
+package analytics
+
+import (
+	"math"
+	"the-gym-app/internal/models"
+)
+
+// wilksCoefficients are a to f of the original Wilks polynomial, by sex.
+var wilksCoefficients = map[string][6]float64{
+	models.SexMale:   {-216.0475144, 16.2606339, -0.002388645, -0.00113732, 7.01863e-06, -1.291e-08},
+	models.SexFemale: {594.31747775582, -27.23842536447, 0.82112226871, -0.00930733913, 4.731582e-05, -9.054e-08},
+}
+
+// dotsCoefficients are a to e of the DOTS polynomial, by sex.
+var dotsCoefficients = map[string][5]float64{
+	models.SexMale:   {-307.75076, 24.0900756, -0.1918759221, 0.0007391293, -0.000001093},
+	models.SexFemale: {-57.96288, 13.6175032, -0.1126655495, 0.0005158568, -0.0000010706},
+}
+
+// ipfGLCoefficients are A, B and C of the IPF GL formula for classic (raw) lifting,
+// by sex, for the three lift total and for the bench press as an event of its own.
+var ipfGLCoefficients = map[string]map[string][3]float64{
+	models.SexMale: {
+		"total":          {1199.72839, 1025.18162, 0.00921},
+		models.LiftBench: {320.98041, 281.40258, 0.01008},
+	},
+	models.SexFemale: {
+		"total":          {610.32796, 1045.59282, 0.03048},
+		models.LiftBench: {142.40398, 442.52671, 0.04724},
+	},
+}
+
+// Bodyweight ranges the formulas were fitted on; lighter and heavier lifters are
+// scored at the nearest end.
+var (
+	wilksBodyweight = map[string][2]float64{models.SexMale: {40, 201.9}, models.SexFemale: {26.51, 154.53}}
+	dotsBodyweight  = map[string][2]float64{models.SexMale: {40, 210}, models.SexFemale: {40, 150}}
+)
+
+// Wilks scores weight lifted at bodyweight, both in kg, with the original Wilks formula.
+func Wilks(sex string, bodyweight, lifted float64) float64 {
+	c, ok := wilksCoefficients[sex]
+	if !ok || bodyweight <= 0 {
+		return 0
+	}
+	x := clamp(bodyweight, wilksBodyweight[sex])
+	denominator := c[0] + c[1]*x + c[2]*x*x + c[3]*math.Pow(x, 3) + c[4]*math.Pow(x, 4) + c[5]*math.Pow(x, 5)
+	return Round(lifted*500/denominator, 0.01)
+}
+
+// DOTS scores weight lifted at bodyweight, both in kg, with the DOTS formula.
+func DOTS(sex string, bodyweight, lifted float64) float64 {
+	coefficient := dotsCoefficient(sex, bodyweight)
+	return Round(lifted*coefficient, 0.01)
+}
+
+// IPFGL scores a classic three lift total, or a bench press when bench is true, with
+// the IPF GL formula.
+func IPFGL(sex string, bodyweight, lifted float64, bench bool) float64 {
+	event := "total"
+	if bench {
+		event = models.LiftBench
+	}
+	c, ok := ipfGLCoefficients[sex][event]
+	if !ok || bodyweight <= 0 || lifted <= 0 {
+		return 0
+	}
+	return Round(lifted*100/(c[0]-c[1]*math.Exp(-c[2]*bodyweight)), 0.01)
+}
+
+func dotsCoefficient(sex string, bodyweight float64) float64 {
+	c, ok := dotsCoefficients[sex]
+	if !ok || bodyweight <= 0 {
+		return 0
+	}
+	x := clamp(bodyweight, dotsBodyweight[sex])
+	return 500 / (c[0] + c[1]*x + c[2]*x*x + c[3]*math.Pow(x, 3) + c[4]*math.Pow(x, 4))
+}
+
+func clamp(value float64, bounds [2]float64) float64 {
+	return math.Min(math.Max(value, bounds[0]), bounds[1])
+}
+
+// standardMultiples are the bodyweight multiples from which a lifter of the reference
+// bodyweight of their sex reaches novice, intermediate, advanced and elite.
+// Everyone starts as a beginner.
+var standardMultiples = map[string]map[string][4]float64{
+	models.SexMale: {
+		models.LiftSquat:    {1.0, 1.5, 2.0, 2.75},
+		models.LiftBench:    {0.75, 1.0, 1.5, 2.0},
+		models.LiftDeadlift: {1.25, 1.75, 2.5, 3.0},
+	},
+	models.SexFemale: {
+		models.LiftSquat:    {0.75, 1.25, 1.5, 2.0},
+		models.LiftBench:    {0.5, 0.75, 1.0, 1.5},
+		models.LiftDeadlift: {1.0, 1.25, 1.75, 2.5},
+	},
+}
+
+// referenceBodyweight is the bodyweight standardMultiples hold at, by sex.
+var referenceBodyweight = map[string]float64{models.SexMale: 80, models.SexFemale: 60}
+
+// StrengthStandards returns the one-rep max from which a lifter of sex and bodyweight
+// reaches each level of lift. Standards scale with the DOTS coefficient, so they
+// ask less relative to bodyweight of heavier lifters, as strength does not grow
+// in proportion to size.
+func StrengthStandards(sex, lift string, bodyweight float64) []models.StrengthStandard {
+	multiples, ok := standardMultiples[sex][lift]
+	if !ok || bodyweight <= 0 {
+		return []models.StrengthStandard{}
+	}
+	reference := referenceBodyweight[sex]
+	scale := reference * dotsCoefficient(sex, reference) / dotsCoefficient(sex, bodyweight)
+	standards := []models.StrengthStandard{{Level: models.LevelBeginner, Weight: 0}}
+	for i, multiple := range multiples {
+		standards = append(standards, models.StrengthStandard{Level: models.StrengthLevels[i+1], Weight: Round(multiple*scale, 0.5)})
+	}
+	return standards
+}
+
+// StrengthLevel places a one-rep max on standards, returning the level reached and
+// the next one with the weight it takes, empty at the top.
+func StrengthLevel(standards []models.StrengthStandard, oneRepMax float64) (string, string, float64) {
+	level := ""
+	for _, standard := range standards {
+		if oneRepMax < standard.Weight {
+			return level, standard.Level, standard.Weight
+		}
+		level = standard.Level
+	}
+	return level, "", 0
+}
@@ -0,0 +1,100 @@
+package analytics
+
+import (
+	"testing"
+	"the-gym-app/internal/models"
+)
+
+// TestStrengthScores checks the scores against the published coefficients: a Wilks
+// coefficient of 0.6086 for a 100 kg man and 1.1149 for a 60 kg woman, and a DOTS
+// coefficient of 0.6155 and 1.1085 for the same lifters.
+func TestStrengthScores(t *testing.T) {
+	for _, test := range []struct {
+		name               string
+		sex                string
+		bodyweight, lifted float64
+		wilks, dots, ipfGL float64
+	}{
+		{"man of 100 kg", models.SexMale, 100, 800, 486.87, 492.41, 101.06},
+		{"woman of 60 kg", models.SexFemale, 60, 400, 445.95, 443.42, 90.42},
+		//Wilks and DOTS score at the end of the bodyweight range they were fitted on
+		{"man above the range", models.SexMale, 250, 800, 425.2, 396.5, 72.91},
+		{"man below the range", models.SexMale, 30, 300, 400.63, 381.33, 71.08},
+		{"woman below the range", models.SexFemale, 20, 200, 335.48, 296.96, 476.45},
+		{"woman above the range", models.SexFemale, 200, 400, 307.26, 308.3, 65.79},
+		{"unknown sex", "", 80, 500, 0, 0, 0},
+		{"no bodyweight", models.SexMale, 0, 500, 0, 0, 0},
+		{"negative bodyweight", models.SexFemale, -60, 300, 0, 0, 0},
+	} {
+		if got := Wilks(test.sex, test.bodyweight, test.lifted); got != test.wilks {
+			t.Errorf("%s: Wilks = %v, want %v", test.name, got, test.wilks)
+		}
+		if got := DOTS(test.sex, test.bodyweight, test.lifted); got != test.dots {
+			t.Errorf("%s: DOTS = %v, want %v", test.name, got, test.dots)
+		}
+		if got := IPFGL(test.sex, test.bodyweight, test.lifted, false); got != test.ipfGL {
+			t.Errorf("%s: IPF GL = %v, want %v", test.name, got, test.ipfGL)
+		}
+	}
+
+	//the bench press on its own has its own IPF GL coefficients
+	if got := IPFGL(models.SexMale, 100, 200, true); got != 91.62 {
+		t.Errorf("IPF GL of a 200 kg bench at 100 kg = %v, want 91.62", got)
+	}
+	if got := IPFGL(models.SexFemale, 60, 100, true); got != 85.91 {
+		t.Errorf("IPF GL of a 100 kg bench at 60 kg = %v, want 85.91", got)
+	}
+}
+
+func TestStrengthStandards(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		sex, lift  string
+		bodyweight float64
+		want       []float64
+	}{
+		//at the reference bodyweight the standards are the multiples themselves
+		{"man of 80 kg", models.SexMale, models.LiftSquat, 80, []float64{0, 80, 120, 160, 220}},
+		{"woman of 60 kg", models.SexFemale, models.LiftBench, 60, []float64{0, 30, 45, 60, 90}},
+		//heavier lifters need less per kg of bodyweight
+		{"man of 100 kg", models.SexMale, models.LiftSquat, 100, []float64{0, 89.5, 134.5, 179, 246.5}},
+		{"unknown lift", models.SexMale, "curl", 80, nil},
+		{"unknown sex", "", models.LiftSquat, 80, nil},
+		{"no bodyweight", models.SexFemale, models.LiftDeadlift, 0, nil},
+	} {
+		standards := StrengthStandards(test.sex, test.lift, test.bodyweight)
+		if len(standards) != len(test.want) {
+			t.Errorf("%s: standards = %+v, want %v", test.name, standards, test.want)
+			continue
+		}
+		for i, standard := range standards {
+			if standard.Level != models.StrengthLevels[i] || standard.Weight != test.want[i] {
+				t.Errorf("%s: standard %d = %+v, want %s at %v", test.name, i, standard, models.StrengthLevels[i], test.want[i])
+			}
+		}
+	}
+}
+
+func TestStrengthLevel(t *testing.T) {
+	standards := StrengthStandards(models.SexMale, models.LiftSquat, 80)
+	for _, test := range []struct {
+		oneRepMax  float64
+		level      string
+		next       string
+		nextWeight float64
+	}{
+		{0, models.LevelBeginner, models.LevelNovice, 80},
+		{79.5, models.LevelBeginner, models.LevelNovice, 80},
+		{80, models.LevelNovice, models.LevelIntermediate, 120},
+		{200, models.LevelAdvanced, models.LevelElite, 220},
+		{220, models.LevelElite, "", 0},
+	} {
+		level, next, nextWeight := StrengthLevel(standards, test.oneRepMax)
+		if level != test.level || next != test.next || nextWeight != test.nextWeight {
+			t.Errorf("StrengthLevel(%v) = %s, %s, %v, want %s, %s, %v", test.oneRepMax, level, next, nextWeight, test.level, test.next, test.nextWeight)
+		}
+	}
+	if level, next, _ := StrengthLevel(nil, 100); level != "" || next != "" {
+		t.Errorf("StrengthLevel without standards = %q, %q", level, next)
+	}
+}
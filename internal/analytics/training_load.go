@@ -0,0 +1,160 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// Windows of the acute:chronic workload ratio, in days.
+const (
+	AcuteDays   = 7
+	ChronicDays = 28
+)
+
+// Time constants and weights of the Banister impulse-response model. Fitness decays
+// over 42 days and fatigue over 7, but fatigue weighs twice as much.
+const (
+	FitnessDays   = 42
+	FatigueDays   = 7
+	FitnessWeight = 1
+	FatigueWeight = 2
+)
+
+// Zone bounds of the workload ratio, and the week over week increase that warns of
+// a spike.
+const (
+	acwrLowBelow     = 0.8
+	acwrHighAbove    = 1.3
+	acwrDangerAbove  = 1.5
+	weeklySpikeAbove = 1.5
+)
+
+// defaultSetRPE stands in for the effort of sets logged without an RPE.
+const defaultSetRPE = 7
+
+// SetLoad is the training load of one set: its volume, weight times reps, scaled by
+// its RPE out of 10. Warm-ups, sets that were not completed and sets without a
+// weight carry no load.
+func SetLoad(set models.LoggedSet) float64 {
+	if !set.Counted() {
+		return 0
+	}
+	rpe := set.RPE
+	if rpe == 0 {
+		rpe = defaultSetRPE
+	}
+	return set.Weight * float64(set.Reps) * rpe / 10
+}
+
+// LoadZone names the zone of an acute:chronic workload ratio.
+func LoadZone(acwr float64) string {
+	switch {
+	case acwr < acwrLowBelow:
+		return models.LoadZoneLow
+	case acwr <= acwrHighAbove:
+		return models.LoadZoneOptimal
+	case acwr <= acwrDangerAbove:
+		return models.LoadZoneHigh
+	default:
+		return models.LoadZoneDanger
+	}
+}
+
+// TrainingLoad builds the daily load series of sets from the day of from to the day
+// of to. sets must be ordered by time, as GetSetHistory returns them, and may start
+// before from: every earlier day is fed to the averages and the model too.
+func TrainingLoad(sets []models.LoggedSet, from, to time.Time) models.TrainingLoad {
+	first, last := PeriodStart(from, PeriodDay), PeriodStart(to, PeriodDay)
+	result := models.TrainingLoad{From: from, To: to, Days: []models.TrainingLoadDay{}, Warnings: []models.LoadWarning{}}
+	if last.Before(first) {
+		return result
+	}
+
+	//one load per day from the first set, or from the start of the range if later
+	start := first
+	if len(sets) > 0 && PeriodStart(sets[0].PerformedAt, PeriodDay).Before(start) {
+		start = PeriodStart(sets[0].PerformedAt, PeriodDay)
+	}
+	days := int(last.Sub(start).Hours()/24) + 1
+	loads := make([]float64, days)
+	for _, set := range sets {
+		day := int(PeriodStart(set.PerformedAt, PeriodDay).Sub(start).Hours() / 24)
+		if day >= 0 && day < days {
+			loads[day] += SetLoad(set)
+		}
+	}
+
+	fitnessDecay, fatigueDecay := math.Exp(-1.0/FitnessDays), math.Exp(-1.0/FatigueDays)
+	var fitness, fatigue, acuteSum, chronicSum float64
+	lastDanger := -AcuteDays - 1
+	for i, load := range loads {
+		fitness = fitness*fitnessDecay + load
+		fatigue = fatigue*fatigueDecay + load
+		acuteSum += load
+		chronicSum += load
+		if i >= AcuteDays {
+			acuteSum -= loads[i-AcuteDays]
+		}
+		if i >= ChronicDays {
+			chronicSum -= loads[i-ChronicDays]
+		}
+		date := start.AddDate(0, 0, i)
+		if date.Before(first) {
+			continue
+		}
+
+		day := models.TrainingLoadDay{
+			Date:    date,
+			Load:    Round(load, 0.1),
+			Acute:   Round(acuteSum/AcuteDays, 0.1),
+			Chronic: Round(chronicSum/ChronicDays, 0.1),
+			Fitness: Round(fitness, 0.1),
+			Fatigue: Round(fatigue, 0.1),
+			Form:    Round(FitnessWeight*fitness-FatigueWeight*fatigue, 0.1),
+		}
+		//the ratio of a user's first weeks compares against days they did not train yet
+		//and a day without chronic load has no zone, while a rest week after training is low
+		if chronicSum > 0 && i >= ChronicDays-1 {
+			day.ACWR = Round(acuteSum/AcuteDays/(chronicSum/ChronicDays), 0.01)
+			day.Zone = LoadZone(day.ACWR)
+		}
+		result.Days = append(result.Days, day)
+
+		//warn when the ratio enters the danger zone, not again until it stayed out of it for a week
+		if day.Zone == models.LoadZoneDanger {
+			if i-lastDanger > AcuteDays {
+				result.Warnings = append(result.Warnings, models.LoadWarning{
+					Date: date, Kind: models.LoadWarningACWR, Value: day.ACWR,
+					Message: fmt.Sprintf("acute load is %.2f times the chronic load", day.ACWR),
+				})
+			}
+			lastDanger = i
+		}
+
+		//on the last day of every week, compare it with the week before
+		if i >= 2*AcuteDays-1 && date.Weekday() == time.Sunday {
+			var week, previous float64
+			for d := i - 2*AcuteDays + 1; d <= i; d++ {
+				if d > i-AcuteDays {
+					week += loads[d]
+				} else {
+					previous += loads[d]
+				}
+			}
+			if previous > 0 && week/previous > weeklySpikeAbove {
+				ratio := Round(week/previous, 0.01)
+				result.Warnings = append(result.Warnings, models.LoadWarning{
+					Date: PeriodStart(date, PeriodWeek), Kind: models.LoadWarningWeekly, Value: ratio,
+					Message: fmt.Sprintf("weekly load rose %.0f%% over the week before", (ratio-1)*100),
+				})
+			}
+		}
+	}
+	sort.SliceStable(result.Warnings, func(i, j int) bool {
+		return result.Warnings[i].Date.Before(result.Warnings[j].Date)
+	})
+	return result
+}
@@ -0,0 +1,154 @@
+package analytics
+
+import (
+	"testing"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// loadStart is a Monday, so day 6 of a series is its first Sunday.
+var loadStart = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// loadSeries returns one set per day of loads whose SetLoad is exactly that load,
+// with loads[i] performed i days after loadStart.
+func loadSeries(loads map[int]float64, days int) []models.LoggedSet {
+	var sets []models.LoggedSet
+	for day := 0; day < days; day++ {
+		load, ok := loads[day]
+		if !ok {
+			load = 100
+		}
+		sets = append(sets, models.LoggedSet{Reps: 1, Weight: load, RPE: 10, Completed: true, PerformedAt: loadStart.AddDate(0, 0, day).Add(18 * time.Hour)})
+	}
+	return sets
+}
+
+func TestSetLoad(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		set  models.LoggedSet
+		want float64
+	}{
+		{"rpe scales the volume", models.LoggedSet{Reps: 5, Weight: 100, RPE: 8, Completed: true}, 400},
+		{"missing rpe counts as 7", models.LoggedSet{Reps: 5, Weight: 100, Completed: true}, 350},
+		{"warm-up", models.LoggedSet{Reps: 5, Weight: 60, RPE: 5, Completed: true, Type: models.SetTypeWarmup}, 0},
+		{"not completed", models.LoggedSet{Reps: 5, Weight: 100, RPE: 8}, 0},
+		{"bodyweight", models.LoggedSet{Reps: 10, RPE: 8, Completed: true}, 0},
+	} {
+		if got := SetLoad(test.set); got != test.want {
+			t.Errorf("%s: SetLoad = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLoadZone(t *testing.T) {
+	for _, test := range []struct {
+		acwr float64
+		want string
+	}{
+		{0, models.LoadZoneLow},
+		{0.79, models.LoadZoneLow},
+		{0.8, models.LoadZoneOptimal},
+		{1.3, models.LoadZoneOptimal},
+		{1.31, models.LoadZoneHigh},
+		{1.5, models.LoadZoneHigh},
+		{1.51, models.LoadZoneDanger},
+	} {
+		if got := LoadZone(test.acwr); got != test.want {
+			t.Errorf("LoadZone(%v) = %q, want %q", test.acwr, got, test.want)
+		}
+	}
+}
+
+func TestTrainingLoadRatio(t *testing.T) {
+	//21 days of 100 then a week of 200: acute 200 against a chronic (2100+1400)/28 = 125
+	spike := map[int]float64{}
+	for day := 21; day < 28; day++ {
+		spike[day] = 200
+	}
+	for _, test := range []struct {
+		name     string
+		loads    map[int]float64
+		days     int
+		from, to int
+		want     map[int]models.TrainingLoadDay
+	}{
+		{"steady load", nil, 28, 0, 27, map[int]models.TrainingLoadDay{
+			//no ratio until four weeks of history exist
+			0:  {Load: 100, Acute: 14.3, Chronic: 3.6},
+			26: {Load: 100, Acute: 100, Chronic: 96.4},
+			27: {Load: 100, Acute: 100, Chronic: 100, ACWR: 1, Zone: models.LoadZoneOptimal},
+		}},
+		{"history before the range counts", nil, 28, 27, 27, map[int]models.TrainingLoadDay{
+			0: {Load: 100, Acute: 100, Chronic: 100, ACWR: 1, Zone: models.LoadZoneOptimal},
+		}},
+		{"spike", spike, 28, 0, 27, map[int]models.TrainingLoadDay{
+			27: {Load: 200, Acute: 200, Chronic: 125, ACWR: 1.6, Zone: models.LoadZoneDanger},
+		}},
+		{"rest days", map[int]float64{28: 0, 29: 0, 30: 0, 31: 0, 32: 0, 33: 0, 34: 0}, 35, 34, 34, map[int]models.TrainingLoadDay{
+			0: {Load: 0, Acute: 0, Chronic: 75, Zone: models.LoadZoneLow},
+		}},
+	} {
+		result := TrainingLoad(loadSeries(test.loads, test.days), loadStart.AddDate(0, 0, test.from), loadStart.AddDate(0, 0, test.to))
+		if len(result.Days) != test.to-test.from+1 {
+			t.Fatalf("%s: %d days, want %d", test.name, len(result.Days), test.to-test.from+1)
+		}
+		for index, want := range test.want {
+			got := result.Days[index]
+			if !got.Date.Equal(loadStart.AddDate(0, 0, test.from+index)) {
+				t.Errorf("%s: day %d is %v", test.name, index, got.Date)
+			}
+			if got.Load != want.Load || got.Acute != want.Acute || got.Chronic != want.Chronic || got.ACWR != want.ACWR || got.Zone != want.Zone {
+				t.Errorf("%s: day %d = %+v, want %+v", test.name, index, got, want)
+			}
+		}
+	}
+}
+
+func TestTrainingLoadWarnings(t *testing.T) {
+	type warning struct {
+		day   int
+		kind  string
+		value float64
+	}
+	for _, test := range []struct {
+		name  string
+		loads map[int]float64
+		days  int
+		want  []warning
+	}{
+		{"steady load", nil, 49, nil},
+		{
+			//day 28 enters the danger zone and day 35 leaves it; day 42 is a new spike
+			//more than a week later. The weekly spikes are dated to their Monday.
+			"spikes a week apart", map[int]float64{28: 800, 42: 1500}, 49,
+			[]warning{
+				{28, models.LoadWarningACWR, 1.6},
+				{28, models.LoadWarningWeekly, 2},
+				{42, models.LoadWarningACWR, 1.71},
+				{42, models.LoadWarningWeekly, 3},
+			},
+		},
+		{
+			//day 36 is back in the danger zone two days after leaving it, so it does not
+			//warn again; its week is exactly 1.5 times the one before, which is no spike
+			"spikes within a week", map[int]float64{28: 800, 36: 1500}, 42,
+			[]warning{
+				{28, models.LoadWarningACWR, 1.6},
+				{28, models.LoadWarningWeekly, 2},
+			},
+		},
+	} {
+		result := TrainingLoad(loadSeries(test.loads, test.days), loadStart, loadStart.AddDate(0, 0, test.days-1))
+		if len(result.Warnings) != len(test.want) {
+			t.Errorf("%s: warnings = %+v, want %+v", test.name, result.Warnings, test.want)
+			continue
+		}
+		for i, want := range test.want {
+			got := result.Warnings[i]
+			if !got.Date.Equal(loadStart.AddDate(0, 0, want.day)) || got.Kind != want.kind || got.Value != want.value {
+				t.Errorf("%s: warning %d = %+v, want %+v", test.name, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// Account is everything of an account that goes into an archive besides the
+// workouts, which are streamed.
+type Account struct {
+	Username        string                   `json:"username"`
+	ExportedAt      time.Time                `json:"exported_at"`
+	Profile         *models.UserProfile      `json:"profile"`
+	PersonalRecords []models.PersonalRecord  `json:"-"`
+	Measurements    []models.BodyMeasurement `json:"-"`
+}
+
+// WriteArchive writes a zip of the whole account:
+//
+//	account.json           username, export time and profile
+//	workouts.json          the workouts as written by WriteJSON
+//	workouts.csv           the workouts as written by WriteCSV
+//	personal_records.json  every personal record, newest first
+//	measurements.json      the body measurement history, oldest first
+//
+// Entries are compressed as they are written, so the archive streams too.
+func WriteArchive(w io.Writer, account Account, source WorkoutSource) error {
+	archive := zip.NewWriter(w)
+	entries := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"account.json", jsonEntry(account)},
+		{"workouts.json", func(w io.Writer) error { return WriteJSON(w, source, account.ExportedAt) }},
+		{"workouts.csv", func(w io.Writer) error { return WriteCSV(w, source) }},
+		{"personal_records.json", jsonEntry(account.PersonalRecords)},
+		{"measurements.json", jsonEntry(account.Measurements)},
+	}
+	for _, entry := range entries {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Deflate, Modified: account.ExportedAt})
+		if err != nil {
+			return err
+		}
+		if err := entry.write(file); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func jsonEntry(v interface{}) func(io.Writer) error {
+	return func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+}
@@ -0,0 +1,73 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// CSVHeader is the header row of a CSV export. Every following row is one set.
+var CSVHeader = []string{
+	"workout_id", "workout_name", "date", "exercise", "catalog_id", "group", "group_type",
+	"set_number", "set_type", "reps", "weight", "rpe", "rir", "tempo", "completed",
+	"duration_seconds", "distance_meters", "pace_seconds_per_km", "avg_heart_rate", "max_heart_rate", "calories", "remarks",
+}
+
+// WriteCSV writes one row per set of the workouts of source. Exercises without sets
+// have no rows.
+func WriteCSV(w io.Writer, source WorkoutSource) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return err
+	}
+	err := source(func(workout *models.Workout) error {
+		groupTypes := make(map[string]string)
+		for _, group := range workout.Groups {
+			groupTypes[group.Label] = group.Type
+		}
+		for _, exercise := range workout.Exercises {
+			for _, set := range exercise.Sets {
+				rir := ""
+				if set.RIR != nil {
+					rir = formatFloat(*set.RIR)
+				}
+				completed := set.Completed == nil || *set.Completed
+				err := writer.Write([]string{
+					strconv.Itoa(workout.ID), text(workout.Name), workout.CreatedAt.UTC().Format(time.RFC3339),
+					text(exercise.Exercise), strconv.Itoa(exercise.CatalogID), text(exercise.Group), groupTypes[exercise.Group],
+					strconv.Itoa(set.SetNumber), set.Type, strconv.Itoa(set.Reps), formatFloat(set.Weight), formatFloat(set.RPE), rir,
+					text(set.Tempo), strconv.FormatBool(completed),
+					strconv.Itoa(set.DurationSeconds), formatFloat(set.DistanceMeters), formatFloat(set.PaceSecondsPerKm),
+					strconv.Itoa(set.AvgHeartRate), strconv.Itoa(set.MaxHeartRate), strconv.Itoa(set.Calories), text(set.Remarks),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		//flush per workout so rows reach the client while the export runs
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// text guards free text against being run as a formula when the file is opened in
+// a spreadsheet.
+func text(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
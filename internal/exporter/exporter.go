@@ -0,0 +1,49 @@
+// Package exporter writes a user's training history in formats other tools can read.
+package exporter
+
+import (
+	"fmt"
+	"strings"
+	"the-gym-app/internal/models"
+)
+
+// Format is an export format.
+type Format string
+
+// Supported formats. An archive is a zip of the whole account.
+const (
+	FormatCSV     Format = "csv"
+	FormatJSON    Format = "json"
+	FormatArchive Format = "zip"
+)
+
+// Formats lists every supported format.
+var Formats = []Format{FormatCSV, FormatJSON, FormatArchive}
+
+// ParseFormat validates a format name, defaulting to JSON when it is empty.
+func ParseFormat(value string) (Format, error) {
+	if value == "" {
+		return FormatJSON, nil
+	}
+	for _, format := range Formats {
+		if strings.EqualFold(value, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("format must be one of csv, json, zip")
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatArchive:
+		return "application/zip"
+	}
+	return "application/json"
+}
+
+// WorkoutSource calls fn with every workout to export, in order, stopping at the
+// first error. Workouts are streamed, so fn must not keep them.
+type WorkoutSource func(fn func(*models.Workout) error) error
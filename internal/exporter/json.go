@@ -0,0 +1,99 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// JSONVersion is the version of the JSON export layout.
+const JSONVersion = 1
+
+// WorkoutRecord is a workout in the JSON export, without its exercises.
+type WorkoutRecord struct {
+	ID        int                    `json:"id"`
+	Name      string                 `json:"name"`
+	StartedAt time.Time              `json:"started_at"`
+	Groups    []models.ExerciseGroup `json:"groups,omitempty"`
+}
+
+// ExerciseRecord is an exercise in the JSON export, pointing at its workout.
+// Position is its 1-based place in the workout.
+type ExerciseRecord struct {
+	ID        int    `json:"id"`
+	WorkoutID int    `json:"workout_id"`
+	Position  int    `json:"position"`
+	Exercise  string `json:"exercise"`
+	CatalogID int    `json:"catalog_id,omitempty"`
+	Group     string `json:"group,omitempty"`
+}
+
+// WriteJSON writes the workouts of source as one normalized document: flat workouts,
+// exercises and sets arrays linked by id, like the tables they come from.
+//
+//	{"version": 1, "exported_at": "...", "workouts": [...], "exercises": [...], "sets": [...]}
+//
+// Each array is streamed by a pass over source of its own.
+func WriteJSON(w io.Writer, source WorkoutSource, exportedAt time.Time) error {
+	out := bufio.NewWriter(w)
+	timestamp, err := json.Marshal(exportedAt.UTC())
+	if err != nil {
+		return err
+	}
+	out.WriteString(`{"version":` + strconv.Itoa(JSONVersion) + `,"exported_at":`)
+	out.Write(timestamp)
+
+	arrays := []struct {
+		name    string
+		records func(*models.Workout) []interface{}
+	}{
+		{"workouts", func(workout *models.Workout) []interface{} {
+			return []interface{}{WorkoutRecord{ID: workout.ID, Name: workout.Name, StartedAt: workout.CreatedAt.UTC(), Groups: workout.Groups}}
+		}},
+		{"exercises", func(workout *models.Workout) []interface{} {
+			records := make([]interface{}, len(workout.Exercises))
+			for i, exercise := range workout.Exercises {
+				records[i] = ExerciseRecord{ID: exercise.ID, WorkoutID: workout.ID, Position: i + 1, Exercise: exercise.Exercise, CatalogID: exercise.CatalogID, Group: exercise.Group}
+			}
+			return records
+		}},
+		{"sets", func(workout *models.Workout) []interface{} {
+			var records []interface{}
+			for _, exercise := range workout.Exercises {
+				for _, set := range exercise.Sets {
+					records = append(records, set)
+				}
+			}
+			return records
+		}},
+	}
+	for _, array := range arrays {
+		out.WriteString(`,"` + array.name + `":[`)
+		first := true
+		err := source(func(workout *models.Workout) error {
+			for _, record := range array.records(workout) {
+				data, err := json.Marshal(record)
+				if err != nil {
+					return err
+				}
+				if !first {
+					out.WriteByte(',')
+				}
+				first = false
+				if _, err := out.Write(data); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		out.WriteString("]")
+	}
+	out.WriteString("}\n")
+	return out.Flush()
+}
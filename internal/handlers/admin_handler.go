@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+// AdminHandler serves user management endpoints. Routes using it are expected to
+// be wrapped in middleware.RequirePermission(models.PermManageUsers).
+type AdminHandler struct {
+	dbService services.Store
+}
+
+func NewAdminHandler(dbService services.Store) *AdminHandler {
+	return &AdminHandler{dbService: dbService}
+}
+
+type rolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+// ListUsers returns every registered user with their roles.
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	users, err := h.dbService.ListUsers()
+	if err != nil {
+		writeStoreError(w, err, "Unable to fetch users")
+		return
+	}
+	writeJSON(w, users)
+}
+
+// UpdateUserRoles replaces the roles of the user in the path.
+func (h *AdminHandler) UpdateUserRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var request rolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Roles) == 0 {
+		http.Error(w, "roles is required", http.StatusBadRequest)
+		return
+	}
+	for _, role := range request.Roles {
+		if !models.IsValidRole(role) {
+			http.Error(w, "Unknown role: "+role, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.dbService.SetUserRoles(userID, request.Roles); err != nil {
+		writeStoreError(w, err, "Unable to update roles")
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"user_id": userID,
+		"roles":   request.Roles,
+	})
+}
+
+// CoachClients serves PUT and DELETE on /api/admin/coaches/{id}/clients/{clientID},
+// assigning the client to the coach or removing them.
+func (h *AdminHandler) CoachClients(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	clientID, ok := pathID(w, r, "clientID")
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if err := h.dbService.AssignClient(coachID, clientID); err != nil {
+			writeStoreError(w, err, "Unable to assign client")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"coach_id":  coachID,
+			"client_id": clientID,
+		})
+	case http.MethodDelete:
+		if err := h.dbService.UnassignClient(coachID, clientID); err != nil {
+			writeStoreError(w, err, "Unable to remove client")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
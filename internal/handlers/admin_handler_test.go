@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"the-gym-app/internal/middleware"
+	"the-gym-app/internal/models"
+)
+
+func TestOnlyAdminsManageUsers(t *testing.T) {
+	mux, dbService := newTestServer(t)
+	adminHandler := NewAdminHandler(dbService)
+	mux.Handle("/api/admin/users", middleware.MiddlewareHandler(middleware.RequirePermission(models.PermManageUsers)(http.HandlerFunc(adminHandler.ListUsers))))
+
+	for _, test := range []struct {
+		roles []string
+		want  int
+	}{
+		{nil, http.StatusForbidden},
+		{[]string{models.RoleMember}, http.StatusForbidden},
+		{[]string{models.RoleCoach}, http.StatusForbidden},
+		{[]string{models.RoleAdmin}, http.StatusOK},
+		{[]string{models.RoleMember, models.RoleAdmin}, http.StatusOK},
+	} {
+		rec := doRequest(t, mux, "alice", test.roles, http.MethodGet, "/api/admin/users", "")
+		if rec.Code != test.want {
+			t.Errorf("roles %v: status %d, want %d", test.roles, rec.Code, test.want)
+		}
+	}
+}
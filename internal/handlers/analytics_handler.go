@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+type AnalyticsHandler struct {
+	dbService        services.Store
+	analyticsService *services.AnalyticsService
+}
+
+func NewAnalyticsHandler(dbService services.Store, analyticsService *services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{dbService: dbService, analyticsService: analyticsService}
+}
+
+// GetOneRepMaxes returns the estimated one-rep max and rep-max table per exercise.
+// Optional query parameters: exercise, formula (epley, brzycki, lombardi, rpe).
+func (h *AnalyticsHandler) GetOneRepMaxes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	formula, err := analytics.ParseFormula(r.URL.Query().Get("formula"))
+	if err != nil {
+		http.Error(w, "formula must be one of epley, brzycki, lombardi, rpe", http.StatusBadRequest)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	estimates, err := h.analyticsService.OneRepMaxes(userID, r.URL.Query().Get("exercise"), formula)
+	if err != nil {
+		writeStoreError(w, err, "Unable to compute one rep maxes")
+		return
+	}
+	writeJSON(w, estimates)
+}
+
+// GetPersonalRecords returns the user's PR history, newest first.
+// Optional query parameters: exercise, type (weight_at_reps, estimated_1rm, volume_set, session_volume).
+func (h *AnalyticsHandler) GetPersonalRecords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	records, err := h.dbService.GetPersonalRecords(userID, r.URL.Query().Get("exercise"), r.URL.Query().Get("type"))
+	if err != nil {
+		writeStoreError(w, err, "Unable to fetch personal records")
+		return
+	}
+	writeJSON(w, records)
+}
+
+// GetCardioVolume returns the distance, time and calories of cardio and timed sets
+// per period. Optional query parameters: exercise, period (day, week, month), from, to.
+func (h *AnalyticsHandler) GetCardioVolume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	period, err := analytics.ParsePeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		http.Error(w, "period must be one of day, week, month", http.StatusBadRequest)
+		return
+	}
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	volume, err := h.analyticsService.CardioVolume(userID, r.URL.Query().Get("exercise"), period, from, to)
+	if err != nil {
+		writeStoreError(w, err, "Unable to compute cardio volume")
+		return
+	}
+	writeJSON(w, volume)
+}
+
+// GetBestPaces returns the fastest pace over each standard distance of one exercise.
+// Required query parameter: exercise.
+func (h *AnalyticsHandler) GetBestPaces(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	exercise := r.URL.Query().Get("exercise")
+	if exercise == "" {
+		http.Error(w, "exercise is required", http.StatusBadRequest)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	paces, err := h.analyticsService.BestPaces(userID, exercise)
+	if err != nil {
+		writeStoreError(w, err, "Unable to compute best paces")
+		return
+	}
+	writeJSON(w, paces)
+}
+
+// GetMuscleVolume returns the weekly hard sets and tonnage per muscle group, flagged
+// against the user's volume landmarks. Optional query parameters: from, to (the last
+// 8 weeks by default), secondary_credit (0 to 1, 0.5 by default).
+func (h *AnalyticsHandler) GetMuscleVolume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	h.writeMuscleVolume(w, r, userID)
+}
+
+// GetClientMuscleVolume is GetMuscleVolume for one of the coach's clients.
+// Routes using it are expected to be wrapped in middleware.RequirePermission(models.PermViewClientWorkouts).
+func (h *AnalyticsHandler) GetClientMuscleVolume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	clientID, ok := clientIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	h.writeMuscleVolume(w, r, clientID)
+}
+
+func (h *AnalyticsHandler) writeMuscleVolume(w http.ResponseWriter, r *http.Request, userID int) {
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+	credit := analytics.DefaultSecondaryCredit
+	if value := r.URL.Query().Get("secondary_credit"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			http.Error(w, "secondary_credit must be a number between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		credit = parsed
+	}
+
+	report, err := h.analyticsService.MuscleVolume(userID, from, to, credit)
+	if err != nil {
+		writeStoreError(w, err, "Unable to compute muscle volume")
+		return
+	}
+	writeJSON(w, report)
+}
+
+// GetTrainingLoad returns the daily training load of the user with its acute:chronic
+// workload ratio, Banister fitness and fatigue, and warnings of load spikes.
+// Optional query parameters: from, to (the last 90 days by default).
+func (h *AnalyticsHandler) GetTrainingLoad(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	load, err := h.analyticsService.TrainingLoad(userID, from, to)
+	if err != nil {
+		writeStoreError(w, err, "Unable to compute training load")
+		return
+	}
+	writeJSON(w, load)
+}
+
+// GetNextSession recommends the weight and reps of the next session of an exercise.
+// Required query parameter: exercise. Optional: sets, min_reps, max_reps (taken from
+// the last session when left out), target_rpe (8 by default), increment (2.5 by default).
+func (h *AnalyticsHandler) GetNextSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	params := r.URL.Query()
+	exercise := params.Get("exercise")
+	if exercise == "" {
+		http.Error(w, "exercise is required", http.StatusBadRequest)
+		return
+	}
+	var target models.OverloadTarget
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{{"sets", &target.Sets}, {"min_reps", &target.MinReps}, {"max_reps", &target.MaxReps}} {
+		if value := params.Get(param.name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, param.name+" must be a number", http.StatusBadRequest)
+				return
+			}
+			*param.value = parsed
+		}
+	}
+	for _, param := range []struct {
+		name  string
+		value *float64
+	}{{"target_rpe", &target.TargetRPE}, {"increment", &target.Increment}} {
+		if value := params.Get(param.name); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				http.Error(w, param.name+" must be a number", http.StatusBadRequest)
+				return
+			}
+			*param.value = parsed
+		}
+	}
+	if err := models.ValidateOverloadTarget(&target); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	recommendation, err := h.analyticsService.RecommendNext(userID, exercise, target)
+	if err != nil {
+		writeStoreError(w, err, "Unable to recommend the next session")
+		return
+	}
+	writeJSON(w, recommendation)
+}
+
+// GetStrengthStandards returns Wilks, DOTS and IPF GL points, bodyweight multiples and
+// strength standard levels for the user's squat, bench and deadlift. Optional query
+// parameters: sex (male, female) and bodyweight in kg, both taken from the profile
+// when left out.
+func (h *AnalyticsHandler) GetStrengthStandards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sex := r.URL.Query().Get("sex")
+	if sex != "" && sex != models.SexMale && sex != models.SexFemale {
+		http.Error(w, "sex must be male or female", http.StatusBadRequest)
+		return
+	}
+	var bodyweight float64
+	if value := r.URL.Query().Get("bodyweight"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "bodyweight must be a positive number", http.StatusBadRequest)
+			return
+		}
+		bodyweight = parsed
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.analyticsService.StrengthStandards(userID, sex, bodyweight)
+	if err != nil {
+		writeStoreError(w, err, "Unable to compute strength standards")
+		return
+	}
+	writeJSON(w, report)
+}
+
+// VolumeLandmarks serves GET and PUT on the user's weekly set landmarks per muscle.
+// PUT takes a list of {muscle, mev, mrv} that replaces the user's own landmarks;
+// muscles left out use the defaults again.
+func (h *AnalyticsHandler) VolumeLandmarks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		landmarks, err := h.analyticsService.VolumeLandmarks(userID)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch volume landmarks")
+			return
+		}
+		writeJSON(w, landmarks)
+	case http.MethodPut:
+		var landmarks []models.VolumeLandmark
+		if err := json.NewDecoder(r.Body).Decode(&landmarks); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := models.ValidateVolumeLandmarks(landmarks); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		saved, err := h.analyticsService.SetVolumeLandmarks(userID, landmarks)
+		if err != nil {
+			writeStoreError(w, err, "Failed to save volume landmarks")
+			return
+		}
+		writeJSON(w, saved)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
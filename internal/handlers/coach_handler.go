@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"the-gym-app/internal/services"
+)
+
+// CoachHandler serves the coach's own view of their clients. Routes using it are
+// expected to be wrapped in middleware.RequirePermission(models.PermViewClientWorkouts).
+type CoachHandler struct {
+	dbService services.Store
+}
+
+func NewCoachHandler(dbService services.Store) *CoachHandler {
+	return &CoachHandler{dbService: dbService}
+}
+
+// ListClients returns the users assigned to the authenticated coach.
+func (h *CoachHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	coachID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	clients, err := h.dbService.GetClients(coachID)
+	if err != nil {
+		writeStoreError(w, err, "Unable to fetch clients")
+		return
+	}
+	writeJSON(w, clients)
+}
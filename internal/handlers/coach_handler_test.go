@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"the-gym-app/internal/middleware"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+func TestCoachReadsOnlyAssignedClients(t *testing.T) {
+	mux, dbService := newTestServer(t)
+	workoutHandler := NewWorkoutHandler(dbService, nil)
+	coachHandler := NewCoachHandler(dbService)
+	coachOnly := middleware.RequirePermission(models.PermViewClientWorkouts)
+	mux.Handle("/api/coach/clients", middleware.MiddlewareHandler(coachOnly(http.HandlerFunc(coachHandler.ListClients))))
+	mux.Handle("/api/coach/clients/{id}/workouts", middleware.MiddlewareHandler(coachOnly(http.HandlerFunc(workoutHandler.GetClientWorkouts))))
+	mux.Handle("/api/coach/clients/{id}/analytics/muscle-volume", middleware.MiddlewareHandler(coachOnly(http.HandlerFunc(NewAnalyticsHandler(dbService, services.NewAnalyticsService(dbService)).GetClientMuscleVolume))))
+
+	logTestWorkout(t, mux, "alice")
+	aliceID, err := dbService.GetUserIdFromUsername("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	coachID, err := dbService.GetUserIdFromUsername("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dbService.SetUserRoles(coachID, []string{models.RoleCoach}); err != nil {
+		t.Fatal(err)
+	}
+	coach := []string{models.RoleCoach}
+	clientPath := fmt.Sprintf("/api/coach/clients/%d/workouts", aliceID)
+	volumePath := fmt.Sprintf("/api/coach/clients/%d/analytics/muscle-volume", aliceID)
+
+	for _, path := range []string{clientPath, volumePath} {
+		rec := doRequest(t, mux, "bob", coach, http.MethodGet, path, "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("coach GET %s of a user that is not their client: status %d, want 404", path, rec.Code)
+		}
+	}
+
+	if err := dbService.AssignClient(coachID, aliceID); err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(t, mux, "bob", coach, http.MethodGet, clientPath, "")
+	var workouts []models.Workout
+	if err := json.Unmarshal(rec.Body.Bytes(), &workouts); err != nil {
+		t.Fatalf("reading client workouts: %d %s", rec.Code, rec.Body)
+	}
+	if len(workouts) != 1 || workouts[0].UserID != aliceID {
+		t.Errorf("client workouts = %+v", workouts)
+	}
+	rec = doRequest(t, mux, "bob", coach, http.MethodGet, volumePath, "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("reading client muscle volume: status %d, want 200", rec.Code)
+	}
+	rec = doRequest(t, mux, "bob", coach, http.MethodGet, "/api/coach/clients", "")
+	var clients []models.User
+	if err := json.Unmarshal(rec.Body.Bytes(), &clients); err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 1 || clients[0].ID != aliceID {
+		t.Errorf("clients = %+v", clients)
+	}
+
+	//the client cannot use the coach routes to read the coach back
+	rec = doRequest(t, mux, "alice", nil, http.MethodGet, fmt.Sprintf("/api/coach/clients/%d/workouts", coachID), "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("member on a coach route: status %d, want 403", rec.Code)
+	}
+
+	if err := dbService.UnassignClient(coachID, aliceID); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{clientPath, volumePath} {
+		rec := doRequest(t, mux, "bob", coach, http.MethodGet, path, "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("coach GET %s of a removed client: status %d, want 404", path, rec.Code)
+		}
+	}
+}
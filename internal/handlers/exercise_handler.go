@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+// ExerciseHandler serves the exercise catalog: the shared library plus each user's
+// custom exercises.
+type ExerciseHandler struct {
+	dbService services.Store
+}
+
+func NewExerciseHandler(dbService services.Store) *ExerciseHandler {
+	return &ExerciseHandler{dbService: dbService}
+}
+
+// Exercises serves GET and POST on /api/exercises. GET accepts the optional query
+// parameters q, muscle, equipment and pattern; POST adds a custom exercise.
+func (h *ExerciseHandler) Exercises(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		exercises, err := h.dbService.ListCatalogExercises(userID, models.CatalogFilter{
+			Query:           query.Get("q"),
+			Muscle:          query.Get("muscle"),
+			Equipment:       query.Get("equipment"),
+			MovementPattern: query.Get("pattern"),
+		})
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch exercises")
+			return
+		}
+		writeJSON(w, exercises)
+	case http.MethodPost:
+		var exercise models.CatalogExercise
+		if err := json.NewDecoder(r.Body).Decode(&exercise); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := models.ValidateCatalogExercise(&exercise); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.CreateCustomExercise(userID, &exercise); err != nil {
+			writeStoreError(w, err, "Failed to save exercise")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(exercise)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ExerciseByID serves GET on any visible catalog entry and DELETE on the user's own
+// custom exercises at /api/exercises/{id}.
+func (h *ExerciseHandler) ExerciseByID(w http.ResponseWriter, r *http.Request) {
+	catalogID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		exercise, err := h.dbService.GetCatalogExercise(userID, catalogID)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch exercise")
+			return
+		}
+		writeJSON(w, exercise)
+	case http.MethodDelete:
+		if err := h.dbService.DeleteCustomExercise(userID, catalogID); err != nil {
+			writeStoreError(w, err, "Failed to delete exercise")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ResolveExercise returns the catalog entry the name query parameter refers to, so
+// clients can show what free text input will be logged as.
+func (h *ExerciseHandler) ResolveExercise(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "name parameter is required", http.StatusBadRequest)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	exercise, err := h.dbService.ResolveExercise(userID, name)
+	if errors.Is(err, services.ErrUnknownExercise) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err, "Unable to resolve exercise")
+		return
+	}
+	writeJSON(w, exercise)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"the-gym-app/internal/exporter"
+	"the-gym-app/internal/services"
+	"time"
+)
+
+// ExportHandler lets users download their data.
+type ExportHandler struct {
+	dbService     services.Store
+	exportService *services.ExportService
+}
+
+func NewExportHandler(dbService services.Store, exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{dbService: dbService, exportService: exportService}
+}
+
+// Export serves GET on /api/export?format=csv|json|zip. csv has one row per set, json
+// (the default) is the normalized workouts document and zip is the full account
+// archive. The export is streamed as an attachment.
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	format, err := exporter.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	username, ok := usernameFromRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	out := &exportWriter{w: w, format: format, filename: fmt.Sprintf("the-gym-app-%s.%s", time.Now().UTC().Format("2006-01-02"), format)}
+	if err := h.exportService.Export(userID, username, format, out); err != nil {
+		if !out.started {
+			writeStoreError(w, err, "Failed to export data")
+			return
+		}
+		//the status is already sent, so all that is left is to cut the download short
+		log.Printf("Export of user %d failed after it started: %v", userID, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportWriter sends the download headers with the first write, leaving the response
+// untouched if the export fails before producing any output.
+type exportWriter struct {
+	w        http.ResponseWriter
+	format   exporter.Format
+	filename string
+	started  bool
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.w.Header().Set("Content-Type", e.format.ContentType())
+		e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.filename+`"`)
+	}
+	return e.w.Write(p)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"the-gym-app/internal/services"
+	"time"
+)
+
+// pathID parses a positive integer path value, writing a 400 if it is invalid.
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid "+name+" in path", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// writeStoreError maps services.ErrNotFound to a 404, validation, catalog and program
+// errors to a 400 or 409 carrying their message and anything else to a 500 with msg.
+func writeStoreError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrUnknownExercise), errors.Is(err, services.ErrUnknownProgram), errors.Is(err, services.ErrTrainingMaxRequired),
+		errors.Is(err, services.ErrInvalidSet), errors.Is(err, services.ErrInvalidGroup), errors.Is(err, services.ErrInvalidImport), errors.Is(err, services.ErrInvalidCursor),
+		errors.Is(err, services.ErrProfileIncomplete), errors.Is(err, services.ErrNotCoach), errors.Is(err, services.ErrInvalidMeasurement):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrExerciseExists), errors.Is(err, services.ErrExerciseInUse), errors.Is(err, services.ErrSessionRecorded),
+		errors.Is(err, services.ErrUserExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	log.Printf("%s: %v", msg, err)
+	http.Error(w, msg, http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// dateRange parses the optional from and to query parameters as YYYY-MM-DD or RFC3339.
+// A date only to value covers the whole day.
+func dateRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	var from, to time.Time
+	for _, param := range []string{"from", "to"} {
+		value := r.URL.Query().Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", value)
+			if err == nil && param == "to" {
+				parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+			}
+		}
+		if err != nil {
+			http.Error(w, param+" must be a date (YYYY-MM-DD) or RFC3339 timestamp", http.StatusBadRequest)
+			return from, to, false
+		}
+		if param == "from" {
+			from = parsed
+		} else {
+			to = parsed
+		}
+	}
+	return from, to, true
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"the-gym-app/internal/importer"
+	"the-gym-app/internal/services"
+	"time"
+)
+
+// maxImportSize caps an uploaded export; years of history fit comfortably.
+const maxImportSize = 32 << 20
+
+// ImportHandler imports workout history exported by other apps.
+type ImportHandler struct {
+	dbService     services.Store
+	importService *services.ImportService
+}
+
+func NewImportHandler(dbService services.Store, importService *services.ImportService) *ImportHandler {
+	return &ImportHandler{dbService: dbService, importService: importService}
+}
+
+// Import serves POST on /api/import. The export is uploaded as the "file" field of a
+// multipart form or as the raw request body. Optional parameters, as query or form
+// values: format (strong, hevy, fitnotes; detected when empty), weight_unit (kg, lb),
+// distance_unit (km, mi), timezone (IANA name), mapping (JSON object from exported
+// names to catalog names) and dry_run. The response is the import report, with 201
+// when workouts were written.
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var file io.Reader = r.Body
+	if err := r.ParseMultipartForm(maxImportSize); err == nil {
+		upload, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "file is required", http.StatusBadRequest)
+			return
+		}
+		defer upload.Close()
+		file = upload
+	} else if err != http.ErrNotMultipart {
+		http.Error(w, "Invalid upload", http.StatusBadRequest)
+		return
+	}
+
+	var opts services.ImportOptions
+	var err error
+	if opts.Format, err = importer.ParseFormat(r.FormValue("format")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts.WeightUnit = r.FormValue("weight_unit")
+	opts.DistanceUnit = r.FormValue("distance_unit")
+	if timezone := r.FormValue("timezone"); timezone != "" {
+		if opts.Location, err = time.LoadLocation(timezone); err != nil {
+			http.Error(w, "Unknown timezone: "+timezone, http.StatusBadRequest)
+			return
+		}
+	}
+	if mapping := r.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			http.Error(w, "mapping must be a JSON object of exercise names", http.StatusBadRequest)
+			return
+		}
+	}
+	if dryRun := r.FormValue("dry_run"); dryRun != "" {
+		if opts.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+			http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.importService.Import(userID, file, opts)
+	if err != nil {
+		writeStoreError(w, err, "Failed to import workouts")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if report.Imported > 0 && !report.DryRun {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"the-gym-app/internal/middleware"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+const strongExport = "Date;Workout Name;Duration;Exercise Name;Set Order;Weight;Reps;Distance;Seconds;Notes;Workout Notes;RPE\n" +
+	"2024-03-01 18:30:00;Push;1h 5m;Bench Press (Barbell);1;100;5;0;0;;;8\n" +
+	"2024-03-01 18:30:00;Push;1h 5m;Weird Machine Thing;1;50;12;0;0;;;\n" +
+	"2024-03-02 18:30:00;Pull;1h;Deadlift (Barbell);1;180;3;0;0;;;\n"
+
+func TestImportHoldsBackSessionsWithUnmatchedExercises(t *testing.T) {
+	mux, dbService := newTestServer(t)
+	importHandler := NewImportHandler(dbService, services.NewImportService(dbService))
+	mux.Handle("/api/import", middleware.MiddlewareHandler(http.HandlerFunc(importHandler.Import)))
+
+	importFile := func(path string) models.ImportReport {
+		t.Helper()
+		rec := doRequest(t, mux, "alice", nil, http.MethodPost, path, strongExport)
+		if rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+			t.Fatalf("POST %s: %d %s", path, rec.Code, rec.Body)
+		}
+		var report models.ImportReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	report := importFile("/api/import")
+	if report.Imported != 1 || report.Unresolved != 1 || len(report.Unmatched) != 1 {
+		t.Fatalf("first import: %+v", report)
+	}
+
+	//mapping the unmatched name imports the held back session in full
+	mapping := url.QueryEscape(`{"Weird Machine Thing":"Leg Press"}`)
+	report = importFile("/api/import?mapping=" + mapping)
+	if report.Imported != 1 || report.Duplicates != 1 || report.Unresolved != 0 || report.Sets != 2 {
+		t.Fatalf("import with mapping: %+v", report)
+	}
+	aliceID, err := dbService.GetUserIdFromUsername("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	workout, err := dbService.GetWorkout(aliceID, report.WorkoutIDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workout.Exercises) != 2 || workout.Exercises[1].Exercise != "Leg Press" {
+		t.Fatalf("imported session: %+v", workout.Exercises)
+	}
+}
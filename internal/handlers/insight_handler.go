@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+	"time"
+)
+
+// InsightHandler serves the plateaus and regressions found in a user's training.
+type InsightHandler struct {
+	dbService      services.Store
+	insightService *services.InsightService
+}
+
+func NewInsightHandler(dbService services.Store, insightService *services.InsightService) *InsightHandler {
+	return &InsightHandler{dbService: dbService, insightService: insightService}
+}
+
+// Insights serves GET and POST on /api/insights. GET lists the user's open insights,
+// newest first; optional query parameters: exercise, kind (plateau, regression) and
+// include_resolved. POST scans the user's training right away instead of waiting for
+// the background scan and returns the open insights.
+func (h *InsightHandler) Insights(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		params := r.URL.Query()
+		filter := models.InsightFilter{Exercise: params.Get("exercise"), Kind: params.Get("kind")}
+		if err := models.ValidateInsightFilter(filter); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if value := params.Get("include_resolved"); value != "" {
+			var err error
+			if filter.IncludeResolved, err = strconv.ParseBool(value); err != nil {
+				http.Error(w, "include_resolved must be true or false", http.StatusBadRequest)
+				return
+			}
+		}
+		insights, err := h.dbService.GetInsights(userID, filter)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch insights")
+			return
+		}
+		writeJSON(w, insights)
+	case http.MethodPost:
+		insights, err := h.insightService.Scan(userID, time.Now().UTC())
+		if err != nil {
+			writeStoreError(w, err, "Unable to scan for insights")
+			return
+		}
+		writeJSON(w, insights)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"the-gym-app/internal/middleware"
 	"the-gym-app/internal/models"
 	"the-gym-app/internal/services"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -18,12 +21,12 @@ type Credentials struct {
 	Email    string `json:"email,omitempty"`
 }
 
-func NewLoginHandler(db *services.DatabaseService) *LoginHandler {
+func NewLoginHandler(db services.Store) *LoginHandler {
 	return &LoginHandler{db}
 }
 
 type LoginHandler struct {
-	db *services.DatabaseService
+	db services.Store
 }
 
 func (l *LoginHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -53,18 +56,28 @@ func (l *LoginHandler) Login(w http.ResponseWriter, r *http.Request) {
 		}
 		passwordErr := bcrypt.CompareHashAndPassword([]byte(storedPass), []byte(credentials.Password))
 		if passwordErr == nil {
-			//generate jwt token for the user
-			jwtToken, err := middleware.GenerateToken(credentials.Username)
+			userID, err := l.db.GetUserIdFromUsername(credentials.Username)
+			if err != nil {
+				http.Error(w, "Server is facing problems at the moment, could not validate credentials", http.StatusInternalServerError)
+				return
+			}
+			//every login starts a new refresh token family
+			familyID, err := middleware.NewTokenFamily()
 			if err != nil {
 				log.Printf("error : %v", err)
 				http.Error(w, "Server is facing problems at the moment, could not generate token", http.StatusInternalServerError)
 				return
 			}
-			response := map[string]string{
-				"token": jwtToken,
+			refreshToken, err := middleware.GenerateRefreshToken()
+			if err == nil {
+				err = l.db.SaveRefreshToken(userID, refreshToken, familyID, time.Now().Add(middleware.RefreshTokenTTL))
+			}
+			if err != nil {
+				log.Printf("error : %v", err)
+				http.Error(w, "Server is facing problems at the moment, could not generate token", http.StatusInternalServerError)
+				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(response)
+			l.writeTokens(w, credentials.Username, refreshToken)
 		} else {
 			http.Error(w, "Passwords do not match", http.StatusUnauthorized)
 		}
@@ -92,9 +105,122 @@ func (l *LoginHandler) Signup(w http.ResponseWriter, r *http.Request) {
 	newUserToDb.Username = newUser.Username
 	newUserToDb.PasswordHash = string(hashP)
 	newUserToDb.Email = newUser.Email
+	newUserToDb.Role = models.DefaultRole
 	if err := l.db.SaveUser(&newUserToDb); err != nil {
-		http.Error(w, "Problem saving new user, please check in again later", http.StatusInternalServerError)
+		writeStoreError(w, err, "Problem saving new user, please check in again later")
 		return
 	}
 	fmt.Fprintf(w, "Welcome to The Gym App! Please login using your credentials")
 }
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh exchanges a refresh token for a new access token and a rotated refresh token.
+// Reusing a refresh token that was already exchanged revokes the whole family.
+func (l *LoginHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.RefreshToken == "" {
+		http.Error(w, "refresh_token is required", http.StatusBadRequest)
+		return
+	}
+
+	newRefreshToken, err := middleware.GenerateRefreshToken()
+	if err != nil {
+		log.Printf("error : %v", err)
+		http.Error(w, "Server is facing problems at the moment, could not generate token", http.StatusInternalServerError)
+		return
+	}
+	rotated, err := l.db.RotateRefreshToken(request.RefreshToken, newRefreshToken, time.Now().Add(middleware.RefreshTokenTTL))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTokenReused):
+			log.Printf("Refresh token reuse detected, token family revoked")
+			http.Error(w, "Refresh token has already been used, please login again", http.StatusUnauthorized)
+		case errors.Is(err, services.ErrTokenExpired), errors.Is(err, services.ErrNotFound):
+			http.Error(w, "Invalid or expired refresh token", http.StatusUnauthorized)
+		default:
+			log.Printf("error : %v", err)
+			http.Error(w, "Server is facing problems at the moment, could not refresh token", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	l.writeTokens(w, rotated.Username, newRefreshToken)
+}
+
+// Logout revokes the access token used for the call and, when given, the refresh
+// token family it was issued with. It must be wrapped in middleware.MiddlewareHandler.
+func (l *LoginHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	claims, ok := r.Context().Value(middleware.ContextKey("user")).(jwt.MapClaims)
+	if !ok {
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return
+	}
+	userID, ok := userIDFromRequest(l.db, w, r)
+	if !ok {
+		return
+	}
+
+	//the body is optional, a logout without a refresh token only kills the access token
+	var request refreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Request is not valid", http.StatusBadRequest)
+			return
+		}
+	}
+	if request.RefreshToken != "" {
+		err := l.db.RevokeRefreshTokenFamily(userID, request.RefreshToken)
+		if err != nil && !errors.Is(err, services.ErrNotFound) {
+			log.Printf("error : %v", err)
+			http.Error(w, "Server is facing problems at the moment, could not logout", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	jti, _ := claims["jti"].(string)
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+	if err := l.db.RevokeAccessToken(jti, expiresAt.Time); err != nil {
+		log.Printf("error : %v", err)
+		http.Error(w, "Server is facing problems at the moment, could not logout", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (l *LoginHandler) writeTokens(w http.ResponseWriter, username, refreshToken string) {
+	//roles are read on every issue so a role change applies from the next refresh
+	roles, err := l.db.GetUserRoles(username)
+	if err != nil {
+		log.Printf("error : %v", err)
+		http.Error(w, "Server is facing problems at the moment, could not generate token", http.StatusInternalServerError)
+		return
+	}
+	jwtToken, err := middleware.GenerateToken(username, roles)
+	if err != nil {
+		log.Printf("error : %v", err)
+		http.Error(w, "Server is facing problems at the moment, could not generate token", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"token":         jwtToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(middleware.AccessTokenTTL.Seconds()),
+	})
+}
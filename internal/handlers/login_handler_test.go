@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignupRejectsTakenUsernames(t *testing.T) {
+	_, dbService := newTestServer(t)
+	loginHandler := NewLoginHandler(dbService)
+
+	for _, test := range []struct {
+		username string
+		want     int
+	}{
+		{"carol", http.StatusOK},
+		{"carol", http.StatusConflict},
+		//alice is created by newTestServer
+		{"alice", http.StatusConflict},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/signup", bytes.NewBufferString(`{"username":"`+test.username+`","password":"secret"}`))
+		rec := httptest.NewRecorder()
+		loginHandler.Signup(rec, req)
+		if rec.Code != test.want {
+			t.Errorf("signing up %s: status %d, want %d", test.username, rec.Code, test.want)
+		}
+	}
+}
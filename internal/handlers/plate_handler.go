@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+// PlateHandler serves the plate inventory and the plate and warm-up calculator.
+type PlateHandler struct {
+	dbService    services.Store
+	plateService *services.PlateService
+}
+
+func NewPlateHandler(dbService services.Store, plateService *services.PlateService) *PlateHandler {
+	return &PlateHandler{dbService: dbService, plateService: plateService}
+}
+
+// Inventory serves GET and PUT on /api/plates/inventory. GET returns the default kg
+// inventory until the user saved their own.
+func (h *PlateHandler) Inventory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		inventory, err := h.dbService.GetPlateInventory(userID)
+		if errors.Is(err, services.ErrNotFound) {
+			defaults := models.DefaultPlateInventory(models.UnitKg)
+			inventory, err = &defaults, nil
+		}
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch plate inventory")
+			return
+		}
+		writeJSON(w, inventory)
+	case http.MethodPut:
+		var inventory models.PlateInventory
+		if err := json.NewDecoder(r.Body).Decode(&inventory); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := models.ValidatePlateInventory(&inventory); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.SavePlateInventory(userID, &inventory); err != nil {
+			writeStoreError(w, err, "Failed to save plate inventory")
+			return
+		}
+		writeJSON(w, inventory)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Calculate returns the plates per side that load a weight and the warm-up sets up to
+// it. Required query parameter: weight. Optional: reps of the working sets (5 by
+// default), unit (kg, lb) and bar, both taken from the user's inventory when left out.
+func (h *PlateHandler) Calculate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	params := r.URL.Query()
+	target, err := strconv.ParseFloat(params.Get("weight"), 64)
+	if err != nil || target <= 0 {
+		http.Error(w, "weight must be a positive number", http.StatusBadRequest)
+		return
+	}
+	reps := 5
+	if value := params.Get("reps"); value != "" {
+		if reps, err = strconv.Atoi(value); err != nil || reps <= 0 {
+			http.Error(w, "reps must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+	unit := params.Get("unit")
+	if unit != "" && unit != models.UnitKg && unit != models.UnitLb {
+		http.Error(w, "unit must be kg or lb", http.StatusBadRequest)
+		return
+	}
+	var bar *float64
+	if value := params.Get("bar"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "bar must be a number of at least 0", http.StatusBadRequest)
+			return
+		}
+		bar = &parsed
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	calculation, err := h.plateService.Calculate(userID, target, reps, unit, bar)
+	if err != nil {
+		writeStoreError(w, err, "Unable to calculate plates")
+		return
+	}
+	writeJSON(w, calculation)
+}
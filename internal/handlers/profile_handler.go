@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+type ProfileHandler struct {
+	dbService services.Store
+}
+
+func NewProfileHandler(dbService services.Store) *ProfileHandler {
+	return &ProfileHandler{dbService: dbService}
+}
+
+// Profile serves GET and PUT on /api/profile for the authenticated user.
+func (h *ProfileHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profile, err := h.dbService.GetUserProfile(userID)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch profile")
+			return
+		}
+		writeJSON(w, profile)
+	case http.MethodPut:
+		var profile models.UserProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if profile.Height <= 0 || profile.Weight <= 0 {
+			http.Error(w, "height and weight are required", http.StatusBadRequest)
+			return
+		}
+		if profile.Bodyfat < 0 || profile.Bodyfat >= 100 || profile.TargetWeight < 0 {
+			http.Error(w, "bodyfat must be a percentage below 100 and target_weight must not be negative", http.StatusBadRequest)
+			return
+		}
+		if profile.Sex != "" && profile.Sex != models.SexMale && profile.Sex != models.SexFemale {
+			http.Error(w, "sex must be male or female", http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.SaveUserProfile(userID, &profile); err != nil {
+			writeStoreError(w, err, "Failed to save profile")
+			return
+		}
+		saved, err := h.dbService.GetUserProfile(userID)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch profile")
+			return
+		}
+		writeJSON(w, saved)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Measurements serves GET (optional from/to query) and POST on /api/measurements.
+func (h *ProfileHandler) Measurements(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		from, to, ok := dateRange(w, r)
+		if !ok {
+			return
+		}
+		measurements, err := h.dbService.GetMeasurements(userID, from, to)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch measurements")
+			return
+		}
+		writeJSON(w, measurements)
+	case http.MethodPost:
+		var measurement models.BodyMeasurement
+		if err := json.NewDecoder(r.Body).Decode(&measurement); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.AddMeasurement(userID, &measurement); err != nil {
+			writeStoreError(w, err, "Failed to save measurement")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(measurement)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// MeasurementByID serves DELETE on /api/measurements/{id}.
+func (h *ProfileHandler) MeasurementByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	measurementID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	if err := h.dbService.DeleteMeasurement(userID, measurementID); err != nil {
+		writeStoreError(w, err, "Failed to delete measurement")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MeasurementTrend returns one metric bucketed by period for charting.
+// Query parameters: metric (default weight), period (day, week, month), from, to.
+func (h *ProfileHandler) MeasurementTrend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	metric := r.URL.Query().Get("metric")
+	if metric == "" {
+		metric = "weight"
+	}
+	valid := false
+	for _, m := range services.MeasurementMetrics {
+		valid = valid || m == metric
+	}
+	if !valid {
+		http.Error(w, "Unknown metric: "+metric, http.StatusBadRequest)
+		return
+	}
+	period, err := analytics.ParsePeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		http.Error(w, "period must be one of day, week, month", http.StatusBadRequest)
+		return
+	}
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	trend, err := h.dbService.GetMeasurementTrend(userID, metric, period, from, to)
+	if err != nil {
+		writeStoreError(w, err, "Unable to compute trend")
+		return
+	}
+	writeJSON(w, trend)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"the-gym-app/internal/programs"
+	"the-gym-app/internal/services"
+)
+
+// ProgramHandler serves the built-in training programs and the user's enrollment in one.
+type ProgramHandler struct {
+	dbService      services.Store
+	programService *services.ProgramService
+}
+
+func NewProgramHandler(dbService services.Store, programService *services.ProgramService) *ProgramHandler {
+	return &ProgramHandler{dbService: dbService, programService: programService}
+}
+
+type enrollRequest struct {
+	Program       string             `json:"program"`
+	TrainingMaxes map[string]float64 `json:"training_maxes"`
+}
+
+type sessionRequest struct {
+	WorkoutID int `json:"workout_id"`
+}
+
+// ListPrograms returns the definitions of every built-in program.
+func (h *ProgramHandler) ListPrograms(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, programs.Library())
+}
+
+// GetProgram returns the definition of the program at /api/programs/{key}.
+func (h *ProgramHandler) GetProgram(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	program, ok := programs.Lookup(r.PathValue("key"))
+	if !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, program)
+}
+
+// ActiveProgram serves /api/programs/active. GET returns the enrollment with the next
+// prescribed session, POST enrolls in a program, replacing the current one, and
+// DELETE ends the current program.
+func (h *ProgramHandler) ActiveProgram(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		active, err := h.programService.Active(userID)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch program")
+			return
+		}
+		writeJSON(w, active)
+	case http.MethodPost:
+		var req enrollRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Program == "" {
+			http.Error(w, "program is required", http.StatusBadRequest)
+			return
+		}
+		active, err := h.programService.Enroll(userID, req.Program, req.TrainingMaxes)
+		if err != nil {
+			writeStoreError(w, err, "Failed to start program")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(active)
+	case http.MethodDelete:
+		if err := h.dbService.EndProgram(userID); err != nil {
+			writeStoreError(w, err, "Failed to end program")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// CompleteSession serves POST on /api/programs/active/sessions. The logged workout in
+// the body completes the next session of the program, and the response reports how
+// every lift progressed along with the session that follows.
+func (h *ProgramHandler) CompleteSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	var req sessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.WorkoutID <= 0 {
+		http.Error(w, "workout_id is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.programService.CompleteSession(userID, req.WorkoutID)
+	if err != nil {
+		writeStoreError(w, err, "Failed to complete program session")
+		return
+	}
+	writeJSON(w, result)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+// TemplateHandler manages saved workout templates and starts workouts from them.
+type TemplateHandler struct {
+	dbService       services.Store
+	templateService *services.TemplateService
+}
+
+func NewTemplateHandler(dbService services.Store, templateService *services.TemplateService) *TemplateHandler {
+	return &TemplateHandler{dbService: dbService, templateService: templateService}
+}
+
+// Templates serves GET and POST on /api/templates.
+func (h *TemplateHandler) Templates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		templates, err := h.dbService.GetTemplates(userID)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch templates")
+			return
+		}
+		writeJSON(w, templates)
+	case http.MethodPost:
+		var template models.WorkoutTemplate
+		if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := models.ValidateTemplate(&template); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.SaveTemplate(userID, &template); err != nil {
+			writeStoreError(w, err, "Failed to save template")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(template)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// TemplateByID serves GET, PUT and DELETE on /api/templates/{id}. PUT replaces the
+// whole template, including its planned exercises.
+func (h *TemplateHandler) TemplateByID(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		template, err := h.dbService.GetTemplate(userID, templateID)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch template")
+			return
+		}
+		writeJSON(w, template)
+	case http.MethodPut:
+		var template models.WorkoutTemplate
+		if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := models.ValidateTemplate(&template); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.ReplaceTemplate(userID, templateID, &template); err != nil {
+			writeStoreError(w, err, "Failed to update template")
+			return
+		}
+		updated, err := h.dbService.GetTemplate(userID, templateID)
+		if err != nil {
+			writeStoreError(w, err, "Unable to fetch template")
+			return
+		}
+		writeJSON(w, updated)
+	case http.MethodDelete:
+		if err := h.dbService.DeleteTemplate(userID, templateID); err != nil {
+			writeStoreError(w, err, "Failed to delete template")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// StartTemplate serves POST on /api/templates/{id}/start. It returns a draft workout
+// pre-filled with the last performance of each exercise; nothing is saved until the
+// client posts the workout to /api/workouts. With the query parameter warmups=true,
+// barbell exercises start with a warm-up ramp loadable with the user's plates.
+func (h *TemplateHandler) StartTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	templateID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	warmups := false
+	if value := r.URL.Query().Get("warmups"); value != "" {
+		var err error
+		if warmups, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "warmups must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := h.templateService.StartWorkout(userID, templateID)
+	if err != nil {
+		writeStoreError(w, err, "Unable to start workout from template")
+		return
+	}
+	if warmups {
+		if err := h.templateService.AddWarmups(userID, draft); err != nil {
+			writeStoreError(w, err, "Unable to add warm-up sets")
+			return
+		}
+	}
+	writeJSON(w, draft)
+}
+
+// TemplateRecommendations serves GET on /api/templates/{id}/recommendations: the
+// weight and reps to aim for in the next session of every exercise of the template,
+// for clients to pre-fill targets with. Optional query parameter: increment.
+func (h *TemplateHandler) TemplateRecommendations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	templateID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var increment float64
+	if value := r.URL.Query().Get("increment"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "increment must be a positive number", http.StatusBadRequest)
+			return
+		}
+		increment = parsed
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
+	recommendations, err := h.templateService.Recommendations(userID, templateID, increment)
+	if err != nil {
+		writeStoreError(w, err, "Unable to recommend the next session")
+		return
+	}
+	writeJSON(w, recommendations)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"the-gym-app/internal/middleware"
+	"the-gym-app/internal/services"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// userIDFromRequest resolves the id of the authenticated user from the jwt claims
+// that middleware.MiddlewareHandler puts in the request context.
+// On failure the error response is already written and false is returned.
+func userIDFromRequest(users services.UserRepository, w http.ResponseWriter, r *http.Request) (int, bool) {
+	userName, ok := usernameFromRequest(w, r)
+	if !ok {
+		return 0, false
+	}
+
+	userID, err := users.GetUserIdFromUsername(userName)
+	if err != nil {
+		log.Printf("Error fetching user ID: %v", err)
+		if strings.Contains(err.Error(), "user not found") {
+			http.Error(w, "User not found in database", http.StatusUnauthorized)
+		} else {
+			http.Error(w, "Error while fetching User ID", http.StatusInternalServerError)
+		}
+		return 0, false
+	}
+	return userID, true
+}
+
+// usernameFromRequest returns the username of the authenticated user from the jwt
+// claims. On failure the error response is already written and false is returned.
+func usernameFromRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	//get the user from context
+	userClaims, ok := r.Context().Value(middleware.ContextKey("user")).(jwt.MapClaims)
+	if !ok {
+		log.Printf("Failed to convert user value to jwt.MapClaims")
+		http.Error(w, "Invalid user context", http.StatusInternalServerError)
+		return "", false
+	}
+
+	userName, ok := userClaims["username"].(string)
+	if !ok {
+		http.Error(w, "Invalid username : not a string!", http.StatusInternalServerError)
+		return "", false
+	}
+	return userName, true
+}
+
+// clientIDFromRequest returns the id of the client in the path after checking that
+// it is assigned to the authenticated coach. Any other id gets a 404, the same as a
+// user that does not exist.
+func clientIDFromRequest(store services.Store, w http.ResponseWriter, r *http.Request) (int, bool) {
+	clientID, ok := pathID(w, r, "id")
+	if !ok {
+		return 0, false
+	}
+	coachID, ok := userIDFromRequest(store, w, r)
+	if !ok {
+		return 0, false
+	}
+	isCoach, err := store.IsCoachOf(coachID, clientID)
+	if err != nil {
+		writeStoreError(w, err, "Unable to check client")
+		return 0, false
+	}
+	if !isCoach {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return 0, false
+	}
+	return clientID, true
+}
@@ -5,20 +5,17 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
-	"the-gym-app/internal/middleware"
 	"the-gym-app/internal/models"
 	"the-gym-app/internal/services"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type WorkoutHandler struct {
-	dbService *services.DatabaseService
+	dbService     services.Store
+	recordService *services.RecordService
 }
 
-func NewWorkoutHandler(dbService *services.DatabaseService) *WorkoutHandler {
-	return &WorkoutHandler{dbService: dbService}
+func NewWorkoutHandler(dbService services.Store, recordService *services.RecordService) *WorkoutHandler {
+	return &WorkoutHandler{dbService: dbService, recordService: recordService}
 }
 
 func (h *WorkoutHandler) LogWorkout(w http.ResponseWriter, r *http.Request) {
@@ -27,31 +24,120 @@ func (h *WorkoutHandler) LogWorkout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+
 	var workout models.Workout
 	if err := json.NewDecoder(r.Body).Decode(&workout); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// TODO : save the workout to a db
-	if err := h.dbService.SaveWorkout(&workout); err != nil {
-		http.Error(w, "Failed to save workout", http.StatusInternalServerError)
+	if err := h.dbService.SaveWorkout(userID, &workout); err != nil {
+		writeStoreError(w, err, "Failed to save workout")
 		return
 	}
 
+	//the workout is already saved, so a failure here is logged rather than returned
+	records, err := h.recordService.RecordPersonalRecords(userID, &workout)
+	if err != nil {
+		log.Printf("Error detecting personal records: %v", err)
+	}
+	if records == nil {
+		records = []models.PersonalRecord{}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Workout logged successfully",
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":          "Workout logged successfully",
+		"workout_id":       workout.ID,
+		"personal_records": records,
 	})
 
 }
 
+// Workouts serves /api/workouts: GET lists the workout history a page at a time and
+// POST logs a workout.
+func (h *WorkoutHandler) Workouts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListWorkouts(w, r)
+	case http.MethodPost:
+		h.LogWorkout(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListWorkouts returns a page of the workout history. Query parameters, all optional:
+// from and to (dates), exercise, name (substring), sort (date or name), order (asc or
+// desc), limit (1-100, default 20), cursor (next_cursor of the previous page) and
+// summary, which replaces the exercises and sets by exercise names, set count and volume.
+func (h *WorkoutHandler) ListWorkouts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+	params := r.URL.Query()
+	query := models.WorkoutQuery{
+		From:     from,
+		To:       to,
+		Exercise: params.Get("exercise"),
+		Name:     params.Get("name"),
+		Sort:     params.Get("sort"),
+		Order:    params.Get("order"),
+		Cursor:   params.Get("cursor"),
+	}
+	if limit := params.Get("limit"); limit != "" {
+		var err error
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+	summary := false
+	if value := params.Get("summary"); value != "" {
+		var err error
+		if summary, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "summary must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := models.ValidateWorkoutQuery(&query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var page interface{}
+	var err error
+	if summary {
+		page, err = h.dbService.ListWorkoutSummaries(userID, query)
+	} else {
+		page, err = h.dbService.ListWorkouts(userID, query)
+	}
+	if err != nil {
+		writeStoreError(w, err, "Unable to fetch workouts")
+		return
+	}
+	writeJSON(w, page)
+}
+
 func (h *WorkoutHandler) GetAllWorkouts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	logs, err := h.dbService.GetWorkouts()
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	logs, err := h.dbService.GetWorkouts(userID)
 	if err != nil {
 		http.Error(w, "Unable to fetch workouts", http.StatusInternalServerError)
 		return
@@ -60,11 +146,26 @@ func (h *WorkoutHandler) GetAllWorkouts(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(logs)
 }
 
-func (h *WorkoutHandler) GetSetRepMax(w http.ResponseWriter, r *http.Request) {
-	log.Printf("=== GetSetRepMax handler called ===")
-	log.Printf("URL: %s", r.URL.String())
-	log.Printf("Method: %s", r.Method)
+// GetClientWorkouts lets a coach read the workout history of one of their clients.
+// Routes using it are expected to be wrapped in middleware.RequirePermission(models.PermViewClientWorkouts).
+func (h *WorkoutHandler) GetClientWorkouts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	clientID, ok := clientIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	logs, err := h.dbService.GetWorkouts(clientID)
+	if err != nil {
+		writeStoreError(w, err, "Unable to fetch workouts")
+		return
+	}
+	writeJSON(w, logs)
+}
 
+func (h *WorkoutHandler) GetSetRepMax(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -82,7 +183,7 @@ func (h *WorkoutHandler) GetSetRepMax(w http.ResponseWriter, r *http.Request) {
 	}
 	repsInt, err := strconv.Atoi(repsStr)
 	if err != nil {
-		http.Error(w, "Error during type conversion", http.StatusInternalServerError)
+		http.Error(w, "reps must be a number", http.StatusBadRequest)
 		return
 	}
 
@@ -91,41 +192,15 @@ func (h *WorkoutHandler) GetSetRepMax(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//get the user from context
-	log.Printf("Attempting to extract user from context...")
-	userValue := r.Context().Value(middleware.ContextKey("user"))
-	log.Printf("User value from context: %+v", userValue)
-
-	userClaims, ok := userValue.(jwt.MapClaims)
+	userID, ok := userIDFromRequest(h.dbService, w, r)
 	if !ok {
-		log.Printf("Failed to convert user value to jwt.MapClaims")
-		http.Error(w, "Invalid user context", http.StatusInternalServerError)
-		return
-	}
-	log.Printf("Successfully extracted user claims: %+v", userClaims)
-
-	userName, ok := userClaims["username"].(string)
-	if !ok {
-		http.Error(w, "Invalid username : not a string!", http.StatusInternalServerError)
-		return
-	}
-
-	userID, err := h.dbService.GetUserIdFromUsername(userName)
-	if err != nil {
-		log.Printf("Error fetching user ID: %v", err)
-		if strings.Contains(err.Error(), "user not found") {
-			http.Error(w, "User not found in database", http.StatusUnauthorized)
-		} else {
-			http.Error(w, "Error while fetching User ID", http.StatusInternalServerError)
-		}
 		return
 	}
 	setRep, err := h.dbService.GetSetRep(userID, exercise, repsInt)
 	if err != nil {
-		http.Error(w, "Error fetching set rep data", http.StatusInternalServerError)
+		writeStoreError(w, err, "Error fetching set rep data")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(setRep)
+	writeJSON(w, setRep)
 }
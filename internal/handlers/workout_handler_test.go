@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"the-gym-app/internal/middleware"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+)
+
+// newTestServer serves the workout and analytics routes from a fresh SQLite database
+// holding the users alice and bob.
+func newTestServer(t *testing.T) (*http.ServeMux, *services.DatabaseService) {
+	t.Helper()
+	t.Setenv("GYM_APP_SECRET_KEY", "test-secret")
+	dbService, err := services.NewDatabaseService(services.Config{DSN: filepath.Join(t.TempDir(), "gym_app.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbService.Close() })
+	for _, username := range []string{"alice", "bob"} {
+		if err := dbService.SaveUser(&models.User{Username: username, PasswordHash: "hash"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	workoutHandler := NewWorkoutHandler(dbService, services.NewRecordService(dbService))
+	analyticsHandler := NewAnalyticsHandler(dbService, services.NewAnalyticsService(dbService))
+	mux := http.NewServeMux()
+	mux.Handle("/api/workouts", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.Workouts)))
+	mux.Handle("/api/workouts/findAll", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.GetAllWorkouts)))
+	mux.Handle("/api/workouts/setRep", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.GetSetRepMax)))
+	mux.Handle("/api/workouts/{id}", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.WorkoutByID)))
+	mux.Handle("/api/workouts/{id}/exercises/{exerciseID}", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.ExerciseByID)))
+	mux.Handle("/api/workouts/{id}/exercises/{exerciseID}/sets/{setID}", middleware.MiddlewareHandler(http.HandlerFunc(workoutHandler.SetByID)))
+	mux.Handle("/api/analytics/1rm", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetOneRepMaxes)))
+	mux.Handle("/api/analytics/muscle-volume", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetMuscleVolume)))
+	mux.Handle("/api/prs", middleware.MiddlewareHandler(http.HandlerFunc(analyticsHandler.GetPersonalRecords)))
+	return mux, dbService
+}
+
+// doRequest sends an authenticated request as username and returns the recorded response.
+func doRequest(t *testing.T, handler http.Handler, username string, roles []string, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	token, err := middleware.GenerateToken(username, roles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// logTestWorkout logs a bench press workout for username and returns it as stored.
+func logTestWorkout(t *testing.T, handler http.Handler, username string) models.Workout {
+	t.Helper()
+	rec := doRequest(t, handler, username, nil, http.MethodPost, "/api/workouts",
+		`{"name":"Push day","exercises":[{"exercise":"Bench Press","sets":[{"reps":5,"weight":100,"rpe":8}]}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("logging workout: %d %s", rec.Code, rec.Body)
+	}
+	var logged struct {
+		WorkoutID int `json:"workout_id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &logged); err != nil {
+		t.Fatal(err)
+	}
+	rec = doRequest(t, handler, username, nil, http.MethodGet, fmt.Sprintf("/api/workouts/%d", logged.WorkoutID), "")
+	var workout models.Workout
+	if err := json.Unmarshal(rec.Body.Bytes(), &workout); err != nil {
+		t.Fatal(err)
+	}
+	return workout
+}
+
+func TestMalformedTokensAreRejected(t *testing.T) {
+	mux, _ := newTestServer(t)
+	for _, header := range []string{"", "Bearer", "Bearer ", "Bearer abc", "Basic YWxpY2U6c2VjcmV0"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/workouts", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status %d, want 401", header, rec.Code)
+		}
+	}
+}
+
+func TestWorkoutsAreIsolatedBetweenUsers(t *testing.T) {
+	mux, _ := newTestServer(t)
+	workout := logTestWorkout(t, mux, "alice")
+	exercise := workout.Exercises[0]
+	workoutPath := fmt.Sprintf("/api/workouts/%d", workout.ID)
+	exercisePath := fmt.Sprintf("%s/exercises/%d", workoutPath, exercise.ID)
+	setPath := fmt.Sprintf("%s/sets/%d", exercisePath, exercise.Sets[0].ID)
+
+	t.Run("list", func(t *testing.T) {
+		rec := doRequest(t, mux, "bob", nil, http.MethodGet, "/api/workouts", "")
+		var page models.WorkoutPage
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusOK || len(page.Workouts) != 0 {
+			t.Errorf("bob listed %d workouts (status %d), want none", len(page.Workouts), rec.Code)
+		}
+		rec = doRequest(t, mux, "bob", nil, http.MethodGet, "/api/workouts/findAll", "")
+		var all []models.Workout
+		if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 0 {
+			t.Errorf("bob's full history has %d workouts, want none", len(all))
+		}
+	})
+
+	t.Run("read update delete", func(t *testing.T) {
+		requests := []struct{ method, path, body string }{
+			{http.MethodGet, workoutPath, ""},
+			{http.MethodPut, workoutPath, `{"name":"Mine now","exercises":[]}`},
+			{http.MethodPatch, workoutPath, `{"name":"Mine now"}`},
+			{http.MethodGet, exercisePath, ""},
+			{http.MethodPatch, exercisePath, `{"exercise":"Squat"}`},
+			{http.MethodGet, setPath, ""},
+			{http.MethodPatch, setPath, `{"weight":1}`},
+			{http.MethodDelete, setPath, ""},
+			{http.MethodDelete, exercisePath, ""},
+			{http.MethodDelete, workoutPath, ""},
+		}
+		for _, req := range requests {
+			rec := doRequest(t, mux, "bob", nil, req.method, req.path, req.body)
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("bob %s %s: status %d, want 404", req.method, req.path, rec.Code)
+			}
+		}
+
+		rec := doRequest(t, mux, "alice", nil, http.MethodGet, workoutPath, "")
+		var after models.Workout
+		if err := json.Unmarshal(rec.Body.Bytes(), &after); err != nil {
+			t.Fatal(err)
+		}
+		if after.Name != workout.Name || len(after.Exercises) != 1 || len(after.Exercises[0].Sets) != 1 || after.Exercises[0].Sets[0].Weight != 100 {
+			t.Errorf("alice's workout changed after bob's requests: %+v", after)
+		}
+	})
+
+	t.Run("analytics", func(t *testing.T) {
+		rec := doRequest(t, mux, "bob", nil, http.MethodGet, "/api/workouts/setRep?exercise=Bench+Press&reps=5", "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("bob's set rep max: status %d, want 404", rec.Code)
+		}
+		for _, path := range []string{"/api/analytics/1rm", "/api/prs", "/api/analytics/muscle-volume"} {
+			rec := doRequest(t, mux, "bob", nil, http.MethodGet, path, "")
+			if rec.Code != http.StatusOK {
+				t.Errorf("bob GET %s: status %d", path, rec.Code)
+			}
+			if bytes.Contains(rec.Body.Bytes(), []byte("Bench Press")) {
+				t.Errorf("bob GET %s returned alice's bench press: %s", path, rec.Body)
+			}
+		}
+		rec = doRequest(t, mux, "alice", nil, http.MethodGet, "/api/analytics/1rm", "")
+		if !bytes.Contains(rec.Body.Bytes(), []byte("Bench Press")) {
+			t.Errorf("alice's one rep maxes miss her bench press: %s", rec.Body)
+		}
+	})
+}
+
+func TestPersonalRecordsFollowHistory(t *testing.T) {
+	mux, _ := newTestServer(t)
+	logBench := func(date string, weight float64) (int, []models.PersonalRecord) {
+		t.Helper()
+		body := fmt.Sprintf(`{"name":"Bench","created_at":"%sT10:00:00Z","exercises":[{"exercise":"Bench Press","sets":[{"reps":5,"weight":%v}]}]}`, date, weight)
+		rec := doRequest(t, mux, "alice", nil, http.MethodPost, "/api/workouts", body)
+		var logged struct {
+			WorkoutID       int                     `json:"workout_id"`
+			PersonalRecords []models.PersonalRecord `json:"personal_records"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &logged); err != nil {
+			t.Fatalf("logging workout: %d %s", rec.Code, rec.Body)
+		}
+		return logged.WorkoutID, logged.PersonalRecords
+	}
+	storedRecords := func() []models.PersonalRecord {
+		t.Helper()
+		rec := doRequest(t, mux, "alice", nil, http.MethodGet, "/api/prs", "")
+		var records []models.PersonalRecord
+		if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+			t.Fatal(err)
+		}
+		return records
+	}
+	recordWorkouts := func() map[int]int {
+		t.Helper()
+		counts := make(map[int]int)
+		for _, record := range storedRecords() {
+			counts[record.WorkoutID]++
+		}
+		return counts
+	}
+
+	first, _ := logBench("2026-01-10", 100)
+	second, records := logBench("2026-01-20", 110)
+	if len(records) == 0 {
+		t.Fatal("heavier session reported no records")
+	}
+
+	//a later session that breaks nothing leaves the stored records and their ids alone
+	before := storedRecords()
+	logBench("2026-01-25", 90)
+	if after := storedRecords(); !reflect.DeepEqual(after, before) {
+		t.Errorf("records after a lighter session = %+v, want %+v", after, before)
+	}
+
+	//back-logging a heavier session between the two takes the records of the later one
+	backlogged, records := logBench("2026-01-15", 120)
+	if len(records) == 0 {
+		t.Error("back-logged session beating the one before it reported no records")
+	}
+	if counts := recordWorkouts(); counts[second] != 0 || counts[backlogged] == 0 || counts[first] != 0 {
+		t.Errorf("records per workout after back-logging = %v", counts)
+	}
+
+	rec := doRequest(t, mux, "alice", nil, http.MethodDelete, fmt.Sprintf("/api/workouts/%d", backlogged), "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("deleting workout: %d %s", rec.Code, rec.Body)
+	}
+	if counts := recordWorkouts(); counts[second] == 0 || len(counts) != 1 {
+		t.Errorf("records per workout after deleting = %v", counts)
+	}
+
+	rec = doRequest(t, mux, "alice", nil, http.MethodPut, fmt.Sprintf("/api/workouts/%d", second),
+		`{"name":"Bench","exercises":[{"exercise":"Bench Press","sets":[{"reps":5,"weight":90}]}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("replacing workout: %d %s", rec.Code, rec.Body)
+	}
+	if counts := recordWorkouts(); len(counts) != 0 {
+		t.Errorf("records per workout after lowering the weight = %v", counts)
+	}
+}
+
+func TestFreeTextExercisesAreLoggedUnlinked(t *testing.T) {
+	mux, _ := newTestServer(t)
+	rec := doRequest(t, mux, "alice", nil, http.MethodPost, "/api/workouts",
+		`{"name":"Odd day","exercises":[{"exercise":"Tire Flip","sets":[{"reps":5,"weight":200}]}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("logging a free text exercise: %d %s", rec.Code, rec.Body)
+	}
+	var logged struct {
+		WorkoutID int `json:"workout_id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &logged); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = doRequest(t, mux, "alice", nil, http.MethodGet, fmt.Sprintf("/api/workouts/%d", logged.WorkoutID), "")
+	var workout models.Workout
+	if err := json.Unmarshal(rec.Body.Bytes(), &workout); err != nil {
+		t.Fatal(err)
+	}
+	if exercise := workout.Exercises[0]; exercise.Exercise != "Tire Flip" || exercise.CatalogID != 0 || !exercise.Unlinked {
+		t.Fatalf("free text exercise: %+v", exercise)
+	}
+}
+
+func TestWorkoutTimesWithOffsetsAreComparedInUTC(t *testing.T) {
+	mux, _ := newTestServer(t)
+	logAt := func(createdAt string, weight float64) int {
+		t.Helper()
+		body := fmt.Sprintf(`{"name":"Bench","created_at":%q,"exercises":[{"exercise":"Bench Press","sets":[{"reps":5,"weight":%v}]}]}`, createdAt, weight)
+		rec := doRequest(t, mux, "alice", nil, http.MethodPost, "/api/workouts", body)
+		var logged struct {
+			WorkoutID int `json:"workout_id"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &logged); err != nil || logged.WorkoutID == 0 {
+			t.Fatalf("logging workout: %d %s", rec.Code, rec.Body)
+		}
+		return logged.WorkoutID
+	}
+	//in local time the order is 11:00, 12:00, 16:30; in UTC it is 12:00, 13:30, 15:00
+	noon := logAt("2026-03-01T12:00:00Z", 100)
+	late := logAt("2026-03-01T11:00:00-04:00", 110)
+	early := logAt("2026-03-01T16:30:00+03:00", 110)
+
+	list := func(params string) []int {
+		t.Helper()
+		rec := doRequest(t, mux, "alice", nil, http.MethodGet, "/api/workouts?order=asc&"+params, "")
+		var page models.WorkoutPage
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("listing workouts: %d %s", rec.Code, rec.Body)
+		}
+		ids := []int{}
+		for _, workout := range page.Workouts {
+			ids = append(ids, workout.ID)
+		}
+		return ids
+	}
+	for _, test := range []struct {
+		params string
+		want   []int
+	}{
+		{"", []int{noon, early, late}},
+		{"from=2026-03-01T13:00:00Z", []int{early, late}},
+		{"to=2026-03-01T14:00:00Z", []int{noon, early}},
+		{"from=2026-03-01T14:00:00%2B01:00&to=2026-03-01T10:00:00-04:00", []int{early}},
+	} {
+		if got := list(test.params); fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("workouts for %q = %v, want %v", test.params, got, test.want)
+		}
+	}
+
+	//the first session to lift 110 holds the records, not the later tie
+	rec := doRequest(t, mux, "alice", nil, http.MethodGet, "/api/prs", "")
+	var records []models.PersonalRecord
+	if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if record.WorkoutID != early {
+			t.Errorf("record %s held by workout %d, want %d", record.RecordType, record.WorkoutID, early)
+		}
+	}
+	if len(records) == 0 {
+		t.Error("no records")
+	}
+}
+
+func TestPatchExercisePicksTheExerciseOneWay(t *testing.T) {
+	mux, _ := newTestServer(t)
+	workout := logTestWorkout(t, mux, "alice")
+	exercise := workout.Exercises[0]
+	path := fmt.Sprintf("/api/workouts/%d/exercises/%d", workout.ID, exercise.ID)
+
+	rec := doRequest(t, mux, "alice", nil, http.MethodPatch, path, fmt.Sprintf(`{"exercise":"Back Squat","catalog_id":%d}`, exercise.CatalogID))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("patching both exercise and catalog_id: status %d, want 400", rec.Code)
+	}
+	rec = doRequest(t, mux, "alice", nil, http.MethodPatch, path, `{"exercise":"Back Squat"}`)
+	var patched models.ExerciseLog
+	if err := json.Unmarshal(rec.Body.Bytes(), &patched); err != nil {
+		t.Fatalf("patching the exercise name: %d %s", rec.Code, rec.Body)
+	}
+	if patched.Exercise != "Back Squat" || patched.CatalogID == 0 || patched.CatalogID == exercise.CatalogID {
+		t.Errorf("patched exercise = %+v", patched)
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"the-gym-app/internal/models"
+)
+
+// WorkoutByID serves GET/PUT/PATCH/DELETE on /api/workouts/{id}.
+func (h *WorkoutHandler) WorkoutByID(w http.ResponseWriter, r *http.Request) {
+	workoutID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	//records depend on every set of an exercise, so recompute them after any edit
+	if r.Method != http.MethodGet {
+		defer h.refreshRecords(userID, workoutID, h.exerciseNames(userID, workoutID))
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.writeWorkout(w, userID, workoutID)
+	case http.MethodPut:
+		var workout models.Workout
+		if err := json.NewDecoder(r.Body).Decode(&workout); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.ReplaceWorkout(userID, workoutID, &workout); err != nil {
+			writeStoreError(w, err, "Failed to update workout")
+			return
+		}
+		h.writeWorkout(w, userID, workoutID)
+	case http.MethodPatch:
+		var patch models.WorkoutPatch
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.PatchWorkout(userID, workoutID, &patch); err != nil {
+			writeStoreError(w, err, "Failed to update workout")
+			return
+		}
+		h.writeWorkout(w, userID, workoutID)
+	case http.MethodDelete:
+		if err := h.dbService.DeleteWorkout(userID, workoutID); err != nil {
+			writeStoreError(w, err, "Failed to delete workout")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ExerciseByID serves GET/PUT/PATCH/DELETE on /api/workouts/{id}/exercises/{exerciseID}.
+func (h *WorkoutHandler) ExerciseByID(w http.ResponseWriter, r *http.Request) {
+	workoutID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	exerciseID, ok := pathID(w, r, "exerciseID")
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	//records depend on every set of an exercise, so recompute them after any edit
+	if r.Method != http.MethodGet {
+		defer h.refreshRecords(userID, workoutID, h.exerciseNames(userID, workoutID))
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.writeExercise(w, userID, workoutID, exerciseID)
+	case http.MethodPut:
+		var exercise models.ExerciseLog
+		if err := json.NewDecoder(r.Body).Decode(&exercise); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.ReplaceExercise(userID, workoutID, exerciseID, &exercise); err != nil {
+			writeStoreError(w, err, "Failed to update exercise")
+			return
+		}
+		h.writeExercise(w, userID, workoutID, exerciseID)
+	case http.MethodPatch:
+		var patch models.ExercisePatch
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		//either one picks the exercise, so sending both would leave one of them ignored
+		if patch.Exercise != nil && patch.CatalogID != nil {
+			http.Error(w, "send either exercise or catalog_id, not both", http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.PatchExercise(userID, workoutID, exerciseID, &patch); err != nil {
+			writeStoreError(w, err, "Failed to update exercise")
+			return
+		}
+		h.writeExercise(w, userID, workoutID, exerciseID)
+	case http.MethodDelete:
+		if err := h.dbService.DeleteExercise(userID, workoutID, exerciseID); err != nil {
+			writeStoreError(w, err, "Failed to delete exercise")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// SetByID serves GET/PUT/PATCH/DELETE on /api/workouts/{id}/exercises/{exerciseID}/sets/{setID}.
+func (h *WorkoutHandler) SetByID(w http.ResponseWriter, r *http.Request) {
+	workoutID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	exerciseID, ok := pathID(w, r, "exerciseID")
+	if !ok {
+		return
+	}
+	setID, ok := pathID(w, r, "setID")
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromRequest(h.dbService, w, r)
+	if !ok {
+		return
+	}
+	//records depend on every set of an exercise, so recompute them after any edit
+	if r.Method != http.MethodGet {
+		defer h.refreshRecords(userID, workoutID, h.exerciseNames(userID, workoutID))
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.writeSet(w, userID, workoutID, exerciseID, setID)
+	case http.MethodPut:
+		var set models.Set
+		if err := json.NewDecoder(r.Body).Decode(&set); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.ReplaceSet(userID, workoutID, exerciseID, setID, &set); err != nil {
+			writeStoreError(w, err, "Failed to update set")
+			return
+		}
+		h.writeSet(w, userID, workoutID, exerciseID, setID)
+	case http.MethodPatch:
+		var patch models.SetPatch
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.dbService.PatchSet(userID, workoutID, exerciseID, setID, &patch); err != nil {
+			writeStoreError(w, err, "Failed to update set")
+			return
+		}
+		h.writeSet(w, userID, workoutID, exerciseID, setID)
+	case http.MethodDelete:
+		if err := h.dbService.DeleteSet(userID, workoutID, exerciseID, setID); err != nil {
+			writeStoreError(w, err, "Failed to delete set")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *WorkoutHandler) writeWorkout(w http.ResponseWriter, userID, workoutID int) {
+	workout, err := h.dbService.GetWorkout(userID, workoutID)
+	if err != nil {
+		writeStoreError(w, err, "Unable to fetch workout")
+		return
+	}
+	writeJSON(w, workout)
+}
+
+func (h *WorkoutHandler) writeExercise(w http.ResponseWriter, userID, workoutID, exerciseID int) {
+	exercise, err := h.dbService.GetExercise(userID, workoutID, exerciseID)
+	if err != nil {
+		writeStoreError(w, err, "Unable to fetch exercise")
+		return
+	}
+	writeJSON(w, exercise)
+}
+
+func (h *WorkoutHandler) writeSet(w http.ResponseWriter, userID, workoutID, exerciseID, setID int) {
+	set, err := h.dbService.GetSet(userID, workoutID, exerciseID, setID)
+	if err != nil {
+		writeStoreError(w, err, "Unable to fetch set")
+		return
+	}
+	writeJSON(w, set)
+}
+
+// exerciseNames returns the exercises logged in a workout, or nil if the user cannot
+// read it; the edit that follows reports that error.
+func (h *WorkoutHandler) exerciseNames(userID, workoutID int) []string {
+	workout, err := h.dbService.GetWorkout(userID, workoutID)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(workout.Exercises))
+	for i, exercise := range workout.Exercises {
+		names[i] = exercise.Exercise
+	}
+	return names
+}
+
+// refreshRecords recomputes the personal records of the exercises a workout had
+// before an edit and has after it, which covers renamed, removed and deleted ones.
+// The edit is already saved, so a failure is logged rather than returned.
+func (h *WorkoutHandler) refreshRecords(userID, workoutID int, before []string) {
+	exercises := append(before, h.exerciseNames(userID, workoutID)...)
+	if len(exercises) == 0 {
+		return
+	}
+	if err := h.recordService.RefreshPersonalRecords(userID, exercises); err != nil {
+		log.Printf("Error recomputing personal records: %v", err)
+	}
+}
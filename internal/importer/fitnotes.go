@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"the-gym-app/internal/models"
+)
+
+// fitNotesRow reads a row of a FitNotes export:
+//
+//	Date,Exercise,Category,Weight (kgs),Reps,Distance,Distance Unit,Time,Comment
+//
+// The weight column is "Weight (lbs)" for accounts using pounds. FitNotes has no
+// workout times or names, so every date is one session named after the categories
+// trained that day.
+func fitNotesRow(columns map[string]int, opts Options) func(row) (rowSet, error) {
+	weightColumn, weightUnit := "weight (kgs)", UnitKilograms
+	if _, ok := columns["weight (lbs)"]; ok {
+		weightColumn, weightUnit = "weight (lbs)", UnitPounds
+	}
+	//categories seen per date, to name the session
+	categories := make(map[string][]string)
+
+	return func(r row) (rowSet, error) {
+		var parsed rowSet
+		date := r.get("date")
+		startedAt, err := parseTime(date, opts.Location, "2006-01-02", "2006-01-02 15:04:05")
+		if err != nil {
+			return parsed, err
+		}
+		weight, err := r.float(weightColumn)
+		if err != nil {
+			return parsed, err
+		}
+		reps, err := r.int("reps")
+		if err != nil {
+			return parsed, err
+		}
+		distance, err := r.float("distance")
+		if err != nil {
+			return parsed, err
+		}
+		distanceMeters := 0.0
+		if distance > 0 {
+			if distanceMeters, err = meters(distance, r.get("distance unit")); err != nil {
+				return parsed, err
+			}
+		}
+		seconds, err := parseDuration(r.get("time"))
+		if err != nil {
+			return parsed, err
+		}
+
+		if category := r.get("category"); category != "" && !containsFold(categories[date], category) {
+			categories[date] = append(categories[date], category)
+		}
+		parsed.sessionKey = date
+		parsed.startedAt = startedAt
+		parsed.workoutName = strings.Join(categories[date], ", ")
+		if parsed.workoutName == "" {
+			parsed.workoutName = "Workout"
+		}
+		parsed.exercise = r.get("exercise")
+		parsed.notes = r.get("comment")
+		parsed.set = models.Set{
+			Reps:            reps,
+			Weight:          convertWeight(weight, weightUnit, opts),
+			Type:            models.SetTypeWorking,
+			DurationSeconds: seconds,
+			DistanceMeters:  distanceMeters,
+		}
+		if parsed.exercise == "" {
+			return parsed, fmt.Errorf("exercise is empty")
+		}
+		return parsed, nil
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
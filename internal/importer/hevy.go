@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"fmt"
+	"the-gym-app/internal/models"
+)
+
+// hevyRow reads a row of a Hevy export:
+//
+//	title,start_time,end_time,description,exercise_title,superset_id,exercise_notes,set_index,set_type,weight_kg,reps,distance_km,duration_seconds,rpe
+//
+// Depending on the account settings the weight and distance columns are weight_lbs
+// and distance_miles instead. Exercises sharing a superset_id become a group.
+func hevyRow(columns map[string]int, opts Options) func(row) (rowSet, error) {
+	weightColumn, weightUnit := "weight_kg", UnitKilograms
+	if _, ok := columns["weight_lbs"]; ok {
+		weightColumn, weightUnit = "weight_lbs", UnitPounds
+	}
+	distanceColumn, distanceUnit := "distance_km", UnitKilometers
+	if _, ok := columns["distance_miles"]; ok {
+		distanceColumn, distanceUnit = "distance_miles", UnitMiles
+	}
+
+	return func(r row) (rowSet, error) {
+		var parsed rowSet
+		start := r.get("start_time")
+		startedAt, err := parseTime(start, opts.Location, "2 Jan 2006, 15:04", "2 Jan 2006 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00")
+		if err != nil {
+			return parsed, err
+		}
+
+		setType := models.SetTypeWorking
+		switch r.get("set_type") {
+		case "warmup":
+			setType = models.SetTypeWarmup
+		case "dropset":
+			setType = models.SetTypeDrop
+		case "failure":
+			setType = models.SetTypeFailure
+		}
+		weight, err := r.float(weightColumn)
+		if err != nil {
+			return parsed, err
+		}
+		reps, err := r.int("reps")
+		if err != nil {
+			return parsed, err
+		}
+		distance, err := r.float(distanceColumn)
+		if err != nil {
+			return parsed, err
+		}
+		distanceMeters, _ := meters(distance, distanceUnit)
+		seconds, err := r.int("duration_seconds")
+		if err != nil {
+			return parsed, err
+		}
+		rpe, err := r.float("rpe")
+		if err != nil {
+			return parsed, err
+		}
+		if rpe > 10 {
+			return parsed, fmt.Errorf("invalid rpe %v", rpe)
+		}
+
+		parsed.sessionKey = start + "|" + r.get("title")
+		parsed.startedAt = startedAt
+		parsed.workoutName = r.get("title")
+		parsed.exercise = r.get("exercise_title")
+		parsed.notes = r.get("exercise_notes")
+		if superset := r.get("superset_id"); superset != "" {
+			parsed.group = "superset " + superset
+		}
+		parsed.set = models.Set{
+			Reps:            reps,
+			Weight:          convertWeight(weight, weightUnit, opts),
+			RPE:             rpe,
+			Type:            setType,
+			DurationSeconds: seconds,
+			DistanceMeters:  distanceMeters,
+		}
+		if parsed.exercise == "" {
+			return parsed, fmt.Errorf("exercise_title is empty")
+		}
+		return parsed, nil
+	}
+}
@@ -0,0 +1,377 @@
+// Package importer reads workout history exported by other training apps.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// Format is an export format the importer understands.
+type Format string
+
+// Supported formats.
+const (
+	FormatStrong   Format = "strong"
+	FormatHevy     Format = "hevy"
+	FormatFitNotes Format = "fitnotes"
+)
+
+// Formats lists every supported format.
+var Formats = []Format{FormatStrong, FormatHevy, FormatFitNotes}
+
+// Weight and distance units.
+const (
+	UnitKilograms  = "kg"
+	UnitPounds     = "lb"
+	UnitKilometers = "km"
+	UnitMiles      = "mi"
+)
+
+const (
+	poundsPerKilogram = 2.20462262
+	metersPerMile     = 1609.344
+)
+
+// ErrUnknownFormat is returned when the header of a file matches no supported format.
+var ErrUnknownFormat = errors.New("unrecognised export format")
+
+// Options control how a file is read.
+type Options struct {
+	// Format forces a format instead of detecting it from the header.
+	Format Format
+	// WeightUnit is the unit weights are imported in, kg by default. Strong does not
+	// record its unit, so its weights are taken to already be in this unit.
+	WeightUnit string
+	// DistanceUnit is the unit distances of Strong exports are in, km by default.
+	DistanceUnit string
+	// Location is the time zone the timestamps of the file are in, UTC by default.
+	Location *time.Location
+}
+
+func (o *Options) normalise() error {
+	switch strings.ToLower(o.WeightUnit) {
+	case "", "kg", "kgs":
+		o.WeightUnit = UnitKilograms
+	case "lb", "lbs":
+		o.WeightUnit = UnitPounds
+	default:
+		return fmt.Errorf("weight unit must be kg or lb, got %q", o.WeightUnit)
+	}
+	switch strings.ToLower(o.DistanceUnit) {
+	case "", "km":
+		o.DistanceUnit = UnitKilometers
+	case "mi", "mile", "miles":
+		o.DistanceUnit = UnitMiles
+	default:
+		return fmt.Errorf("distance unit must be km or mi, got %q", o.DistanceUnit)
+	}
+	if o.Location == nil {
+		o.Location = time.UTC
+	}
+	return nil
+}
+
+// ParseFormat validates a format name. An empty name detects the format from the file.
+func ParseFormat(value string) (Format, error) {
+	if value == "" {
+		return "", nil
+	}
+	for _, format := range Formats {
+		if strings.EqualFold(value, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("format must be one of strong, hevy, fitnotes")
+}
+
+// Parse reads an export and returns its sessions oldest first, together with the
+// format it was read as. Exercise names are left as they appear in the file.
+func Parse(r io.Reader, opts Options) ([]models.ImportedSession, Format, error) {
+	if err := opts.normalise(); err != nil {
+		return nil, "", err
+	}
+	reader, err := newCSVReader(r)
+	if err != nil {
+		return nil, "", err
+	}
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, "", fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	format := opts.Format
+	if format == "" {
+		if format, err = detectFormat(columns); err != nil {
+			return nil, "", err
+		}
+	}
+	var parse func(row) (rowSet, error)
+	switch format {
+	case FormatStrong:
+		parse = strongRow(opts)
+	case FormatHevy:
+		parse = hevyRow(columns, opts)
+	case FormatFitNotes:
+		parse = fitNotesRow(columns, opts)
+	default:
+		return nil, "", ErrUnknownFormat
+	}
+
+	builder := newSessionBuilder(format)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if blank(record) {
+			continue
+		}
+		set, err := parse(row{columns: columns, record: record})
+		if err != nil {
+			return nil, "", fmt.Errorf("line %d: %v", line, err)
+		}
+		if set.skip {
+			continue
+		}
+		builder.add(set)
+	}
+	return builder.sessions(), format, nil
+}
+
+func detectFormat(columns map[string]int) (Format, error) {
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := columns[name]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case has("exercise name", "set order"):
+		return FormatStrong, nil
+	case has("exercise_title", "start_time"):
+		return FormatHevy, nil
+	case has("exercise", "category", "reps"):
+		return FormatFitNotes, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// newCSVReader drops a byte order mark and picks ";" as the separator for exports
+// from locales that use a decimal comma.
+func newCSVReader(r io.Reader) (*csv.Reader, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		buffered.Discard(3)
+	}
+	firstLine, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if i := bytes.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	reader := csv.NewReader(buffered)
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader, nil
+}
+
+func blank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// row gives access to the fields of a record by column name.
+type row struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r row) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r row) float(column string) (float64, error) {
+	value := strings.ReplaceAll(r.get(column), ",", ".")
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid %s %q", column, r.get(column))
+	}
+	return f, nil
+}
+
+func (r row) int(column string) (int, error) {
+	f, err := r.float(column)
+	return int(math.Round(f)), err
+}
+
+// rowSet is one parsed row: a set together with the session and exercise it belongs to.
+type rowSet struct {
+	skip        bool
+	sessionKey  string
+	startedAt   time.Time
+	workoutName string
+	exercise    string
+	notes       string
+	group       string
+	set         models.Set
+}
+
+// sessionBuilder collects rows into sessions. Consecutive rows of the same exercise
+// form one exercise of the session.
+type sessionBuilder struct {
+	format Format
+	order  []string
+	byKey  map[string]*models.ImportedSession
+	groups map[string]map[string]bool
+}
+
+func newSessionBuilder(format Format) *sessionBuilder {
+	return &sessionBuilder{format: format, byKey: make(map[string]*models.ImportedSession), groups: make(map[string]map[string]bool)}
+}
+
+func (b *sessionBuilder) add(r rowSet) {
+	session, ok := b.byKey[r.sessionKey]
+	if !ok {
+		session = &models.ImportedSession{
+			Key:     r.sessionKey,
+			Workout: models.Workout{Name: r.workoutName, CreatedAt: r.startedAt, Exercises: []models.ExerciseLog{}},
+		}
+		b.byKey[r.sessionKey] = session
+		b.order = append(b.order, r.sessionKey)
+		b.groups[r.sessionKey] = make(map[string]bool)
+	}
+	//FitNotes names a session after everything trained that day, so the last row wins
+	session.Workout.Name = r.workoutName
+
+	exercises := session.Workout.Exercises
+	n := len(exercises)
+	if n == 0 || exercises[n-1].Exercise != r.exercise || exercises[n-1].Group != r.group {
+		session.Workout.Exercises = append(session.Workout.Exercises, models.ExerciseLog{Exercise: r.exercise, Group: r.group, Sets: []models.Set{}})
+		n++
+		if r.group != "" && !b.groups[r.sessionKey][r.group] {
+			b.groups[r.sessionKey][r.group] = true
+			session.Workout.Groups = append(session.Workout.Groups, models.ExerciseGroup{Label: r.group})
+		}
+	}
+	set := r.set
+	if set.Remarks == "" {
+		set.Remarks = r.notes
+	}
+	session.Workout.Exercises[n-1].Sets = append(session.Workout.Exercises[n-1].Sets, set)
+}
+
+func (b *sessionBuilder) sessions() []models.ImportedSession {
+	sessions := make([]models.ImportedSession, 0, len(b.order))
+	for _, key := range b.order {
+		session := *b.byKey[key]
+		session.Key = string(b.format) + ":" + key
+		sessions = append(sessions, session)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Workout.CreatedAt.Before(sessions[j].Workout.CreatedAt)
+	})
+	return sessions
+}
+
+// parseTime parses value with the first layout that fits, in loc.
+func parseTime(value string, loc *time.Location, layouts ...string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// parseDuration reads "1:05:30", "05:30", "1h 5m" or a plain number of seconds.
+func parseDuration(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if strings.Contains(value, ":") {
+		total := 0
+		for _, part := range strings.Split(value, ":") {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			total = total*60 + n
+		}
+		return total, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return int(math.Round(seconds)), nil
+	}
+	d, err := time.ParseDuration(strings.ReplaceAll(value, " ", ""))
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return int(d.Seconds()), nil
+}
+
+// convertWeight converts weight from unit to the unit of opts, rounded to 0.01.
+func convertWeight(weight float64, unit string, opts Options) float64 {
+	switch {
+	case unit == UnitKilograms && opts.WeightUnit == UnitPounds:
+		weight *= poundsPerKilogram
+	case unit == UnitPounds && opts.WeightUnit == UnitKilograms:
+		weight /= poundsPerKilogram
+	}
+	return math.Round(weight*100) / 100
+}
+
+// meters converts a distance in unit to meters.
+func meters(distance float64, unit string) (float64, error) {
+	switch strings.ToLower(unit) {
+	case "", UnitKilometers, "kms":
+		return distance * 1000, nil
+	case "m", "meters", "metres":
+		return distance, nil
+	case UnitMiles, "mile", "miles":
+		return distance * metersPerMile, nil
+	case "ft", "feet":
+		return distance * 0.3048, nil
+	case "yd", "yards":
+		return distance * 0.9144, nil
+	}
+	return 0, fmt.Errorf("unknown distance unit %q", unit)
+}
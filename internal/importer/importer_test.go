@@ -0,0 +1,262 @@
+package importer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+func parse(t *testing.T, file string, opts Options) ([]models.ImportedSession, Format) {
+	t.Helper()
+	sessions, format, err := Parse(strings.NewReader(file), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sessions, format
+}
+
+// describe lists the exercises of a session with their sets as "type weight x reps".
+func describe(session models.ImportedSession) []string {
+	var lines []string
+	for _, exercise := range session.Workout.Exercises {
+		line := exercise.Exercise
+		if exercise.Group != "" {
+			line += " [" + exercise.Group + "]"
+		}
+		for _, set := range exercise.Sets {
+			line += fmt.Sprintf(", %s %gx%d", set.Type, set.Weight, set.Reps)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestParseStrong(t *testing.T) {
+	//a byte order mark, ";" separators and decimal commas, as exported in many locales
+	file := "\ufeffDate;Workout Name;Duration;Exercise Name;Set Order;Weight;Reps;Distance;Seconds;Notes;Workout Notes;RPE\n" +
+		"2024-03-02 10:00:00;Pull;50m;Deadlift (Barbell);1;180;3;0;0;;;9\n" +
+		"2024-03-01 18:30:00;Push;1h 5m;Bench Press (Barbell);W;60;10;0;0;;;\n" +
+		"2024-03-01 18:30:00;Push;1h 5m;Bench Press (Barbell);1;102,5;5;0;0;paused;;8,5\n" +
+		"2024-03-01 18:30:00;Push;1h 5m;Bench Press (Barbell);Rest Timer;0;0;0;90;;;\n" +
+		"2024-03-01 18:30:00;Push;1h 5m;Bench Press (Barbell);D;80;8;0;0;;;\n" +
+		"2024-03-01 18:30:00;Push;1h 5m;Bench Press (Barbell);F;70;6;0;0;;;\n" +
+		"\n" +
+		"2024-03-01 18:30:00;Push;1h 5m;Running;1;0;0;5;1500;;;\n"
+	sessions, format := parse(t, file, Options{Location: time.FixedZone("CET", 3600)})
+	if format != FormatStrong || len(sessions) != 2 {
+		t.Fatalf("read %d sessions as %s", len(sessions), format)
+	}
+
+	push := sessions[0]
+	if push.Key != "strong:2024-03-01 18:30:00|Push" || push.Workout.Name != "Push" || !push.Workout.CreatedAt.Equal(time.Date(2024, 3, 1, 17, 30, 0, 0, time.UTC)) {
+		t.Errorf("first session = %s %q at %v", push.Key, push.Workout.Name, push.Workout.CreatedAt)
+	}
+	want := []string{
+		"Bench Press (Barbell), warmup 60x10, working 102.5x5, drop 80x8, failure 70x6",
+		"Running, working 0x0",
+	}
+	if got := describe(push); !reflect.DeepEqual(got, want) {
+		t.Errorf("first session = %q, want %q", got, want)
+	}
+	bench := push.Workout.Exercises[0].Sets[1]
+	if bench.RPE != 8.5 || bench.Remarks != "paused" {
+		t.Errorf("bench set = %+v", bench)
+	}
+	run := push.Workout.Exercises[1].Sets[0]
+	if run.DistanceMeters != 5000 || run.DurationSeconds != 1500 {
+		t.Errorf("run = %+v", run)
+	}
+	if sessions[1].Workout.Name != "Pull" {
+		t.Errorf("second session = %q", sessions[1].Workout.Name)
+	}
+
+	//Strong does not record its unit, so weights are taken as they are
+	sessions, _ = parse(t, file, Options{WeightUnit: "lb", DistanceUnit: "mi"})
+	if set := sessions[0].Workout.Exercises[0].Sets[1]; set.Weight != 102.5 {
+		t.Errorf("weight in lb = %v", set.Weight)
+	}
+	if set := sessions[0].Workout.Exercises[1].Sets[0]; set.DistanceMeters != 5*metersPerMile {
+		t.Errorf("distance in miles = %v", set.DistanceMeters)
+	}
+}
+
+func TestParseHevy(t *testing.T) {
+	file := "title,start_time,end_time,description,exercise_title,superset_id,exercise_notes,set_index,set_type,weight_lbs,reps,distance_miles,duration_seconds,rpe\n" +
+		`"Arms","5 Apr 2024, 17:00","5 Apr 2024, 18:00","",Bicep Curl (Dumbbell),0,,0,warmup,20,12,,,` + "\n" +
+		`"Arms","5 Apr 2024, 17:00","5 Apr 2024, 18:00","",Bicep Curl (Dumbbell),0,,1,normal,35,10,,,8` + "\n" +
+		`"Arms","5 Apr 2024, 17:00","5 Apr 2024, 18:00","",Triceps Pushdown,0,,0,dropset,60,12,,,` + "\n" +
+		`"Arms","5 Apr 2024, 17:00","5 Apr 2024, 18:00","",Bicep Curl (Dumbbell),0,,2,failure,35,8,,,` + "\n" +
+		`"Arms","5 Apr 2024, 17:00","5 Apr 2024, 18:00","",Plank,,,0,normal,,,,60,` + "\n" +
+		`"Arms","5 Apr 2024, 17:00","5 Apr 2024, 18:00","",Treadmill,,,0,normal,,,2,900,` + "\n"
+	sessions, format := parse(t, file, Options{})
+	if format != FormatHevy || len(sessions) != 1 {
+		t.Fatalf("read %d sessions as %s", len(sessions), format)
+	}
+	session := sessions[0]
+	//exercises of one superset alternate, and each run of sets is an exercise of the group
+	want := []string{
+		"Bicep Curl (Dumbbell) [superset 0], warmup 9.07x12, working 15.88x10",
+		"Triceps Pushdown [superset 0], drop 27.22x12",
+		"Bicep Curl (Dumbbell) [superset 0], failure 15.88x8",
+		"Plank, working 0x0",
+		"Treadmill, working 0x0",
+	}
+	if got := describe(session); !reflect.DeepEqual(got, want) {
+		t.Errorf("session = %q, want %q", got, want)
+	}
+	if len(session.Workout.Groups) != 1 || session.Workout.Groups[0].Label != "superset 0" {
+		t.Errorf("groups = %+v", session.Workout.Groups)
+	}
+	if plank := session.Workout.Exercises[3].Sets[0]; plank.DurationSeconds != 60 {
+		t.Errorf("plank = %+v", plank)
+	}
+	if run := session.Workout.Exercises[4].Sets[0]; run.DistanceMeters != 2*metersPerMile {
+		t.Errorf("treadmill = %+v", run)
+	}
+
+	//weight_kg columns are converted when importing in pounds
+	file = "title,start_time,end_time,description,exercise_title,superset_id,exercise_notes,set_index,set_type,weight_kg,reps,distance_km,duration_seconds,rpe\n" +
+		`"Legs","2024-04-06 09:00:00","","",Squat (Barbell),,,0,normal,100,5,,,` + "\n"
+	sessions, _ = parse(t, file, Options{WeightUnit: "lbs"})
+	if got := describe(sessions[0]); !reflect.DeepEqual(got, []string{"Squat (Barbell), working 220.46x5"}) {
+		t.Errorf("kg in pounds = %q", got)
+	}
+}
+
+func TestParseFitNotes(t *testing.T) {
+	file := "Date,Exercise,Category,Weight (lbs),Reps,Distance,Distance Unit,Time,Comment\n" +
+		"2024-05-01,Flat Barbell Bench Press,Chest,225.0,5,,,,\n" +
+		"2024-05-01,Barbell Squat,Legs,315.0,5,,,,felt good\n" +
+		"2024-05-01,Flat Barbell Bench Press,chest,225.0,5,,,,\n" +
+		"2024-05-02,Running,Cardio,,,5.0,km,0:25:30,\n" +
+		"2024-05-03,Plank,,,,,,1:30,\n"
+	sessions, format := parse(t, file, Options{})
+	if format != FormatFitNotes || len(sessions) != 3 {
+		t.Fatalf("read %d sessions as %s", len(sessions), format)
+	}
+
+	//a day is one session named after the categories trained that day, in order
+	for i, want := range []string{"Chest, Legs", "Cardio", "Workout"} {
+		if sessions[i].Workout.Name != want {
+			t.Errorf("session %d is named %q, want %q", i, sessions[i].Workout.Name, want)
+		}
+	}
+	want := []string{
+		"Flat Barbell Bench Press, working 102.06x5",
+		"Barbell Squat, working 142.88x5",
+		"Flat Barbell Bench Press, working 102.06x5",
+	}
+	if got := describe(sessions[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("first session = %q, want %q", got, want)
+	}
+	if squat := sessions[0].Workout.Exercises[1].Sets[0]; squat.Remarks != "felt good" {
+		t.Errorf("squat = %+v", squat)
+	}
+	if run := sessions[1].Workout.Exercises[0].Sets[0]; run.DistanceMeters != 5000 || run.DurationSeconds != 1530 {
+		t.Errorf("run = %+v", run)
+	}
+	if plank := sessions[2].Workout.Exercises[0].Sets[0]; plank.DurationSeconds != 90 {
+		t.Errorf("plank = %+v", plank)
+	}
+	if sessions[0].Key != "fitnotes:2024-05-01" {
+		t.Errorf("key = %s", sessions[0].Key)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		file string
+		opts Options
+		want string
+	}{
+		{"empty file", "", Options{}, "file is empty"},
+		{"unknown header", "a,b,c\n1,2,3\n", Options{}, ErrUnknownFormat.Error()},
+		{"unknown unit", "Date,Exercise,Category,Weight (kgs),Reps\n", Options{WeightUnit: "stone"}, "weight unit"},
+		{"bad weight", "Date,Exercise,Category,Weight (kgs),Reps\n2024-05-01,Squat,Legs,heavy,5\n", Options{}, `line 2: invalid weight (kgs) "heavy"`},
+		{"bad date", "Date,Exercise,Category,Weight (kgs),Reps\n01/05/2024,Squat,Legs,100,5\n", Options{}, "line 2: invalid date"},
+		{"rpe above 10", "Date;Workout Name;Exercise Name;Set Order;Weight;Reps;RPE\n2024-03-01;Push;Bench Press;1;100;5;11\n", Options{}, "line 2: invalid rpe"},
+		{"missing exercise", "Date,Exercise,Category,Weight (kgs),Reps\n2024-05-01,,Legs,100,5\n", Options{}, "line 2: exercise is empty"},
+	} {
+		_, _, err := Parse(strings.NewReader(test.file), test.opts)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: err = %v, want %q", test.name, err, test.want)
+		}
+	}
+
+	//a forced format is used even when the header would detect another one
+	_, format, err := Parse(strings.NewReader("Date,Exercise,Category,Weight (kgs),Reps\n"), Options{Format: FormatHevy})
+	if err != nil || format != FormatHevy {
+		t.Errorf("forcing hevy: %s, %v", format, err)
+	}
+	if format, err := ParseFormat("Strong"); err != nil || format != FormatStrong {
+		t.Errorf("ParseFormat(Strong) = %s, %v", format, err)
+	}
+	if _, err := ParseFormat("garmin"); err == nil {
+		t.Error("ParseFormat(garmin) did not fail")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for _, test := range []struct {
+		value string
+		want  int
+	}{
+		{"", 0},
+		{"1:05:30", 3930},
+		{"05:30", 330},
+		{"1h 5m", 3900},
+		{"90", 90},
+		{"12.6", 13},
+	} {
+		got, err := parseDuration(test.value)
+		if err != nil || got != test.want {
+			t.Errorf("parseDuration(%q) = %d, %v, want %d", test.value, got, err, test.want)
+		}
+	}
+	for _, value := range []string{"abc", "1:-5", "-3m"} {
+		if _, err := parseDuration(value); err == nil {
+			t.Errorf("parseDuration(%q) did not fail", value)
+		}
+	}
+}
+
+func TestConvertWeight(t *testing.T) {
+	kg, lb := Options{WeightUnit: UnitKilograms}, Options{WeightUnit: UnitPounds}
+	for _, test := range []struct {
+		weight float64
+		unit   string
+		opts   Options
+		want   float64
+	}{
+		{100, UnitKilograms, kg, 100},
+		{225, UnitPounds, kg, 102.06},
+		{100, UnitKilograms, lb, 220.46},
+		{45, UnitPounds, lb, 45},
+		{102.123, UnitKilograms, kg, 102.12},
+	} {
+		if got := convertWeight(test.weight, test.unit, test.opts); got != test.want {
+			t.Errorf("convertWeight(%v %s) to %s = %v, want %v", test.weight, test.unit, test.opts.WeightUnit, got, test.want)
+		}
+	}
+}
+
+func TestNameCandidates(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want []string
+	}{
+		{"Squat", []string{"Squat"}},
+		{"Bench Press (Barbell)", []string{"Bench Press (Barbell)", "Barbell Bench Press", "Bench Press"}},
+		{"Bicep Curl (Dumbbell)", []string{"Bicep Curl (Dumbbell)", "Biceps Curl (Dumbbell)", "Dumbbell Bicep Curl", "Dumbbell Biceps Curl", "Bicep Curl", "Biceps Curl"}},
+		{"Flat Barbell Bench Press", []string{"Flat Barbell Bench Press", "Barbell Bench Press"}},
+		{" Plank () ", []string{"Plank ()", "Plank"}},
+	} {
+		if got := NameCandidates(test.name); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("NameCandidates(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
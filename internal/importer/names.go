@@ -0,0 +1,34 @@
+package importer
+
+import "strings"
+
+// spellings rewrites words other apps spell differently from the catalog.
+var spellings = strings.NewReplacer("Bicep ", "Biceps ", "bicep ", "biceps ", "Tricep ", "Triceps ", "tricep ", "triceps ")
+
+// NameCandidates returns the names to try, in order, when matching an exported
+// exercise name to the catalog. Strong and Hevy put the equipment in parentheses,
+// as in "Bench Press (Barbell)", which the catalog spells "Barbell Bench Press" or
+// just "Bench Press". FitNotes prefixes bench variations with "Flat".
+func NameCandidates(name string) []string {
+	name = strings.TrimSpace(name)
+	candidates := []string{name}
+	if open := strings.LastIndex(name, "("); open > 0 && strings.HasSuffix(name, ")") {
+		base := strings.TrimSpace(name[:open])
+		if equipment := strings.TrimSpace(name[open+1 : len(name)-1]); equipment != "" {
+			candidates = append(candidates, equipment+" "+base)
+		}
+		candidates = append(candidates, base)
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, candidate := range candidates {
+		for _, variant := range []string{candidate, spellings.Replace(candidate), strings.TrimPrefix(candidate, "Flat ")} {
+			if variant != "" && !seen[variant] {
+				seen[variant] = true
+				result = append(result, variant)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"the-gym-app/internal/models"
+)
+
+// strongRow reads a row of a Strong export:
+//
+//	Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE
+//
+// Set Order is the set number, or W, D and F for warm-up, drop and failure sets.
+// Rest timer rows are skipped.
+func strongRow(opts Options) func(row) (rowSet, error) {
+	return func(r row) (rowSet, error) {
+		var parsed rowSet
+		setType := models.SetTypeWorking
+		switch order := strings.ToUpper(r.get("set order")); order {
+		case "W":
+			setType = models.SetTypeWarmup
+		case "D":
+			setType = models.SetTypeDrop
+		case "F":
+			setType = models.SetTypeFailure
+		default:
+			if strings.Contains(order, "REST") {
+				parsed.skip = true
+				return parsed, nil
+			}
+		}
+
+		date := r.get("date")
+		startedAt, err := parseTime(date, opts.Location, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02")
+		if err != nil {
+			return parsed, err
+		}
+		weight, err := r.float("weight")
+		if err != nil {
+			return parsed, err
+		}
+		reps, err := r.int("reps")
+		if err != nil {
+			return parsed, err
+		}
+		distance, err := r.float("distance")
+		if err != nil {
+			return parsed, err
+		}
+		distanceMeters, _ := meters(distance, opts.DistanceUnit)
+		seconds, err := r.int("seconds")
+		if err != nil {
+			return parsed, err
+		}
+		rpe, err := r.float("rpe")
+		if err != nil {
+			return parsed, err
+		}
+		if rpe > 10 {
+			return parsed, fmt.Errorf("invalid rpe %v", rpe)
+		}
+
+		parsed.sessionKey = date + "|" + r.get("workout name")
+		parsed.startedAt = startedAt
+		parsed.workoutName = r.get("workout name")
+		parsed.exercise = r.get("exercise name")
+		parsed.notes = r.get("notes")
+		parsed.set = models.Set{
+			Reps:            reps,
+			Weight:          convertWeight(weight, opts.WeightUnit, opts),
+			RPE:             rpe,
+			Type:            setType,
+			DurationSeconds: seconds,
+			DistanceMeters:  distanceMeters,
+		}
+		if parsed.exercise == "" {
+			return parsed, fmt.Errorf("exercise name is empty")
+		}
+		return parsed, nil
+	}
+}
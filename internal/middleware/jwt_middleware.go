@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,10 +16,27 @@ import (
 
 type ContextKey string
 
+const (
+	// AccessTokenTTL is how long an access token is accepted by MiddlewareHandler.
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long a refresh token can be exchanged for a new pair.
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// RevocationChecker reports whether an access token has been revoked, by its jwt id.
+type RevocationChecker interface {
+	IsTokenRevoked(jti string) (bool, error)
+}
+
+var revocationChecker RevocationChecker
+
+// SetRevocationChecker registers the store MiddlewareHandler consults for revoked tokens.
+func SetRevocationChecker(checker RevocationChecker) {
+	revocationChecker = checker
+}
+
 func MiddlewareHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("=== JWT Middleware called for: %s ===", r.URL.Path)
-
 		// Get secret key from environment variable
 		secret := os.Getenv("GYM_APP_SECRET_KEY")
 		if secret == "" {
@@ -35,7 +54,6 @@ func MiddlewareHandler(next http.Handler) http.Handler {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		log.Printf("Token string: %s", tokenString[:20]+"...") // Log first 20 chars for security
 
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -57,31 +75,59 @@ func MiddlewareHandler(next http.Handler) http.Handler {
 			return
 		}
 
-		log.Printf("Token is valid, extracting claims...")
-
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			log.Printf("Claims extracted successfully: %+v", claims)
-			ctx := context.WithValue(r.Context(), ContextKey("user"), claims)
-			r = r.WithContext(ctx)
-			log.Printf("Context set successfully")
-		} else {
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
 			log.Printf("Failed to extract claims from token")
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		//reject tokens that were revoked on logout
+		jti, _ := claims["jti"].(string)
+		if jti == "" {
+			log.Printf("Token has no jti")
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
 		}
+		if revocationChecker != nil {
+			revoked, err := revocationChecker.IsTokenRevoked(jti)
+			if err != nil {
+				log.Printf("Error checking token revocation: %v", err)
+				http.Error(w, "Could not verify token", http.StatusInternalServerError)
+				return
+			}
+			if revoked {
+				log.Printf("Token %s has been revoked", jti)
+				http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), ContextKey("user"), claims)
+		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-func GenerateToken(username string) (string, error) {
+// GenerateToken issues a short lived access token carrying the user's roles and a
+// unique jti so it can be revoked.
+func GenerateToken(username string, roles []string) (string, error) {
 	secret := os.Getenv("GYM_APP_SECRET_KEY")
 	if secret == "" {
 		return "", fmt.Errorf("invalid_secret")
 	}
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
 	claims := jwt.MapClaims{
 		"username": username,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+		"exp":      time.Now().Add(AccessTokenTTL).Unix(),
 		"iat":      time.Now().Unix(),
 		"iss":      "the-gym-app",
+		"jti":      jti,
+		"roles":    roles,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	//signing the token with the secret
@@ -92,3 +138,22 @@ func GenerateToken(username string) (string, error) {
 	//returning signed token
 	return signedToken, nil
 }
+
+// GenerateRefreshToken returns an opaque random refresh token. It is not a jwt, the
+// server keeps its hash and state in the database.
+func GenerateRefreshToken() (string, error) {
+	return randomToken(32)
+}
+
+// NewTokenFamily returns an id shared by every refresh token rotated from one login.
+func NewTokenFamily() (string, error) {
+	return randomToken(16)
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"the-gym-app/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RequireRole only lets requests through whose token carries one of roles.
+// It reads the claims set by MiddlewareHandler, so it has to be wrapped by it:
+//
+//	MiddlewareHandler(RequireRole(models.RoleAdmin)(handler))
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !models.HasRole(RolesFromRequest(r), roles...) {
+				log.Printf("Access denied to %s: requires one of roles %v", r.URL.Path, roles)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission only lets requests through whose roles grant permission.
+// Like RequireRole it must be wrapped by MiddlewareHandler.
+func RequirePermission(permission models.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !models.HasPermission(RolesFromRequest(r), permission) {
+				log.Printf("Access denied to %s: requires permission %s", r.URL.Path, permission)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RolesFromRequest returns the roles claim of the authenticated user, or nil when the
+// request did not pass through MiddlewareHandler.
+func RolesFromRequest(r *http.Request) []string {
+	claims, ok := r.Context().Value(ContextKey("user")).(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+	values, ok := claims["roles"].([]interface{})
+	if !ok {
+		return nil
+	}
+	roles := make([]string, 0, len(values))
+	for _, value := range values {
+		if role, ok := value.(string); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// LoggedSet is a single set joined with the exercise and workout it was performed in.
+// It is the common input for the analytics built on top of a user's history.
+type LoggedSet struct {
+	SetID      int     `json:"set_id"`
+	WorkoutID  int     `json:"workout_id"`
+	ExerciseID int     `json:"exercise_id"`
+	Exercise   string  `json:"exercise"`
+	CatalogID  int     `json:"catalog_id,omitempty"`
+	Reps       int     `json:"reps"`
+	Weight     float64 `json:"weight"`
+	RPE        float64 `json:"rpe"`
+	SetNumber  int     `json:"set_number"`
+	Type       string  `json:"type"`
+	Completed  bool    `json:"completed"`
+	// DurationSeconds and DistanceMeters are only set for timed, cardio and carry sets.
+	DurationSeconds int       `json:"duration_seconds,omitempty"`
+	DistanceMeters  float64   `json:"distance_meters,omitempty"`
+	Calories        int       `json:"calories,omitempty"`
+	PerformedAt     time.Time `json:"performed_at"`
+}
+
+// Counted is Set.Counted for a set from the history.
+func (s LoggedSet) Counted() bool {
+	return s.Completed && s.Type != SetTypeWarmup
+}
+
+// OneRepMaxEstimate is the best estimated one-rep max of an exercise together with
+// the set it was derived from and the resulting rep-max table.
+type OneRepMaxEstimate struct {
+	ExerciseName       string    `json:"exercise_name"`
+	Formula            string    `json:"formula"`
+	EstimatedOneRepMax float64   `json:"estimated_1rm"`
+	BasedOn            LoggedSet `json:"based_on"`
+	RepMaxes           []RepMax  `json:"rep_maxes"`
+}
+
+// RepMax is one row of a rep-max table. ActualWeight is the heaviest weight
+// logged for at least Reps reps, if any.
+type RepMax struct {
+	Reps            int     `json:"reps"`
+	EstimatedWeight float64 `json:"estimated_weight"`
+	ActualWeight    float64 `json:"actual_weight,omitempty"`
+}
+
+// PersonalRecord is a record broken by a set or session, stored as an event so
+// the full PR history of a user can be replayed.
+type PersonalRecord struct {
+	ID            int       `json:"id"`
+	UserID        int       `json:"user_id"`
+	WorkoutID     int       `json:"workout_id"`
+	SetID         int       `json:"set_id,omitempty"`
+	Exercise      string    `json:"exercise"`
+	RecordType    string    `json:"record_type"`
+	Reps          int       `json:"reps,omitempty"`
+	Weight        float64   `json:"weight,omitempty"`
+	Value         float64   `json:"value"`
+	PreviousValue float64   `json:"previous_value"`
+	AchievedAt    time.Time `json:"achieved_at"`
+}
+
+// CardioVolume is the distance and time of timed and cardio sets bucketed by period.
+type CardioVolume struct {
+	Period              string         `json:"period"`
+	TotalDistanceMeters float64        `json:"total_distance_meters"`
+	Points              []CardioPeriod `json:"points"`
+}
+
+// CardioPeriod sums the sets of one period. The average pace only covers sets that
+// have both a distance and a duration.
+type CardioPeriod struct {
+	PeriodStart         time.Time `json:"period_start"`
+	DistanceMeters      float64   `json:"distance_meters"`
+	DurationSeconds     int       `json:"duration_seconds"`
+	Calories            int       `json:"calories"`
+	Sessions            int       `json:"sessions"`
+	AvgPaceSecondsPerKm float64   `json:"avg_pace_seconds_per_km,omitempty"`
+}
+
+// BestPace is the fastest pace held over at least a standard distance, with the time
+// it projects for exactly that distance.
+type BestPace struct {
+	Distance         string    `json:"distance"`
+	DistanceMeters   float64   `json:"distance_meters"`
+	PaceSecondsPerKm float64   `json:"pace_seconds_per_km"`
+	EstimatedSeconds int       `json:"estimated_seconds"`
+	BasedOn          LoggedSet `json:"based_on"`
+}
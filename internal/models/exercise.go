@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// CatalogExercise is an entry of the exercise catalog. Library entries are shared by
+// every user, custom entries are only visible to the user who created them.
+type CatalogExercise struct {
+	ID               int      `json:"id"`
+	Name             string   `json:"name"`
+	Aliases          []string `json:"aliases"`
+	PrimaryMuscles   []string `json:"primary_muscles"`
+	SecondaryMuscles []string `json:"secondary_muscles"`
+	Equipment        string   `json:"equipment"`
+	MovementPattern  string   `json:"movement_pattern"`
+	Unilateral       bool     `json:"unilateral"`
+	ExerciseType     string   `json:"exercise_type"`
+	Custom           bool     `json:"custom"`
+}
+
+// Exercise types decide which set fields have to be logged: strength sets need reps,
+// timed holds a duration, cardio a distance or duration and carries a distance or
+// duration with an optional weight.
+const (
+	ExerciseTypeStrength = "strength"
+	ExerciseTypeTimed    = "timed"
+	ExerciseTypeCardio   = "cardio"
+	ExerciseTypeCarry    = "carry"
+)
+
+// ExerciseTypes lists every valid exercise type.
+var ExerciseTypes = []string{ExerciseTypeStrength, ExerciseTypeTimed, ExerciseTypeCardio, ExerciseTypeCarry}
+
+// CatalogFilter narrows a catalog listing. Empty fields match everything.
+type CatalogFilter struct {
+	// Query matches part of the name or an alias.
+	Query           string
+	Muscle          string
+	Equipment       string
+	MovementPattern string
+}
+
+// Muscles a catalog exercise can train. Volume per muscle is counted against these names.
+var Muscles = []string{
+	"chest", "front delts", "side delts", "rear delts", "lats", "upper back", "traps",
+	"biceps", "triceps", "forearms", "abs", "obliques", "lower back",
+	"glutes", "quads", "hamstrings", "adductors", "calves",
+}
+
+// Equipment a catalog exercise can use.
+var Equipment = []string{
+	"barbell", "dumbbell", "kettlebell", "machine", "cable", "smith machine",
+	"ez bar", "trap bar", "bodyweight", "band", "cardio machine", "other",
+}
+
+// MovementPatterns a catalog exercise can belong to.
+var MovementPatterns = []string{
+	"squat", "hinge", "lunge", "horizontal push", "vertical push",
+	"horizontal pull", "vertical pull", "carry", "core", "isolation", "cardio",
+}
+
+// NormalizeExerciseName reduces a free text exercise name to the form aliases are
+// matched on: lower case words separated by single spaces, punctuation dropped.
+// "BB Bench-Press " and "bb bench press" normalise to the same value.
+func NormalizeExerciseName(name string) string {
+	name = strings.NewReplacer("'", "", "’", "").Replace(name)
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// ValidateCatalogExercise checks a custom exercise before it is stored.
+func ValidateCatalogExercise(exercise *CatalogExercise) error {
+	if NormalizeExerciseName(exercise.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if exercise.ExerciseType == "" {
+		exercise.ExerciseType = ExerciseTypeStrength
+	}
+	if !contains(ExerciseTypes, exercise.ExerciseType) {
+		return fmt.Errorf("unknown exercise type: %s", exercise.ExerciseType)
+	}
+	if len(exercise.PrimaryMuscles) == 0 {
+		return fmt.Errorf("at least one primary muscle is required")
+	}
+	for _, muscle := range append(append([]string{}, exercise.PrimaryMuscles...), exercise.SecondaryMuscles...) {
+		if !contains(Muscles, muscle) {
+			return fmt.Errorf("unknown muscle: %s", muscle)
+		}
+	}
+	if exercise.Equipment != "" && !contains(Equipment, exercise.Equipment) {
+		return fmt.Errorf("unknown equipment: %s", exercise.Equipment)
+	}
+	if exercise.MovementPattern != "" && !contains(MovementPatterns, exercise.MovementPattern) {
+		return fmt.Errorf("unknown movement pattern: %s", exercise.MovementPattern)
+	}
+	for _, alias := range exercise.Aliases {
+		if NormalizeExerciseName(alias) == "" {
+			return fmt.Errorf("aliases must not be empty")
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExerciseGroup groups consecutive exercises of a workout or template that are
+// performed back to back, such as a superset. Exercises join a group by carrying its
+// Label. RestSeconds is the rest after each round, Rounds how often the group is
+// gone through.
+type ExerciseGroup struct {
+	Label       string `json:"label"`
+	Type        string `json:"type"`
+	Rounds      int    `json:"rounds"`
+	RestSeconds int    `json:"rest_seconds,omitempty"`
+}
+
+// Group types. A superset pairs two exercises, a giant set chains three or more and
+// a circuit goes through two or more exercises for several rounds.
+const (
+	GroupTypeSuperset = "superset"
+	GroupTypeGiantSet = "giant_set"
+	GroupTypeCircuit  = "circuit"
+)
+
+// GroupTypes lists every valid group type.
+var GroupTypes = []string{GroupTypeSuperset, GroupTypeGiantSet, GroupTypeCircuit}
+
+// ValidateGroups checks groups against members, the group label of every exercise in
+// order with "" for exercises outside a group, and normalises them. A group without
+// a type is a superset when it has two exercises and a giant set otherwise, rounds
+// default to 1 and the groups are sorted by their first exercise.
+func ValidateGroups(groups []ExerciseGroup, members []string) error {
+	first := make(map[string]int)
+	last := make(map[string]int)
+	count := make(map[string]int)
+	for i, label := range members {
+		if label == "" {
+			continue
+		}
+		if _, ok := first[label]; !ok {
+			first[label] = i
+		}
+		last[label] = i
+		count[label]++
+	}
+
+	defined := make(map[string]bool)
+	for i := range groups {
+		group := &groups[i]
+		if group.Label == "" {
+			return fmt.Errorf("group %d: label is required", i+1)
+		}
+		if defined[group.Label] {
+			return fmt.Errorf("group %s is defined twice", group.Label)
+		}
+		defined[group.Label] = true
+
+		n := count[group.Label]
+		if n < 2 {
+			return fmt.Errorf("group %s needs at least two exercises", group.Label)
+		}
+		if last[group.Label]-first[group.Label]+1 != n {
+			return fmt.Errorf("the exercises of group %s must follow each other", group.Label)
+		}
+
+		groupType := strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(group.Type)))
+		switch {
+		case groupType == "" && n == 2:
+			groupType = GroupTypeSuperset
+		case groupType == "" || groupType == "giantset":
+			groupType = GroupTypeGiantSet
+		}
+		switch groupType {
+		case GroupTypeSuperset:
+			if n != 2 {
+				return fmt.Errorf("superset %s must have exactly two exercises, has %d", group.Label, n)
+			}
+		case GroupTypeGiantSet:
+			if n < 3 {
+				return fmt.Errorf("giant set %s must have at least three exercises", group.Label)
+			}
+		case GroupTypeCircuit:
+		default:
+			return fmt.Errorf("unknown group type %q, expected one of %s", group.Type, strings.Join(GroupTypes, ", "))
+		}
+		group.Type = groupType
+
+		if group.Rounds < 0 || group.RestSeconds < 0 {
+			return fmt.Errorf("group %s: rounds and rest_seconds must not be negative", group.Label)
+		}
+		if group.Rounds == 0 {
+			group.Rounds = 1
+		}
+	}
+	for label := range first {
+		if !defined[label] {
+			return fmt.Errorf("exercise %d refers to unknown group %s", first[label]+1, label)
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return first[groups[i].Label] < first[groups[j].Label] })
+	return nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Workout history sort keys and directions.
+const (
+	SortByDate = "date"
+	SortByName = "name"
+
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// Page size limits of the workout history.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// WorkoutQuery selects a page of a user's workout history. Every filter is optional:
+// From and To bound the workout date, Exercise keeps workouts containing that
+// exercise (any catalog alias matches) and Name keeps workouts whose name contains
+// it, ignoring case. Cursor is the NextCursor of the previous page.
+type WorkoutQuery struct {
+	From     time.Time
+	To       time.Time
+	Exercise string
+	Name     string
+	Sort     string
+	Order    string
+	Limit    int
+	Cursor   string
+}
+
+// ValidateWorkoutQuery checks a query and fills in the defaults: newest first by
+// date, names A to Z, and DefaultPageSize workouts per page.
+func ValidateWorkoutQuery(query *WorkoutQuery) error {
+	query.Sort = strings.ToLower(strings.TrimSpace(query.Sort))
+	query.Order = strings.ToLower(strings.TrimSpace(query.Order))
+	switch query.Sort {
+	case "":
+		query.Sort = SortByDate
+	case SortByDate, SortByName:
+	default:
+		return fmt.Errorf("sort must be date or name")
+	}
+	switch query.Order {
+	case "":
+		query.Order = SortDescending
+		if query.Sort == SortByName {
+			query.Order = SortAscending
+		}
+	case SortAscending, SortDescending:
+	default:
+		return fmt.Errorf("order must be asc or desc")
+	}
+
+	if query.Limit == 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit < 0 || query.Limit > MaxPageSize {
+		return fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return fmt.Errorf("to must not be before from")
+	}
+	query.Exercise = strings.TrimSpace(query.Exercise)
+	query.Name = strings.TrimSpace(query.Name)
+	return nil
+}
+
+// WorkoutPage is one page of the workout history. NextCursor is empty on the last page.
+type WorkoutPage struct {
+	Workouts   []Workout `json:"workouts"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// WorkoutSummary is a workout without its sets, for history lists. Volume is the
+// weight times reps of the sets that count towards volume.
+type WorkoutSummary struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Exercises []string  `json:"exercises"`
+	Sets      int       `json:"sets"`
+	Volume    float64   `json:"volume"`
+}
+
+// WorkoutSummaryPage is one page of the workout history in summary mode.
+type WorkoutSummaryPage struct {
+	Workouts   []WorkoutSummary `json:"workouts"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
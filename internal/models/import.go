@@ -0,0 +1,34 @@
+package models
+
+// ImportedSession is one workout read from another app's export. Key identifies the
+// session within its source, so importing the same file twice skips it.
+type ImportedSession struct {
+	Key     string  `json:"key"`
+	Workout Workout `json:"workout"`
+}
+
+// ImportReport summarises an import. Sessions counts the sessions in the file, of
+// which Duplicates were imported before, Unresolved were held back because some of
+// their exercises are unmatched and Empty had no exercise left to import.
+type ImportReport struct {
+	Format      string              `json:"format"`
+	DryRun      bool                `json:"dry_run"`
+	Sessions    int                 `json:"sessions"`
+	Imported    int                 `json:"imported"`
+	Duplicates  int                 `json:"duplicates"`
+	Unresolved  int                 `json:"unresolved"`
+	Empty       int                 `json:"empty"`
+	Sets        int                 `json:"sets"`
+	SkippedSets int                 `json:"skipped_sets"`
+	WorkoutIDs  []int               `json:"workout_ids"`
+	Unmatched   []UnmatchedExercise `json:"unmatched"`
+}
+
+// UnmatchedExercise is an exercise name of an import that matched no catalog entry.
+// Sessions using it are not imported; mapping the name to a catalog exercise and
+// importing the file again imports them.
+type UnmatchedExercise struct {
+	Name     string `json:"name"`
+	Sessions int    `json:"sessions"`
+	Sets     int    `json:"sets"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of insights.
+const (
+	// InsightPlateau is an exercise whose estimated 1RM stopped improving.
+	InsightPlateau = "plateau"
+	// InsightRegression is an exercise whose estimated 1RM fell well below its peak.
+	InsightRegression = "regression"
+)
+
+// Kinds of interventions an insight suggests.
+const (
+	InterventionDeload    = "deload"
+	InterventionRepRange  = "rep_range_change"
+	InterventionVariation = "variation_swap"
+)
+
+// Insight is a stall or regression found in a user's estimated 1RM trend of one
+// exercise, measured from the workout of its peak. An insight stays open while later
+// scans still find it; ResolvedAt is set by the first scan that no longer does.
+type Insight struct {
+	ID               int            `json:"id"`
+	UserID           int            `json:"user_id"`
+	Exercise         string         `json:"exercise"`
+	CatalogID        int            `json:"catalog_id,omitempty"`
+	Kind             string         `json:"kind"`
+	PeakWorkoutID    int            `json:"peak_workout_id"`
+	PeakAt           time.Time      `json:"peak_at"`
+	PeakOneRepMax    float64        `json:"peak_estimated_1rm"`
+	CurrentOneRepMax float64        `json:"current_estimated_1rm"`
+	ChangePercent    float64        `json:"change_percent"`
+	SessionsSince    int            `json:"sessions_since_peak"`
+	WeeksSince       int            `json:"weeks_since_peak"`
+	Interventions    []Intervention `json:"interventions"`
+	DetectedAt       time.Time      `json:"detected_at"`
+	ResolvedAt       *time.Time     `json:"resolved_at,omitempty"`
+}
+
+// Intervention is one way out of a plateau or regression.
+type Intervention struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+}
+
+// InsightFilter selects a user's insights. Exercise matches any alias of a catalog
+// exercise; resolved insights are only included when asked for.
+type InsightFilter struct {
+	Exercise        string
+	Kind            string
+	IncludeResolved bool
+}
+
+// ValidateInsightFilter checks the kind of a filter.
+func ValidateInsightFilter(filter InsightFilter) error {
+	if filter.Kind != "" && filter.Kind != InsightPlateau && filter.Kind != InsightRegression {
+		return fmt.Errorf("kind must be %s or %s", InsightPlateau, InsightRegression)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// VolumeLandmark is the weekly range of hard sets a muscle grows on: below MEV, the
+// minimum effective volume, training does little and above MRV, the maximum
+// recoverable volume, it outgrows recovery. Custom marks a user's own value.
+type VolumeLandmark struct {
+	Muscle string  `json:"muscle"`
+	MEV    float64 `json:"mev"`
+	MRV    float64 `json:"mrv"`
+	Custom bool    `json:"custom"`
+}
+
+// MaxWeeklySets bounds the landmarks a user can set.
+const MaxWeeklySets = 60
+
+// ValidateVolumeLandmarks checks landmarks a user sets for their muscles.
+func ValidateVolumeLandmarks(landmarks []VolumeLandmark) error {
+	seen := make(map[string]bool)
+	for _, landmark := range landmarks {
+		if !contains(Muscles, landmark.Muscle) {
+			return fmt.Errorf("unknown muscle %q", landmark.Muscle)
+		}
+		if seen[landmark.Muscle] {
+			return fmt.Errorf("muscle %s is listed twice", landmark.Muscle)
+		}
+		seen[landmark.Muscle] = true
+		if landmark.MEV < 0 || landmark.MRV <= landmark.MEV || landmark.MRV > MaxWeeklySets {
+			return fmt.Errorf("%s: mev and mrv must satisfy 0 <= mev < mrv <= %d", landmark.Muscle, MaxWeeklySets)
+		}
+	}
+	return nil
+}
+
+// Volume statuses of a muscle in a week, against its landmarks.
+const (
+	VolumeBelowMEV   = "below_mev"
+	VolumeProductive = "productive"
+	VolumeAboveMRV   = "above_mrv"
+)
+
+// MuscleVolumeReport is the weekly training load of every muscle between From and
+// To. Sets of exercises that are not linked to the catalog have no muscles and are
+// only counted in UnattributedSets.
+type MuscleVolumeReport struct {
+	From             time.Time    `json:"from"`
+	To               time.Time    `json:"to"`
+	SecondaryCredit  float64      `json:"secondary_credit"`
+	UnattributedSets int          `json:"unattributed_sets"`
+	Weeks            []MuscleWeek `json:"weeks"`
+}
+
+// MuscleWeek is the load of every muscle in the week starting on Monday WeekStart.
+type MuscleWeek struct {
+	WeekStart time.Time    `json:"week_start"`
+	Muscles   []MuscleLoad `json:"muscles"`
+}
+
+// MuscleLoad is the load of one muscle in a week. HardSets credits a set fully to
+// the primary muscles of its exercise and by the report's secondary credit to the
+// secondary ones; Tonnage, weight times reps, is credited the same way.
+type MuscleLoad struct {
+	Muscle   string  `json:"muscle"`
+	HardSets float64 `json:"hard_sets"`
+	Tonnage  float64 `json:"tonnage"`
+	MEV      float64 `json:"mev"`
+	MRV      float64 `json:"mrv"`
+	Status   string  `json:"status"`
+}
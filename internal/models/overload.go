@@ -0,0 +1,72 @@
+package models
+
+import "fmt"
+
+// Progression rules behind a recommendation.
+const (
+	// RuleNoHistory starts an exercise that was never logged at the bottom of its range.
+	RuleNoHistory = "no_history"
+	// RuleRPE adjusts the weight by how hard the top set of the last session felt.
+	RuleRPE = "rpe"
+	// RuleDoubleProgression adds reps up to the top of the range, then weight.
+	RuleDoubleProgression = "double_progression"
+	// RuleDeload drops the weight after two sessions short of the range.
+	RuleDeload = "deload"
+)
+
+// Defaults of an OverloadTarget: the smallest weight jump recommended, in kg, and
+// the effort working sets aim for.
+const (
+	DefaultWeightIncrement = 2.5
+	DefaultTargetRPE       = 8
+)
+
+// OverloadTarget is the rep range, sets and effort an exercise is trained in. A zero
+// range or zero sets are taken from the last session.
+type OverloadTarget struct {
+	Sets      int     `json:"sets"`
+	MinReps   int     `json:"min_reps"`
+	MaxReps   int     `json:"max_reps"`
+	TargetRPE float64 `json:"target_rpe"`
+	Increment float64 `json:"increment"`
+}
+
+// ValidateOverloadTarget checks a target and fills in the default increment and RPE.
+func ValidateOverloadTarget(target *OverloadTarget) error {
+	if target.Sets < 0 || target.MinReps < 0 || target.MaxReps < 0 {
+		return fmt.Errorf("sets and reps must not be negative")
+	}
+	if target.MaxReps == 0 {
+		target.MaxReps = target.MinReps
+	}
+	if target.MaxReps < target.MinReps {
+		return fmt.Errorf("max_reps must not be below min_reps")
+	}
+	if target.TargetRPE == 0 {
+		target.TargetRPE = DefaultTargetRPE
+	}
+	if target.TargetRPE < 1 || target.TargetRPE > 10 {
+		return fmt.Errorf("target_rpe must be between 1 and 10")
+	}
+	if target.Increment == 0 {
+		target.Increment = DefaultWeightIncrement
+	}
+	if target.Increment < 0 {
+		return fmt.Errorf("increment must be greater than 0")
+	}
+	return nil
+}
+
+// OverloadRecommendation is the weight and reps suggested for the working sets of the
+// next session of an exercise. LastTopSet is the heaviest set of the last session.
+type OverloadRecommendation struct {
+	Exercise   string     `json:"exercise"`
+	CatalogID  int        `json:"catalog_id,omitempty"`
+	Rule       string     `json:"rule"`
+	Sets       int        `json:"sets"`
+	Reps       int        `json:"reps"`
+	Weight     float64    `json:"weight"`
+	TargetRPE  float64    `json:"target_rpe,omitempty"`
+	Reason     string     `json:"reason"`
+	LastTopSet *LoggedSet `json:"last_top_set,omitempty"`
+}
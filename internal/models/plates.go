@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Weight units of a plate inventory.
+const (
+	UnitKg = "kg"
+	UnitLb = "lb"
+)
+
+// PlateInventory is the bar and plates a user loads, in Unit. Count is the number of
+// plates of a weight owned in total; each side of the bar gets half of them.
+// UpdatedAt is empty for the default inventory.
+type PlateInventory struct {
+	Unit      string       `json:"unit"`
+	BarWeight float64      `json:"bar_weight"`
+	Plates    []PlateCount `json:"plates"`
+	UpdatedAt *time.Time   `json:"updated_at,omitempty"`
+}
+
+// PlateCount is a number of plates of one weight.
+type PlateCount struct {
+	Weight float64 `json:"weight"`
+	Count  int     `json:"count"`
+}
+
+// DefaultPlateInventory is a typical commercial gym setup in unit, used until a user
+// saves their own.
+func DefaultPlateInventory(unit string) PlateInventory {
+	if unit == UnitLb {
+		return PlateInventory{Unit: UnitLb, BarWeight: 45, Plates: []PlateCount{
+			{Weight: 45, Count: 8}, {Weight: 35, Count: 2}, {Weight: 25, Count: 2}, {Weight: 10, Count: 4}, {Weight: 5, Count: 2}, {Weight: 2.5, Count: 2},
+		}}
+	}
+	return PlateInventory{Unit: UnitKg, BarWeight: 20, Plates: []PlateCount{
+		{Weight: 25, Count: 8}, {Weight: 20, Count: 2}, {Weight: 15, Count: 2}, {Weight: 10, Count: 2}, {Weight: 5, Count: 2}, {Weight: 2.5, Count: 2}, {Weight: 1.25, Count: 2},
+	}}
+}
+
+// ValidatePlateInventory checks an inventory before it is stored.
+func ValidatePlateInventory(inventory *PlateInventory) error {
+	if inventory.Unit != UnitKg && inventory.Unit != UnitLb {
+		return fmt.Errorf("unit must be kg or lb")
+	}
+	if inventory.BarWeight < 0 {
+		return fmt.Errorf("bar_weight must not be negative")
+	}
+	seen := make(map[float64]bool)
+	for _, plate := range inventory.Plates {
+		if plate.Weight <= 0 || plate.Count <= 0 {
+			return fmt.Errorf("plates need a positive weight and count")
+		}
+		if seen[plate.Weight] {
+			return fmt.Errorf("plate %g is listed twice", plate.Weight)
+		}
+		seen[plate.Weight] = true
+	}
+	return nil
+}
+
+// PlateLoad is how to load the bar for Target. PerSide lists the plates of one side,
+// heaviest first. When the plates cannot make Target, Achieved is the heaviest weight
+// below it they can.
+type PlateLoad struct {
+	Unit      string       `json:"unit"`
+	Target    float64      `json:"target"`
+	Achieved  float64      `json:"achieved"`
+	BarWeight float64      `json:"bar_weight"`
+	PerSide   []PlateCount `json:"per_side"`
+	Exact     bool         `json:"exact"`
+}
+
+// WarmupSet is one set of a warm-up ramp, with its plates.
+type WarmupSet struct {
+	Weight  float64      `json:"weight"`
+	Reps    int          `json:"reps"`
+	PerSide []PlateCount `json:"per_side"`
+}
+
+// PlateCalculation is the loading of a working weight and the warm-up sets leading up to it.
+type PlateCalculation struct {
+	PlateLoad
+	Warmups []WarmupSet `json:"warmups"`
+}
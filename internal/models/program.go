@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// ProgramEnrollment is a user running one of the built-in training programs. Cycle,
+// Week and Day point at the next session to be trained and start at 1.
+type ProgramEnrollment struct {
+	ID         int                `json:"id"`
+	UserID     int                `json:"user_id"`
+	ProgramKey string             `json:"program"`
+	Cycle      int                `json:"cycle"`
+	Week       int                `json:"week"`
+	Day        int                `json:"day"`
+	Lifts      []ProgramLiftState `json:"lifts"`
+	StartedAt  time.Time          `json:"started_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// ActiveProgram is a user's current enrollment together with the session to train next.
+type ActiveProgram struct {
+	Enrollment ProgramEnrollment `json:"enrollment"`
+	Next       PrescribedSession `json:"next"`
+}
+
+// ProgramLiftState is the progression state of one lift of a program. Stage is the
+// index of the set scheme used by programs that change schemes on failure, and
+// Failures counts the failed sessions since the last progression or deload.
+type ProgramLiftState struct {
+	Lift        string  `json:"lift"`
+	CatalogID   int     `json:"catalog_id"`
+	Exercise    string  `json:"exercise"`
+	TrainingMax float64 `json:"training_max"`
+	Stage       int     `json:"stage"`
+	Failures    int     `json:"failures"`
+}
+
+// PrescribedSession is the session a program asks for at a position, with weights
+// worked out from the user's training maxes.
+type PrescribedSession struct {
+	Program   string               `json:"program"`
+	Cycle     int                  `json:"cycle"`
+	Week      int                  `json:"week"`
+	Day       int                  `json:"day"`
+	DayName   string               `json:"day_name"`
+	Deload    bool                 `json:"deload"`
+	Exercises []PrescribedExercise `json:"exercises"`
+}
+
+// PrescribedExercise is one lift of a prescribed session.
+type PrescribedExercise struct {
+	Lift      string          `json:"lift"`
+	CatalogID int             `json:"catalog_id"`
+	Exercise  string          `json:"exercise"`
+	Scheme    string          `json:"scheme,omitempty"`
+	Sets      []PrescribedSet `json:"sets"`
+}
+
+// PrescribedSet is a single prescribed set. Percent is of the lift's training max;
+// AMRAP sets ask for as many reps as possible with Reps as the minimum.
+type PrescribedSet struct {
+	SetNumber int     `json:"set_number"`
+	Reps      int     `json:"reps"`
+	Weight    float64 `json:"weight"`
+	Percent   float64 `json:"percent"`
+	AMRAP     bool    `json:"amrap,omitempty"`
+}
+
+// Outcomes of a lift after a program session.
+const (
+	OutcomeProgressed = "progressed"
+	OutcomeHeld       = "held"
+	OutcomeFailed     = "failed"
+	OutcomeNewScheme  = "new_scheme"
+	OutcomeDeloaded   = "deloaded"
+	OutcomeSkipped    = "skipped"
+)
+
+// LiftProgress reports what a completed session did to one lift.
+type LiftProgress struct {
+	Lift                string  `json:"lift"`
+	Exercise            string  `json:"exercise"`
+	Outcome             string  `json:"outcome"`
+	Success             bool    `json:"success"`
+	PreviousTrainingMax float64 `json:"previous_training_max"`
+	TrainingMax         float64 `json:"training_max"`
+	Stage               int     `json:"stage"`
+}
+
+// ProgramSessionResult is returned when a logged workout completes a program session.
+type ProgramSessionResult struct {
+	Completed PrescribedSession `json:"completed"`
+	Progress  []LiftProgress    `json:"progress"`
+	Next      PrescribedSession `json:"next"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "strings"
+
+// Roles stored in the users.roles column as a comma separated list.
+const (
+	RoleMember = "member"
+	RoleCoach  = "coach"
+	RoleAdmin  = "admin"
+)
+
+// DefaultRole is given to every user on signup.
+const DefaultRole = RoleMember
+
+// Permission is a single action a role may be allowed to perform.
+type Permission string
+
+const (
+	PermViewClientWorkouts Permission = "clients:read"
+	PermManageUsers        Permission = "users:manage"
+)
+
+// rolePermissions lists what each role may do beyond logging and reading its own
+// workouts, which every signed in user can.
+var rolePermissions = map[string][]Permission{
+	RoleMember: {},
+	RoleCoach:  {PermViewClientWorkouts},
+	RoleAdmin:  {PermViewClientWorkouts, PermManageUsers},
+}
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// ParseRoles splits the stored roles column. Users created before roles were
+// written get the default role.
+func ParseRoles(value string) []string {
+	var roles []string
+	for _, role := range strings.Split(value, ",") {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return []string{DefaultRole}
+	}
+	return roles
+}
+
+// JoinRoles is the inverse of ParseRoles.
+func JoinRoles(roles []string) string {
+	return strings.Join(roles, ",")
+}
+
+// HasRole reports whether any of roles equals one of wanted.
+func HasRole(roles []string, wanted ...string) bool {
+	for _, role := range roles {
+		for _, w := range wanted {
+			if role == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether any of roles grants permission.
+func HasPermission(roles []string, permission Permission) bool {
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
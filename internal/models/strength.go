@@ -0,0 +1,57 @@
+package models
+
+// Strength standard levels, weakest first.
+const (
+	LevelBeginner     = "beginner"
+	LevelNovice       = "novice"
+	LevelIntermediate = "intermediate"
+	LevelAdvanced     = "advanced"
+	LevelElite        = "elite"
+)
+
+// StrengthLevels lists the levels in order.
+var StrengthLevels = []string{LevelBeginner, LevelNovice, LevelIntermediate, LevelAdvanced, LevelElite}
+
+// The powerlifts strength is scored on.
+const (
+	LiftSquat    = "squat"
+	LiftBench    = "bench"
+	LiftDeadlift = "deadlift"
+)
+
+// StrengthReport scores a lifter's squat, bench and deadlift against their bodyweight.
+// Total and its points are only given once all three lifts were logged.
+type StrengthReport struct {
+	Sex        string         `json:"sex"`
+	Bodyweight float64        `json:"bodyweight"`
+	Lifts      []LiftStrength `json:"lifts"`
+	Total      float64        `json:"total,omitempty"`
+	Wilks      float64        `json:"wilks,omitempty"`
+	DOTS       float64        `json:"dots,omitempty"`
+	IPFGL      float64        `json:"ipf_gl,omitempty"`
+}
+
+// LiftStrength is the estimated one-rep max of one lift, relative to bodyweight and
+// placed on the strength standards of the lifter's sex and bodyweight. IPFGL is only
+// defined for the bench press on its own. NextLevelWeight is the one-rep max that
+// reaches NextLevel.
+type LiftStrength struct {
+	Lift               string             `json:"lift"`
+	Exercise           string             `json:"exercise,omitempty"`
+	EstimatedOneRepMax float64            `json:"estimated_1rm"`
+	BodyweightMultiple float64            `json:"bodyweight_multiple"`
+	Wilks              float64            `json:"wilks"`
+	DOTS               float64            `json:"dots"`
+	IPFGL              float64            `json:"ipf_gl,omitempty"`
+	Level              string             `json:"level,omitempty"`
+	NextLevel          string             `json:"next_level,omitempty"`
+	NextLevelWeight    float64            `json:"next_level_weight,omitempty"`
+	Standards          []StrengthStandard `json:"standards"`
+	BasedOn            *LoggedSet         `json:"based_on,omitempty"`
+}
+
+// StrengthStandard is the one-rep max from which a lifter reaches Level.
+type StrengthStandard struct {
+	Level  string  `json:"level"`
+	Weight float64 `json:"weight"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// WorkoutTemplate is a saved routine such as "Push Day A" that workouts can be started from.
+type WorkoutTemplate struct {
+	ID        int                `json:"id"`
+	UserID    int                `json:"user_id"`
+	Name      string             `json:"name"`
+	Notes     string             `json:"notes,omitempty"`
+	Exercises []TemplateExercise `json:"exercises"`
+	Groups    []ExerciseGroup    `json:"groups,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// TemplateExercise is one planned exercise of a template. Like ExerciseLog it is
+// given by CatalogID or by a catalog name or alias, and joins a group by its label.
+type TemplateExercise struct {
+	ID          int     `json:"id"`
+	CatalogID   int     `json:"catalog_id"`
+	Exercise    string  `json:"exercise"`
+	Group       string  `json:"group,omitempty"`
+	Position    int     `json:"position"`
+	TargetSets  int     `json:"target_sets"`
+	MinReps     int     `json:"min_reps"`
+	MaxReps     int     `json:"max_reps"`
+	TargetRPE   float64 `json:"target_rpe,omitempty"`
+	RestSeconds int     `json:"rest_seconds,omitempty"`
+	Notes       string  `json:"notes,omitempty"`
+}
+
+// WorkoutDraft is a workout pre-filled from a template, ready to be edited and logged
+// through /api/workouts. Plan carries the targets the sets were filled in from.
+type WorkoutDraft struct {
+	TemplateID int                `json:"template_id"`
+	Workout    Workout            `json:"workout"`
+	Plan       []TemplateExercise `json:"plan"`
+}
+
+// ValidateTemplate checks a template before it is stored. A missing MaxReps means a
+// fixed rep target and is set to MinReps.
+func ValidateTemplate(template *WorkoutTemplate) error {
+	if template.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(template.Exercises) == 0 {
+		return fmt.Errorf("at least one exercise is required")
+	}
+	for i := range template.Exercises {
+		exercise := &template.Exercises[i]
+		if exercise.Exercise == "" && exercise.CatalogID == 0 {
+			return fmt.Errorf("exercise %d: exercise or catalog_id is required", i+1)
+		}
+		if exercise.TargetSets <= 0 {
+			return fmt.Errorf("exercise %d: target_sets must be greater than 0", i+1)
+		}
+		if exercise.MinReps <= 0 {
+			return fmt.Errorf("exercise %d: min_reps must be greater than 0", i+1)
+		}
+		if exercise.MaxReps == 0 {
+			exercise.MaxReps = exercise.MinReps
+		}
+		if exercise.MaxReps < exercise.MinReps {
+			return fmt.Errorf("exercise %d: max_reps must not be below min_reps", i+1)
+		}
+		if exercise.TargetRPE != 0 && (exercise.TargetRPE < 1 || exercise.TargetRPE > 10) {
+			return fmt.Errorf("exercise %d: target_rpe must be between 1 and 10", i+1)
+		}
+		if exercise.RestSeconds < 0 {
+			return fmt.Errorf("exercise %d: rest_seconds must not be negative", i+1)
+		}
+	}
+	members := make([]string, len(template.Exercises))
+	for i, exercise := range template.Exercises {
+		members[i] = exercise.Group
+	}
+	return ValidateGroups(template.Groups, members)
+}
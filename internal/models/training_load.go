@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Acute:chronic workload ratio zones.
+const (
+	LoadZoneLow     = "low"
+	LoadZoneOptimal = "optimal"
+	LoadZoneHigh    = "high"
+	LoadZoneDanger  = "danger"
+)
+
+// Kinds of training load warnings.
+const (
+	LoadWarningACWR   = "acwr_spike"
+	LoadWarningWeekly = "weekly_spike"
+)
+
+// TrainingLoad is a user's daily training load between From and To with the
+// workload ratio and fitness/fatigue model derived from it. Loads before From still
+// feed the averages and the model, so the first days are not computed from scratch.
+type TrainingLoad struct {
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Days     []TrainingLoadDay `json:"days"`
+	Warnings []LoadWarning     `json:"warnings"`
+}
+
+// TrainingLoadDay is one day of the series. Load is the RPE-weighted volume lifted
+// that day; Acute and Chronic average it over the last 7 and 28 days and ACWR, their
+// ratio, is 0 until there are 28 days of history with some load. Fitness and Fatigue are the two
+// components of the Banister model and Form, the modelled performance, fitness
+// minus twice the fatigue.
+type TrainingLoadDay struct {
+	Date    time.Time `json:"date"`
+	Load    float64   `json:"load"`
+	Acute   float64   `json:"acute"`
+	Chronic float64   `json:"chronic"`
+	ACWR    float64   `json:"acwr"`
+	Zone    string    `json:"zone,omitempty"`
+	Fitness float64   `json:"fitness"`
+	Fatigue float64   `json:"fatigue"`
+	Form    float64   `json:"form"`
+}
+
+// LoadWarning flags a load spike: the day the workload ratio entered the danger zone,
+// or a week whose load rose too far above the week before. Value is the ratio.
+type LoadWarning struct {
+	Date    time.Time `json:"date"`
+	Kind    string    `json:"kind"`
+	Value   float64   `json:"value"`
+	Message string    `json:"message"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type User struct {
 	ID              int       `json:"id" db:"id"`
@@ -17,9 +20,81 @@ type User struct {
 }
 
 type UserProfile struct {
-	ID           int     `json:"id" db:"id"`
-	Height       int     `json:"height" db:"height"`
-	Weight       int     `json:"weight" db:"weight"`
-	Bodyfat      float64 `json:"bodyfat,omitempty" db:"bodyfat"`
-	TargetWeight int     `json:"target_weight,omitempty" db:"target_weight"`
+	ID           int       `json:"id" db:"id"`
+	UserID       int       `json:"user_id" db:"user_id"`
+	Height       int       `json:"height" db:"height"`
+	Weight       int       `json:"weight" db:"weight"`
+	Bodyfat      float64   `json:"bodyfat,omitempty" db:"bodyfat"`
+	TargetWeight int       `json:"target_weight,omitempty" db:"target_weight"`
+	Sex          string    `json:"sex,omitempty" db:"sex"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Sexes a profile can give. Strength standards and points formulas differ by sex.
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+)
+
+// BodyMeasurement is one entry of a user's body measurement history.
+// Every field besides the date is optional so partial check-ins can be logged.
+type BodyMeasurement struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	MeasuredAt time.Time `json:"measured_at" db:"measured_at"`
+	Weight     *float64  `json:"weight,omitempty" db:"weight"`
+	Bodyfat    *float64  `json:"bodyfat,omitempty" db:"bodyfat"`
+	Neck       *float64  `json:"neck,omitempty" db:"neck"`
+	Chest      *float64  `json:"chest,omitempty" db:"chest"`
+	Waist      *float64  `json:"waist,omitempty" db:"waist"`
+	Hips       *float64  `json:"hips,omitempty" db:"hips"`
+	Arm        *float64  `json:"arm,omitempty" db:"arm"`
+	Thigh      *float64  `json:"thigh,omitempty" db:"thigh"`
+	Calf       *float64  `json:"calf,omitempty" db:"calf"`
+	Notes      string    `json:"notes,omitempty" db:"notes"`
+}
+
+// ValidateMeasurement checks that a measurement has at least one value and that
+// every value given is positive, with bodyfat a percentage below 100.
+func ValidateMeasurement(m *BodyMeasurement) error {
+	given := false
+	for _, field := range []struct {
+		name  string
+		value *float64
+	}{
+		{"weight", m.Weight}, {"bodyfat", m.Bodyfat}, {"neck", m.Neck}, {"chest", m.Chest}, {"waist", m.Waist},
+		{"hips", m.Hips}, {"arm", m.Arm}, {"thigh", m.Thigh}, {"calf", m.Calf},
+	} {
+		if field.value == nil {
+			continue
+		}
+		if *field.value <= 0 {
+			return fmt.Errorf("%s must be positive", field.name)
+		}
+		given = true
+	}
+	if !given {
+		return fmt.Errorf("at least one measurement is required")
+	}
+	if m.Bodyfat != nil && *m.Bodyfat >= 100 {
+		return fmt.Errorf("bodyfat must be a percentage below 100")
+	}
+	return nil
+}
+
+// MeasurementTrend is a measurement metric bucketed by period for charting.
+type MeasurementTrend struct {
+	Metric string             `json:"metric"`
+	Period string             `json:"period"`
+	Change float64            `json:"change"`
+	Points []MeasurementPoint `json:"points"`
+}
+
+// MeasurementPoint aggregates the entries of one period.
+type MeasurementPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	Average     float64   `json:"average"`
+	Min         float64   `json:"min"`
+	Max         float64   `json:"max"`
+	Count       int       `json:"count"`
 }
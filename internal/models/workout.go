@@ -1,31 +1,181 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
 
 type Workout struct {
-	ID        int           `json:"id" db:"id"`
-	Name      string        `json:"name" db:"workout_name"`
-	Exercises []ExerciseLog `json:"exercises" db:"exercises"`
-	CreatedAt time.Time     `json:"created_at" db:"created_at"`
-	UserID    int           `json:"user_id" db:"user_id"`
+	ID        int             `json:"id" db:"id"`
+	Name      string          `json:"name" db:"workout_name"`
+	Exercises []ExerciseLog   `json:"exercises" db:"exercises"`
+	Groups    []ExerciseGroup `json:"groups,omitempty"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UserID    int             `json:"user_id" db:"user_id"`
 }
 
+// ExerciseLog is one exercise performed in a workout. On input either CatalogID or an
+// exercise name or alias from the catalog identifies it; Exercise is then stored as
+// the catalog name. A name the catalog does not know is kept as typed and the
+// exercise is Unlinked. Group is the label of the workout group the exercise belongs to.
 type ExerciseLog struct {
 	ID        int       `json:"id" db:"id"`
 	Exercise  string    `json:"exercise"`
+	CatalogID int       `json:"catalog_id,omitempty" db:"catalog_id"`
+	Unlinked  bool      `json:"unlinked,omitempty"`
+	Group     string    `json:"group,omitempty" db:"group_label"`
 	Sets      []Set     `json:"sets"`
 	WorkoutID int       `json:"workout_id" db:"workout_id"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// Set is one logged set or interval. RIR can be given instead of RPE, in which case
+// RPE is derived from it. A set without a type or completed flag is a completed
+// working set, so clients that only send reps, weight and RPE keep working. Pace is
+// derived from duration and distance; on input it can stand in for the duration.
 type Set struct {
-	ID         int     `json:"id" db:"id"`
-	ExerciseID int     `json:"exercise_id" db:"exercise_id"`
-	Reps       int     `json:"reps"`
-	Weight     float64 `json:"weight"`
-	RPE        float64 `json:"rpe"`
-	SetNumber  int     `json:"set_number" db:"set_number"`
-	// TODO : add remarks field here and make it optional
+	ID         int      `json:"id" db:"id"`
+	ExerciseID int      `json:"exercise_id" db:"exercise_id"`
+	Reps       int      `json:"reps"`
+	Weight     float64  `json:"weight"`
+	RPE        float64  `json:"rpe"`
+	SetNumber  int      `json:"set_number" db:"set_number"`
+	Type       string   `json:"type" db:"set_type"`
+	RIR        *float64 `json:"rir,omitempty" db:"rir"`
+	Tempo      string   `json:"tempo,omitempty" db:"tempo"`
+	Remarks    string   `json:"remarks,omitempty" db:"remarks"`
+	Completed  *bool    `json:"completed,omitempty" db:"completed"`
+
+	DurationSeconds  int     `json:"duration_seconds,omitempty" db:"duration_seconds"`
+	DistanceMeters   float64 `json:"distance_meters,omitempty" db:"distance_meters"`
+	PaceSecondsPerKm float64 `json:"pace_seconds_per_km,omitempty"`
+	AvgHeartRate     int     `json:"avg_heart_rate,omitempty" db:"avg_heart_rate"`
+	MaxHeartRate     int     `json:"max_heart_rate,omitempty" db:"max_heart_rate"`
+	Calories         int     `json:"calories,omitempty" db:"calories"`
+}
+
+// Pace returns the pace in seconds per kilometre, or 0 when either value is missing.
+func Pace(durationSeconds int, distanceMeters float64) float64 {
+	if durationSeconds <= 0 || distanceMeters <= 0 {
+		return 0
+	}
+	return math.Round(float64(durationSeconds)/distanceMeters*1000*10) / 10
+}
+
+// Counted reports whether the set counts towards records, maxes and volume, which
+// leaves out warm-ups and sets that were planned but not done.
+func (s Set) Counted() bool {
+	return (s.Completed == nil || *s.Completed) && s.Type != SetTypeWarmup
+}
+
+// Set types.
+const (
+	SetTypeWarmup    = "warmup"
+	SetTypeWorking   = "working"
+	SetTypeDrop      = "drop"
+	SetTypeFailure   = "failure"
+	SetTypeAMRAP     = "amrap"
+	SetTypeCluster   = "cluster"
+	SetTypeRestPause = "rest_pause"
+)
+
+// SetTypes lists every valid set type.
+var SetTypes = []string{SetTypeWarmup, SetTypeWorking, SetTypeDrop, SetTypeFailure, SetTypeAMRAP, SetTypeCluster, SetTypeRestPause}
+
+var tempoPattern = regexp.MustCompile(`^([0-9]+|[xX])[-:/ ]?([0-9]+|[xX])[-:/ ]?([0-9]+|[xX])[-:/ ]?([0-9]+|[xX])$`)
+
+// ValidateSet checks a set of an exercise of exerciseType before it is stored and
+// normalises it: the type defaults to working and accepts spellings such as "Warm-up",
+// completed defaults to true, tempo is written as "3-1-X-0", a missing RPE is derived
+// from RIR and a missing duration from the pace. An empty exerciseType, used for
+// exercises outside the catalog, accepts any of reps, duration or distance.
+func ValidateSet(set *Set, exerciseType string) error {
+	if set.Reps < 0 || set.Weight < 0 {
+		return fmt.Errorf("reps and weight must not be negative")
+	}
+	if set.RPE != 0 && (set.RPE < 1 || set.RPE > 10) {
+		return fmt.Errorf("rpe must be between 1 and 10")
+	}
+	if set.RIR != nil && (*set.RIR < 0 || *set.RIR > 10) {
+		return fmt.Errorf("rir must be between 0 and 10")
+	}
+	if set.DurationSeconds < 0 || set.DistanceMeters < 0 || set.PaceSecondsPerKm < 0 || set.Calories < 0 {
+		return fmt.Errorf("duration, distance, pace and calories must not be negative")
+	}
+	for _, heartRate := range []int{set.AvgHeartRate, set.MaxHeartRate} {
+		if heartRate != 0 && (heartRate < 30 || heartRate > 250) {
+			return fmt.Errorf("heart rate must be between 30 and 250 bpm")
+		}
+	}
+	if set.AvgHeartRate != 0 && set.MaxHeartRate != 0 && set.MaxHeartRate < set.AvgHeartRate {
+		return fmt.Errorf("max_heart_rate must not be below avg_heart_rate")
+	}
+
+	setType := strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(set.Type)))
+	switch setType {
+	case "":
+		setType = SetTypeWorking
+	case "warm_up":
+		setType = SetTypeWarmup
+	case "restpause":
+		setType = SetTypeRestPause
+	}
+	if !contains(SetTypes, setType) {
+		return fmt.Errorf("unknown set type %q, expected one of %s", set.Type, strings.Join(SetTypes, ", "))
+	}
+	set.Type = setType
+
+	if tempo := strings.TrimSpace(set.Tempo); tempo != "" {
+		parts := tempoPattern.FindStringSubmatch(tempo)
+		if parts == nil {
+			return fmt.Errorf("tempo must have four phases such as 3-1-X-0, got %q", set.Tempo)
+		}
+		set.Tempo = strings.ToUpper(strings.Join(parts[1:], "-"))
+	} else {
+		set.Tempo = ""
+	}
+
+	if set.Completed == nil {
+		completed := true
+		set.Completed = &completed
+	}
+	if set.RPE == 0 && set.RIR != nil {
+		set.RPE = 10 - *set.RIR
+		if set.RPE < 1 {
+			set.RPE = 1
+		}
+	}
+	if set.DurationSeconds == 0 && set.PaceSecondsPerKm > 0 && set.DistanceMeters > 0 {
+		set.DurationSeconds = int(math.Round(set.PaceSecondsPerKm * set.DistanceMeters / 1000))
+	}
+	set.PaceSecondsPerKm = Pace(set.DurationSeconds, set.DistanceMeters)
+
+	//planned sets that were not done yet may still be empty
+	if !*set.Completed {
+		return nil
+	}
+	switch exerciseType {
+	case ExerciseTypeTimed:
+		if set.DurationSeconds == 0 {
+			return fmt.Errorf("duration_seconds is required for timed exercises")
+		}
+	case ExerciseTypeCardio, ExerciseTypeCarry:
+		if set.DurationSeconds == 0 && set.DistanceMeters == 0 {
+			return fmt.Errorf("duration_seconds or distance_meters is required for %s exercises", exerciseType)
+		}
+	case ExerciseTypeStrength:
+		if set.Reps == 0 {
+			return fmt.Errorf("reps must be greater than 0")
+		}
+	default:
+		if set.Reps == 0 && set.DurationSeconds == 0 && set.DistanceMeters == 0 {
+			return fmt.Errorf("reps, duration_seconds or distance_meters is required")
+		}
+	}
+	return nil
 }
 
 type SetRep struct {
@@ -33,3 +183,32 @@ type SetRep struct {
 	Reps         int     `json:"reps"`
 	Weight       float64 `json:"weight"`
 }
+
+// WorkoutPatch holds the optional fields accepted when partially updating a workout.
+type WorkoutPatch struct {
+	Name *string `json:"name"`
+}
+
+// ExercisePatch holds the optional fields accepted when partially updating an exercise.
+// Exercise and CatalogID both pick the exercise, so at most one of them may be sent.
+type ExercisePatch struct {
+	Exercise  *string `json:"exercise"`
+	CatalogID *int    `json:"catalog_id"`
+}
+
+// SetPatch holds the optional fields accepted when partially updating a set.
+type SetPatch struct {
+	Reps            *int     `json:"reps"`
+	Weight          *float64 `json:"weight"`
+	RPE             *float64 `json:"rpe"`
+	Type            *string  `json:"type"`
+	RIR             *float64 `json:"rir"`
+	Tempo           *string  `json:"tempo"`
+	Remarks         *string  `json:"remarks"`
+	Completed       *bool    `json:"completed"`
+	DurationSeconds *int     `json:"duration_seconds"`
+	DistanceMeters  *float64 `json:"distance_meters"`
+	AvgHeartRate    *int     `json:"avg_heart_rate"`
+	MaxHeartRate    *int     `json:"max_heart_rate"`
+	Calories        *int     `json:"calories"`
+}
@@ -0,0 +1,96 @@
+// Package plates works out which plates to load on a bar for a weight and builds
+// warm-up ramps up to a working weight.
+package plates
+
+import (
+	"math"
+	"sort"
+	"the-gym-app/internal/models"
+)
+
+// scale turns weights into whole hundredths, so plates like 1.25 add up exactly.
+const scale = 100
+
+// Load finds the plates of inventory that load the bar closest to target without
+// going over, using as few plates as possible.
+func Load(target float64, inventory models.PlateInventory) models.PlateLoad {
+	load := models.PlateLoad{Unit: inventory.Unit, Target: target, BarWeight: inventory.BarWeight, PerSide: []models.PlateCount{}}
+	side := int(math.Floor((target-inventory.BarWeight)/2*scale + 1e-6))
+	if side <= 0 {
+		load.Achieved = inventory.BarWeight
+		load.Exact = side == 0
+		return load
+	}
+
+	plates := append([]models.PlateCount{}, inventory.Plates...)
+	sort.Slice(plates, func(i, j int) bool { return plates[i].Weight > plates[j].Weight })
+
+	//fewest plates making every side weight up to the target, one plate weight at a
+	//time; chosen[t][w] is how many plates of weight t a side of weight w uses
+	const unreachable = math.MaxInt32
+	fewest := make([]int, side+1)
+	for w := 1; w <= side; w++ {
+		fewest[w] = unreachable
+	}
+	chosen := make([][]int, len(plates))
+	for t, plate := range plates {
+		weight, perSide := int(math.Round(plate.Weight*scale)), plate.Count/2
+		next := make([]int, side+1)
+		chosen[t] = make([]int, side+1)
+		for w := 0; w <= side; w++ {
+			next[w] = fewest[w]
+			for n := 1; n <= perSide && n*weight <= w; n++ {
+				if fewest[w-n*weight] != unreachable && fewest[w-n*weight]+n < next[w] {
+					next[w] = fewest[w-n*weight] + n
+					chosen[t][w] = n
+				}
+			}
+		}
+		fewest = next
+	}
+
+	best := side
+	for best > 0 && fewest[best] == unreachable {
+		best--
+	}
+	w := best
+	for t := len(plates) - 1; t >= 0; t-- {
+		if n := chosen[t][w]; n > 0 {
+			load.PerSide = append([]models.PlateCount{{Weight: plates[t].Weight, Count: n}}, load.PerSide...)
+			w -= n * int(math.Round(plates[t].Weight*scale))
+		}
+	}
+	load.Achieved = inventory.BarWeight + 2*float64(best)/scale
+	load.Exact = best == side && math.Abs(load.Achieved-target) < 1e-6
+	return load
+}
+
+// warmupSteps is the ramp up to a working weight: an empty bar, then growing shares
+// of the working weight for fewer reps. The last step is only done before sets of
+// heavyReps reps or fewer.
+var warmupSteps = []struct {
+	percent float64
+	reps    int
+}{{0, 10}, {40, 5}, {60, 3}, {80, 2}, {90, 1}}
+
+const heavyReps = 3
+
+// Warmups builds the warm-up sets for working sets of workReps reps at target. Every
+// set is rounded down to what the plates can load, and sets that would repeat the
+// previous weight or reach the working weight are left out.
+func Warmups(target float64, workReps int, inventory models.PlateInventory) []models.WarmupSet {
+	warmups := []models.WarmupSet{}
+	previous := -1.0
+	for i, step := range warmupSteps {
+		if i == len(warmupSteps)-1 && workReps > heavyReps {
+			break
+		}
+		load := Load(target*step.percent/100, inventory)
+		if load.Achieved <= previous || load.Achieved >= target {
+			continue
+		}
+		warmups = append(warmups, models.WarmupSet{Weight: load.Achieved, Reps: step.reps, PerSide: load.PerSide})
+		previous = load.Achieved
+	}
+	return warmups
+}
@@ -0,0 +1,87 @@
+package plates
+
+import (
+	"reflect"
+	"testing"
+	"the-gym-app/internal/models"
+)
+
+func TestLoad(t *testing.T) {
+	kg := models.DefaultPlateInventory(models.UnitKg)
+	for _, test := range []struct {
+		name      string
+		target    float64
+		inventory models.PlateInventory
+		achieved  float64
+		exact     bool
+		perSide   []models.PlateCount
+	}{
+		{"142.5 kg", 142.5, kg, 142.5, true, []models.PlateCount{{Weight: 25, Count: 1}, {Weight: 20, Count: 1}, {Weight: 15, Count: 1}, {Weight: 1.25, Count: 1}}},
+		{"the empty bar", 20, kg, 20, true, []models.PlateCount{}},
+		{"below the bar", 15, kg, 20, false, []models.PlateCount{}},
+		{"less than the smallest pair", 21, kg, 20, false, []models.PlateCount{}},
+		{"unreachable target rounds down", 221.25, kg, 220, false, []models.PlateCount{{Weight: 25, Count: 4}}},
+		{"more than the inventory holds", 500, kg, 327.5, false, []models.PlateCount{
+			{Weight: 25, Count: 4}, {Weight: 20, Count: 1}, {Weight: 15, Count: 1}, {Weight: 10, Count: 1}, {Weight: 5, Count: 1}, {Weight: 2.5, Count: 1}, {Weight: 1.25, Count: 1},
+		}},
+		//taking the heaviest plate first would leave 5 kg a side that cannot be loaded
+		{"fewest plates beats the heaviest first", 80, models.PlateInventory{Unit: models.UnitKg, BarWeight: 20, Plates: []models.PlateCount{
+			{Weight: 25, Count: 2}, {Weight: 10, Count: 2}, {Weight: 15, Count: 4},
+		}}, 80, true, []models.PlateCount{{Weight: 15, Count: 2}}},
+		//a plate without a partner cannot be loaded
+		{"odd plate counts", 100, models.PlateInventory{Unit: models.UnitKg, BarWeight: 20, Plates: []models.PlateCount{
+			{Weight: 20, Count: 3}, {Weight: 10, Count: 3},
+		}}, 80, false, []models.PlateCount{{Weight: 20, Count: 1}, {Weight: 10, Count: 1}}},
+		{"no plates", 60, models.PlateInventory{Unit: models.UnitLb, BarWeight: 45}, 45, false, []models.PlateCount{}},
+	} {
+		load := Load(test.target, test.inventory)
+		if load.Achieved != test.achieved || load.Exact != test.exact || load.Target != test.target || load.BarWeight != test.inventory.BarWeight {
+			t.Errorf("%s: Load(%v) = %+v, want %v exact %v", test.name, test.target, load, test.achieved, test.exact)
+		}
+		if !reflect.DeepEqual(load.PerSide, test.perSide) {
+			t.Errorf("%s: per side = %+v, want %+v", test.name, load.PerSide, test.perSide)
+		}
+	}
+}
+
+func TestWarmups(t *testing.T) {
+	kg, lb := models.DefaultPlateInventory(models.UnitKg), models.DefaultPlateInventory(models.UnitLb)
+	for _, test := range []struct {
+		name      string
+		target    float64
+		reps      int
+		inventory models.PlateInventory
+		want      []models.WarmupSet
+	}{
+		//above heavyReps the 90% single is left out
+		{"142.5 kg for 5", 142.5, 5, kg, []models.WarmupSet{
+			{Weight: 20, Reps: 10}, {Weight: 55, Reps: 5}, {Weight: 85, Reps: 3}, {Weight: 112.5, Reps: 2},
+		}},
+		{"142.5 kg for 3", 142.5, 3, kg, []models.WarmupSet{
+			{Weight: 20, Reps: 10}, {Weight: 55, Reps: 5}, {Weight: 85, Reps: 3}, {Weight: 112.5, Reps: 2}, {Weight: 127.5, Reps: 1},
+		}},
+		{"315 lb single", 315, 1, lb, []models.WarmupSet{
+			{Weight: 45, Reps: 10}, {Weight: 125, Reps: 5}, {Weight: 185, Reps: 3}, {Weight: 250, Reps: 2}, {Weight: 280, Reps: 1},
+		}},
+		//40% rounds down to the bar, which is only done once
+		{"light work", 40, 5, kg, []models.WarmupSet{{Weight: 20, Reps: 10}, {Weight: 22.5, Reps: 3}, {Weight: 30, Reps: 2}}},
+		{"the empty bar", 20, 5, kg, []models.WarmupSet{}},
+	} {
+		warmups := Warmups(test.target, test.reps, test.inventory)
+		if len(warmups) != len(test.want) {
+			t.Errorf("%s: warm-ups = %+v, want %+v", test.name, warmups, test.want)
+			continue
+		}
+		for i, warmup := range warmups {
+			if warmup.Weight != test.want[i].Weight || warmup.Reps != test.want[i].Reps {
+				t.Errorf("%s: warm-up %d = %v x %d, want %v x %d", test.name, i, warmup.Weight, warmup.Reps, test.want[i].Weight, test.want[i].Reps)
+			}
+		}
+	}
+
+	//every warm-up comes with the plates that load it
+	warmups := Warmups(142.5, 5, kg)
+	if want := []models.PlateCount{{Weight: 15, Count: 1}, {Weight: 2.5, Count: 1}}; !reflect.DeepEqual(warmups[1].PerSide, want) {
+		t.Errorf("plates of 55 kg = %+v, want %+v", warmups[1].PerSide, want)
+	}
+}
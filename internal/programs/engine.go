@@ -0,0 +1,262 @@
+package programs
+
+import (
+	"sort"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+)
+
+// Prescribe returns the session the enrollment is at, with weights worked out from
+// its training maxes.
+func (p *Program) Prescribe(enrollment *models.ProgramEnrollment) models.PrescribedSession {
+	p.clampPosition(enrollment)
+	week := p.Weeks[enrollment.Week-1]
+	day := week.Days[enrollment.Day-1]
+	session := models.PrescribedSession{
+		Program:   p.Key,
+		Cycle:     enrollment.Cycle,
+		Week:      enrollment.Week,
+		Day:       enrollment.Day,
+		DayName:   day.Name,
+		Deload:    week.Deload,
+		Exercises: []models.PrescribedExercise{},
+	}
+	for _, slot := range day.Slots {
+		lift, _ := p.Lift(slot.Lift)
+		state := liftState(enrollment, slot.Lift)
+		if state == nil {
+			continue
+		}
+		scheme, sets := p.slotSets(lift, slot, state)
+		exercise := models.PrescribedExercise{
+			Lift:      lift.Key,
+			CatalogID: state.CatalogID,
+			Exercise:  state.Exercise,
+			Scheme:    scheme,
+			Sets:      make([]models.PrescribedSet, 0, len(sets)),
+		}
+		for i, set := range sets {
+			exercise.Sets = append(exercise.Sets, models.PrescribedSet{
+				SetNumber: i + 1,
+				Reps:      set.Reps,
+				Weight:    analytics.Round(state.TrainingMax*set.Percent/100, p.Rounding),
+				Percent:   set.Percent,
+				AMRAP:     set.AMRAP,
+			})
+		}
+		session.Exercises = append(session.Exercises, exercise)
+	}
+	return session
+}
+
+// Complete applies a trained session to the enrollment: every lift of the session is
+// judged against what was performed, keyed by catalog id, its progression rule is
+// applied and the enrollment moves on to the next session. A lift with no performed
+// sets is skipped and left unchanged.
+func (p *Program) Complete(enrollment *models.ProgramEnrollment, performed map[int][]models.Set) []models.LiftProgress {
+	session := p.Prescribe(enrollment)
+	progress := make([]models.LiftProgress, 0, len(session.Exercises))
+	for _, exercise := range session.Exercises {
+		lift, _ := p.Lift(exercise.Lift)
+		state := liftState(enrollment, exercise.Lift)
+		result := models.LiftProgress{
+			Lift:                lift.Key,
+			Exercise:            state.Exercise,
+			PreviousTrainingMax: state.TrainingMax,
+		}
+
+		sets, ok := performed[state.CatalogID]
+		switch {
+		case !ok || len(sets) == 0:
+			result.Outcome = models.OutcomeSkipped
+		case session.Deload:
+			//deload weeks are for recovery, so they neither count as a failure nor progress
+			result.Success = setsMeetPrescription(exercise.Sets, sets)
+			result.Outcome = models.OutcomeHeld
+		default:
+			result.Success = setsMeetPrescription(exercise.Sets, sets)
+			result.Outcome = p.progress(lift, state, result.Success)
+		}
+
+		result.TrainingMax = state.TrainingMax
+		result.Stage = state.Stage
+		progress = append(progress, result)
+	}
+
+	if p.advance(enrollment) {
+		progress = p.endCycle(enrollment, progress)
+	}
+	return progress
+}
+
+// slotSets returns the scheme name and the sets a slot prescribes for the lift's
+// current state.
+func (p *Program) slotSets(lift Lift, slot Slot, state *models.ProgramLiftState) (string, []SetPrescription) {
+	if lift.Progression.Rule != RuleStages {
+		return "", slot.Sets
+	}
+	stage := lift.Progression.Stages[0]
+	if state.Stage >= 0 && state.Stage < len(lift.Progression.Stages) {
+		stage = lift.Progression.Stages[state.Stage]
+	}
+	return stage.Name, stage.Sets
+}
+
+// progress applies the per session part of the lift's rule and returns the outcome.
+func (p *Program) progress(lift Lift, state *models.ProgramLiftState, success bool) string {
+	rule := lift.Progression
+	if rule.Rule == RuleCycle {
+		//cycle lifts are only judged at the end of the cycle
+		if success {
+			return models.OutcomeHeld
+		}
+		state.Failures++
+		return models.OutcomeFailed
+	}
+
+	if success {
+		state.TrainingMax += rule.Increment
+		state.Failures = 0
+		return models.OutcomeProgressed
+	}
+	state.Failures++
+	if state.Failures < rule.FailureLimit {
+		return models.OutcomeFailed
+	}
+	state.Failures = 0
+	if rule.Rule == RuleStages && state.Stage+1 < len(rule.Stages) {
+		state.Stage++
+		return models.OutcomeNewScheme
+	}
+	state.Stage = 0
+	state.TrainingMax = p.deload(state.TrainingMax, rule.DeloadPercent)
+	return models.OutcomeDeloaded
+}
+
+// endCycle applies RuleCycle at the end of a cycle and reports the result on the
+// progress of the session that finished it.
+func (p *Program) endCycle(enrollment *models.ProgramEnrollment, progress []models.LiftProgress) []models.LiftProgress {
+	for _, lift := range p.Lifts {
+		if lift.Progression.Rule != RuleCycle {
+			continue
+		}
+		state := liftState(enrollment, lift.Key)
+		if state == nil {
+			continue
+		}
+
+		previous := state.TrainingMax
+		var outcome string
+		switch {
+		case state.Failures == 0:
+			state.TrainingMax += lift.Progression.Increment
+			outcome = models.OutcomeProgressed
+		case state.Failures >= lift.Progression.FailureLimit:
+			state.TrainingMax = p.deload(state.TrainingMax, lift.Progression.DeloadPercent)
+			outcome = models.OutcomeDeloaded
+		default:
+			outcome = models.OutcomeHeld
+		}
+		state.Failures = 0
+
+		found := false
+		for i := range progress {
+			if progress[i].Lift == lift.Key {
+				progress[i].Outcome = outcome
+				progress[i].TrainingMax = state.TrainingMax
+				found = true
+			}
+		}
+		if !found {
+			progress = append(progress, models.LiftProgress{
+				Lift:                lift.Key,
+				Exercise:            state.Exercise,
+				Outcome:             outcome,
+				Success:             outcome == models.OutcomeProgressed,
+				PreviousTrainingMax: previous,
+				TrainingMax:         state.TrainingMax,
+				Stage:               state.Stage,
+			})
+		}
+	}
+	return progress
+}
+
+// advance moves the enrollment to the next session and reports whether that started
+// a new cycle.
+func (p *Program) advance(enrollment *models.ProgramEnrollment) bool {
+	enrollment.Day++
+	if enrollment.Day <= len(p.Weeks[enrollment.Week-1].Days) {
+		return false
+	}
+	enrollment.Day = 1
+	enrollment.Week++
+	if enrollment.Week <= len(p.Weeks) {
+		return false
+	}
+	enrollment.Week = 1
+	enrollment.Cycle++
+	return true
+}
+
+// clampPosition restarts a week or cycle whose position no longer exists, which can
+// only happen when a built-in definition was shortened after users enrolled.
+func (p *Program) clampPosition(enrollment *models.ProgramEnrollment) {
+	if enrollment.Cycle < 1 {
+		enrollment.Cycle = 1
+	}
+	if enrollment.Week < 1 || enrollment.Week > len(p.Weeks) {
+		enrollment.Week = 1
+	}
+	if enrollment.Day < 1 || enrollment.Day > len(p.Weeks[enrollment.Week-1].Days) {
+		enrollment.Day = 1
+	}
+}
+
+func (p *Program) deload(trainingMax, percent float64) float64 {
+	return analytics.Round(trainingMax*(100-percent)/100, p.Rounding)
+}
+
+// setsMeetPrescription reports whether every prescribed set was matched by its own
+// performed set with at least the prescribed weight and reps. Performed sets are
+// matched heaviest prescription first and in any order, so extra back-off sets do
+// not fail a session; warm-ups and sets that were not completed are ignored.
+func setsMeetPrescription(prescribed []models.PrescribedSet, all []models.Set) bool {
+	var performed []models.Set
+	for _, set := range all {
+		if set.Counted() {
+			performed = append(performed, set)
+		}
+	}
+	if len(performed) < len(prescribed) {
+		return false
+	}
+	ordered := append([]models.PrescribedSet(nil), prescribed...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Weight > ordered[j].Weight })
+
+	used := make([]bool, len(performed))
+	for _, set := range ordered {
+		matched := false
+		for i, done := range performed {
+			//allow for the rounding of loads entered in other units
+			if !used[i] && done.Weight >= set.Weight-0.5 && done.Reps >= set.Reps {
+				used[i] = true
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func liftState(enrollment *models.ProgramEnrollment, key string) *models.ProgramLiftState {
+	for i := range enrollment.Lifts {
+		if enrollment.Lifts[i].Lift == key {
+			return &enrollment.Lifts[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package programs
+
+import (
+	"testing"
+	"the-gym-app/internal/models"
+)
+
+// enroll starts key at its first session with a training max of 100 for every lift.
+func enroll(t *testing.T, key string) (*Program, *models.ProgramEnrollment) {
+	t.Helper()
+	program, ok := Lookup(key)
+	if !ok {
+		t.Fatalf("no program %q", key)
+	}
+	enrollment := &models.ProgramEnrollment{ProgramKey: key, Cycle: 1, Week: 1, Day: 1}
+	for i, lift := range program.Lifts {
+		enrollment.Lifts = append(enrollment.Lifts, models.ProgramLiftState{Lift: lift.Key, CatalogID: i + 1, Exercise: lift.Exercise, TrainingMax: 100})
+	}
+	return program, enrollment
+}
+
+// train performs the enrollment's next session as prescribed, except that the last
+// set of every failed lift falls one rep short.
+func train(program *Program, enrollment *models.ProgramEnrollment, failed []string) []models.LiftProgress {
+	performed := map[int][]models.Set{}
+	for _, exercise := range program.Prescribe(enrollment).Exercises {
+		var sets []models.Set
+		for _, set := range exercise.Sets {
+			sets = append(sets, models.Set{SetNumber: set.SetNumber, Reps: set.Reps, Weight: set.Weight})
+		}
+		for _, lift := range failed {
+			if lift == exercise.Lift {
+				sets[len(sets)-1].Reps--
+			}
+		}
+		performed[exercise.CatalogID] = sets
+	}
+	return program.Complete(enrollment, performed)
+}
+
+// step trains a number of sessions and checks the progress of the last one.
+type step struct {
+	sessions int
+	failed   []string
+	want     []models.LiftProgress
+}
+
+func runSteps(t *testing.T, key string, steps []step) {
+	program, enrollment := enroll(t, key)
+	for i, step := range steps {
+		var progress []models.LiftProgress
+		for session := 0; session < step.sessions; session++ {
+			progress = train(program, enrollment, step.failed)
+		}
+		for _, want := range step.want {
+			found := false
+			for _, got := range progress {
+				if got.Lift != want.Lift {
+					continue
+				}
+				found = true
+				if got.Outcome != want.Outcome || got.TrainingMax != want.TrainingMax || got.Stage != want.Stage {
+					t.Errorf("%s step %d: %s = %s at %v stage %d, want %s at %v stage %d", key, i+1, want.Lift, got.Outcome, got.TrainingMax, got.Stage, want.Outcome, want.TrainingMax, want.Stage)
+				}
+			}
+			if !found {
+				t.Errorf("%s step %d: no progress for %s in %+v", key, i+1, want.Lift, progress)
+			}
+		}
+	}
+}
+
+func TestLinearProgression(t *testing.T) {
+	runSteps(t, "linear", []step{
+		{1, nil, []models.LiftProgress{
+			{Lift: "squat", Outcome: models.OutcomeProgressed, TrainingMax: 102.5},
+			{Lift: "deadlift", Outcome: models.OutcomeProgressed, TrainingMax: 105},
+		}},
+		{1, []string{"squat"}, []models.LiftProgress{
+			{Lift: "squat", Outcome: models.OutcomeFailed, TrainingMax: 102.5},
+			{Lift: "press", Outcome: models.OutcomeProgressed, TrainingMax: 102.5},
+		}},
+		{1, []string{"squat"}, []models.LiftProgress{{Lift: "squat", Outcome: models.OutcomeFailed, TrainingMax: 102.5}}},
+		//the third failure in a row takes 10% off
+		{1, []string{"squat"}, []models.LiftProgress{{Lift: "squat", Outcome: models.OutcomeDeloaded, TrainingMax: 92.5}}},
+		{1, nil, []models.LiftProgress{{Lift: "squat", Outcome: models.OutcomeProgressed, TrainingMax: 95}}},
+	})
+}
+
+func TestWendlerProgression(t *testing.T) {
+	runSteps(t, "531", []step{
+		//training maxes only move at the end of the cycle
+		{1, nil, []models.LiftProgress{{Lift: "press", Outcome: models.OutcomeHeld, TrainingMax: 100}}},
+		{10, nil, []models.LiftProgress{{Lift: "bench", Outcome: models.OutcomeHeld, TrainingMax: 100}}},
+		{1, []string{"squat"}, []models.LiftProgress{{Lift: "squat", Outcome: models.OutcomeFailed, TrainingMax: 100}}},
+		//falling short in the deload week does not count
+		{3, []string{"press", "deadlift", "bench"}, []models.LiftProgress{{Lift: "bench", Outcome: models.OutcomeHeld, TrainingMax: 100}}},
+		{1, nil, []models.LiftProgress{
+			{Lift: "squat", Outcome: models.OutcomeDeloaded, TrainingMax: 90},
+			{Lift: "press", Outcome: models.OutcomeProgressed, TrainingMax: 102.5},
+			{Lift: "deadlift", Outcome: models.OutcomeProgressed, TrainingMax: 105},
+			{Lift: "bench", Outcome: models.OutcomeProgressed, TrainingMax: 102.5},
+		}},
+		{1, nil, []models.LiftProgress{{Lift: "press", Outcome: models.OutcomeHeld, TrainingMax: 102.5}}},
+	})
+}
+
+func TestGZCLPProgression(t *testing.T) {
+	//squat_t1 is trained on the first of every four sessions
+	runSteps(t, "gzclp", []step{
+		{1, []string{"bench_t2"}, []models.LiftProgress{
+			{Lift: "squat_t1", Outcome: models.OutcomeProgressed, TrainingMax: 105},
+			{Lift: "bench_t2", Outcome: models.OutcomeNewScheme, TrainingMax: 100, Stage: 1},
+			{Lift: "pulldown_t3", Outcome: models.OutcomeProgressed, TrainingMax: 102.5},
+		}},
+		{4, []string{"squat_t1"}, []models.LiftProgress{{Lift: "squat_t1", Outcome: models.OutcomeNewScheme, TrainingMax: 105, Stage: 1}}},
+		{4, []string{"squat_t1"}, []models.LiftProgress{{Lift: "squat_t1", Outcome: models.OutcomeNewScheme, TrainingMax: 105, Stage: 2}}},
+		//failing the last stage resets to 5x3+ 15% lighter
+		{4, []string{"squat_t1"}, []models.LiftProgress{{Lift: "squat_t1", Outcome: models.OutcomeDeloaded, TrainingMax: 90}}},
+		{4, nil, []models.LiftProgress{{Lift: "squat_t1", Outcome: models.OutcomeProgressed, TrainingMax: 95}}},
+	})
+}
+
+func TestDUPProgression(t *testing.T) {
+	runSteps(t, "dup", []step{
+		{2, nil, []models.LiftProgress{{Lift: "squat", Outcome: models.OutcomeHeld, TrainingMax: 100}}},
+		{1, nil, []models.LiftProgress{
+			{Lift: "squat", Outcome: models.OutcomeProgressed, TrainingMax: 102.5},
+			{Lift: "bench", Outcome: models.OutcomeProgressed, TrainingMax: 102.5},
+			{Lift: "deadlift", Outcome: models.OutcomeProgressed, TrainingMax: 105},
+		}},
+		{1, []string{"squat", "deadlift"}, []models.LiftProgress{{Lift: "squat", Outcome: models.OutcomeFailed, TrainingMax: 102.5}}},
+		{1, []string{"squat"}, []models.LiftProgress{{Lift: "squat", Outcome: models.OutcomeFailed, TrainingMax: 102.5}}},
+		//two failed sessions in a week deload, one holds
+		{1, nil, []models.LiftProgress{
+			{Lift: "squat", Outcome: models.OutcomeDeloaded, TrainingMax: 92.5},
+			{Lift: "bench", Outcome: models.OutcomeProgressed, TrainingMax: 105},
+			{Lift: "deadlift", Outcome: models.OutcomeHeld, TrainingMax: 105},
+		}},
+	})
+}
+
+func TestPrescribe(t *testing.T) {
+	program, enrollment := enroll(t, "531")
+	session := program.Prescribe(enrollment)
+	if session.DayName != "Press" || session.Deload || len(session.Exercises) != 1 {
+		t.Fatalf("first 5/3/1 session: %+v", session)
+	}
+	sets := session.Exercises[0].Sets
+	for i, want := range []models.PrescribedSet{{SetNumber: 1, Reps: 5, Weight: 65, Percent: 65}, {SetNumber: 2, Reps: 5, Weight: 75, Percent: 75}, {SetNumber: 3, Reps: 5, Weight: 85, Percent: 85, AMRAP: true}} {
+		if i >= len(sets) || sets[i] != want {
+			t.Errorf("set %d = %+v, want %+v", i+1, sets, want)
+		}
+	}
+
+	enrollment.Week = 4
+	if session := program.Prescribe(enrollment); !session.Deload {
+		t.Errorf("week 4 is not a deload: %+v", session)
+	}
+
+	//stage lifts use the sets of their current stage
+	program, enrollment = enroll(t, "gzclp")
+	enrollment.Lifts[0].Stage = 1
+	exercise := program.Prescribe(enrollment).Exercises[0]
+	if exercise.Scheme != "6x2+" || len(exercise.Sets) != 6 || !exercise.Sets[5].AMRAP {
+		t.Errorf("second stage of squat_t1: %+v", exercise)
+	}
+}
+
+func TestCompleteSkipsLiftsWithoutSets(t *testing.T) {
+	program, enrollment := enroll(t, "linear")
+	progress := program.Complete(enrollment, map[int][]models.Set{})
+	if len(progress) != 3 {
+		t.Fatalf("progress = %+v", progress)
+	}
+	for _, result := range progress {
+		if result.Outcome != models.OutcomeSkipped || result.TrainingMax != 100 {
+			t.Errorf("%s = %s at %v, want skipped at 100", result.Lift, result.Outcome, result.TrainingMax)
+		}
+	}
+	if enrollment.Day != 2 {
+		t.Errorf("enrollment stayed on day %d", enrollment.Day)
+	}
+}
+
+func TestSetsMeetPrescription(t *testing.T) {
+	done := false
+	prescribed := []models.PrescribedSet{{Reps: 5, Weight: 80}, {Reps: 3, Weight: 100}}
+	for _, test := range []struct {
+		name      string
+		performed []models.Set
+		want      bool
+	}{
+		{"as prescribed", []models.Set{{Reps: 5, Weight: 80}, {Reps: 3, Weight: 100}}, true},
+		{"in any order", []models.Set{{Reps: 3, Weight: 100}, {Reps: 5, Weight: 80}}, true},
+		{"extra back-off sets", []models.Set{{Reps: 5, Weight: 80}, {Reps: 3, Weight: 100}, {Reps: 8, Weight: 60}}, true},
+		{"rounded from pounds", []models.Set{{Reps: 5, Weight: 79.6}, {Reps: 3, Weight: 99.8}}, true},
+		{"a rep short", []models.Set{{Reps: 5, Weight: 80}, {Reps: 2, Weight: 100}}, false},
+		{"too light", []models.Set{{Reps: 5, Weight: 80}, {Reps: 3, Weight: 97.5}}, false},
+		{"one set for two", []models.Set{{Reps: 5, Weight: 100}}, false},
+		{"warm-ups do not count", []models.Set{{Reps: 5, Weight: 80}, {Reps: 3, Weight: 100, Type: models.SetTypeWarmup}}, false},
+		{"sets not completed do not count", []models.Set{{Reps: 5, Weight: 80}, {Reps: 3, Weight: 100, Completed: &done}}, false},
+	} {
+		if got := setsMeetPrescription(prescribed, test.performed); got != test.want {
+			t.Errorf("%s: setsMeetPrescription = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package programs
+
+import "fmt"
+
+// library holds the built-in programs. Lift exercises must be names or aliases from
+// the exercise catalog, since they are resolved when a user enrolls.
+var library = []Program{
+	{
+		Key:                "linear",
+		Name:               "Linear Progression",
+		Description:        "Novice A/B program: add weight every session and deload by 10% after three failed sessions in a row.",
+		Rounding:           2.5,
+		TrainingMaxPercent: 85,
+		Lifts: []Lift{
+			{Key: "squat", Exercise: "Back Squat", Progression: linear(2.5)},
+			{Key: "bench", Exercise: "Bench Press", Progression: linear(2.5)},
+			{Key: "press", Exercise: "Overhead Press", Progression: linear(2.5)},
+			{Key: "deadlift", Exercise: "Deadlift", Progression: linear(5)},
+		},
+		Weeks: []Week{{Days: []Day{
+			{Name: "A", Slots: []Slot{
+				{Lift: "squat", Sets: straightSets(3, 100, 5)},
+				{Lift: "bench", Sets: straightSets(3, 100, 5)},
+				{Lift: "deadlift", Sets: straightSets(1, 100, 5)},
+			}},
+			{Name: "B", Slots: []Slot{
+				{Lift: "squat", Sets: straightSets(3, 100, 5)},
+				{Lift: "press", Sets: straightSets(3, 100, 5)},
+				{Lift: "deadlift", Sets: straightSets(1, 100, 5)},
+			}},
+		}}},
+	},
+	{
+		Key:                "531",
+		Name:               "5/3/1",
+		Description:        "Four week cycles of 5s, 3s and 5/3/1 waves with a final AMRAP set and a deload week. Training maxes go up each cycle unless an AMRAP set fell short.",
+		Rounding:           2.5,
+		TrainingMaxPercent: 90,
+		Lifts: []Lift{
+			{Key: "press", Exercise: "Overhead Press", Progression: cycle(2.5, 1)},
+			{Key: "deadlift", Exercise: "Deadlift", Progression: cycle(5, 1)},
+			{Key: "bench", Exercise: "Bench Press", Progression: cycle(2.5, 1)},
+			{Key: "squat", Exercise: "Back Squat", Progression: cycle(5, 1)},
+		},
+		Weeks: []Week{
+			wendlerWeek(false, SetPrescription{65, 5, false}, SetPrescription{75, 5, false}, SetPrescription{85, 5, true}),
+			wendlerWeek(false, SetPrescription{70, 3, false}, SetPrescription{80, 3, false}, SetPrescription{90, 3, true}),
+			wendlerWeek(false, SetPrescription{75, 5, false}, SetPrescription{85, 3, false}, SetPrescription{95, 1, true}),
+			wendlerWeek(true, SetPrescription{40, 5, false}, SetPrescription{50, 5, false}, SetPrescription{60, 5, false}),
+		},
+	},
+	{
+		Key:                "gzclp",
+		Name:               "GZCLP",
+		Description:        "Four day tiered program. T1 and T2 lifts add weight every session and drop to lower rep schemes when they stall before resetting; T3 accessories progress linearly.",
+		Rounding:           2.5,
+		TrainingMaxPercent: 85,
+		Lifts: []Lift{
+			{Key: "squat_t1", Exercise: "Back Squat", Progression: tierOne(5)},
+			{Key: "bench_t1", Exercise: "Bench Press", Progression: tierOne(2.5)},
+			{Key: "press_t1", Exercise: "Overhead Press", Progression: tierOne(2.5)},
+			{Key: "deadlift_t1", Exercise: "Deadlift", Progression: tierOne(5)},
+			{Key: "squat_t2", Exercise: "Back Squat", TrainingMaxPercent: 60, Progression: tierTwo(2.5)},
+			{Key: "bench_t2", Exercise: "Bench Press", TrainingMaxPercent: 60, Progression: tierTwo(2.5)},
+			{Key: "press_t2", Exercise: "Overhead Press", TrainingMaxPercent: 60, Progression: tierTwo(2.5)},
+			{Key: "deadlift_t2", Exercise: "Deadlift", TrainingMaxPercent: 60, Progression: tierTwo(5)},
+			{Key: "pulldown_t3", Exercise: "Lat Pulldown", TrainingMaxPercent: 50, Progression: linear(2.5)},
+			{Key: "row_t3", Exercise: "Dumbbell Row", TrainingMaxPercent: 50, Progression: linear(2.5)},
+		},
+		Weeks: []Week{{Days: []Day{
+			{Name: "A1", Slots: []Slot{{Lift: "squat_t1"}, {Lift: "bench_t2"}, {Lift: "pulldown_t3", Sets: tierThree()}}},
+			{Name: "B1", Slots: []Slot{{Lift: "press_t1"}, {Lift: "deadlift_t2"}, {Lift: "row_t3", Sets: tierThree()}}},
+			{Name: "A2", Slots: []Slot{{Lift: "bench_t1"}, {Lift: "squat_t2"}, {Lift: "pulldown_t3", Sets: tierThree()}}},
+			{Name: "B2", Slots: []Slot{{Lift: "deadlift_t1"}, {Lift: "press_t2"}, {Lift: "row_t3", Sets: tierThree()}}},
+		}}},
+	},
+	{
+		Key:                "dup",
+		Name:               "Daily Undulating Periodization",
+		Description:        "Three sessions a week rotating heavy, light and medium rep ranges. Training maxes go up weekly when every session was completed and deload after two failed sessions in a week.",
+		Rounding:           2.5,
+		TrainingMaxPercent: 90,
+		Lifts: []Lift{
+			{Key: "squat", Exercise: "Back Squat", Progression: cycle(2.5, 2)},
+			{Key: "bench", Exercise: "Bench Press", Progression: cycle(2.5, 2)},
+			{Key: "deadlift", Exercise: "Deadlift", Progression: cycle(5, 2)},
+		},
+		Weeks: []Week{{Days: []Day{
+			{Name: "Heavy", Slots: []Slot{
+				{Lift: "squat", Sets: straightSets(5, 85, 3)},
+				{Lift: "bench", Sets: straightSets(5, 85, 3)},
+				{Lift: "deadlift", Sets: straightSets(3, 75, 5)},
+			}},
+			{Name: "Light", Slots: []Slot{
+				{Lift: "squat", Sets: straightSets(3, 65, 10)},
+				{Lift: "bench", Sets: straightSets(3, 65, 10)},
+			}},
+			{Name: "Medium", Slots: []Slot{
+				{Lift: "squat", Sets: straightSets(4, 75, 6)},
+				{Lift: "bench", Sets: straightSets(4, 75, 6)},
+				{Lift: "deadlift", Sets: straightSets(3, 85, 3)},
+			}},
+		}}},
+	},
+}
+
+func init() {
+	//the library is static, so a broken definition is a programming error
+	for i := range library {
+		if err := library[i].Validate(); err != nil {
+			panic(fmt.Sprintf("invalid built-in program: %v", err))
+		}
+	}
+}
+
+// Library returns the built-in programs.
+func Library() []Program {
+	return library
+}
+
+// Lookup returns the built-in program with key.
+func Lookup(key string) (*Program, bool) {
+	for i := range library {
+		if library[i].Key == key {
+			return &library[i], true
+		}
+	}
+	return nil, false
+}
+
+func straightSets(count int, percent float64, reps int) []SetPrescription {
+	sets := make([]SetPrescription, count)
+	for i := range sets {
+		sets[i] = SetPrescription{Percent: percent, Reps: reps}
+	}
+	return sets
+}
+
+// lastSetAMRAP turns the final set of a scheme into an AMRAP set.
+func lastSetAMRAP(sets []SetPrescription) []SetPrescription {
+	sets[len(sets)-1].AMRAP = true
+	return sets
+}
+
+func linear(increment float64) Progression {
+	return Progression{Rule: RuleLinear, Increment: increment, FailureLimit: 3, DeloadPercent: 10}
+}
+
+func cycle(increment float64, failureLimit int) Progression {
+	return Progression{Rule: RuleCycle, Increment: increment, FailureLimit: failureLimit, DeloadPercent: 10}
+}
+
+func tierOne(increment float64) Progression {
+	return Progression{Rule: RuleStages, Increment: increment, FailureLimit: 1, DeloadPercent: 15, Stages: []Stage{
+		{Name: "5x3+", Sets: lastSetAMRAP(straightSets(5, 100, 3))},
+		{Name: "6x2+", Sets: lastSetAMRAP(straightSets(6, 100, 2))},
+		{Name: "10x1+", Sets: lastSetAMRAP(straightSets(10, 100, 1))},
+	}}
+}
+
+func tierTwo(increment float64) Progression {
+	return Progression{Rule: RuleStages, Increment: increment, FailureLimit: 1, DeloadPercent: 15, Stages: []Stage{
+		{Name: "3x10", Sets: straightSets(3, 100, 10)},
+		{Name: "3x8", Sets: straightSets(3, 100, 8)},
+		{Name: "3x6", Sets: straightSets(3, 100, 6)},
+	}}
+}
+
+func tierThree() []SetPrescription {
+	return lastSetAMRAP(straightSets(3, 100, 15))
+}
+
+// wendlerWeek trains one main lift per day on the 5/3/1 percentages of the week.
+func wendlerWeek(deload bool, sets ...SetPrescription) Week {
+	week := Week{Deload: deload}
+	for _, day := range []struct{ name, lift string }{
+		{"Press", "press"}, {"Deadlift", "deadlift"}, {"Bench", "bench"}, {"Squat", "squat"},
+	} {
+		week.Days = append(week.Days, Day{Name: day.name, Slots: []Slot{{Lift: day.lift, Sets: sets}}})
+	}
+	return week
+}
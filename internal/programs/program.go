@@ -0,0 +1,199 @@
+// Package programs holds the declarative definitions of multi-week training programs
+// and the engine that turns them, together with a user's logged sets, into the
+// weights of the next session.
+package programs
+
+import "fmt"
+
+// Rule selects how a lift progresses.
+type Rule string
+
+const (
+	// RuleLinear adds the increment after every successful session and deloads once
+	// FailureLimit sessions in a row were failed.
+	RuleLinear Rule = "linear"
+	// RuleStages adds the increment after every successful session and moves on to the
+	// next set scheme after FailureLimit failed sessions. Failing the last scheme
+	// deloads and starts over at the first one.
+	RuleStages Rule = "stages"
+	// RuleCycle only changes the training max at the end of a cycle: it adds the
+	// increment when no session of the cycle was failed, deloads when FailureLimit or
+	// more were, and holds otherwise.
+	RuleCycle Rule = "cycle"
+)
+
+// Program is a complete program definition. After the last week the program starts
+// over with the next cycle.
+type Program struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Rounding is the step prescribed weights are rounded to.
+	Rounding float64 `json:"rounding"`
+	// TrainingMaxPercent of the estimated one-rep max is used as training max when a
+	// user enrolls without giving one.
+	TrainingMaxPercent float64 `json:"training_max_percent"`
+	Lifts              []Lift  `json:"lifts"`
+	Weeks              []Week  `json:"weeks"`
+}
+
+// Lift is a progression track with its own training max. The same exercise can be
+// trained on more than one track, such as a heavy and a volume squat.
+type Lift struct {
+	Key      string `json:"key"`
+	Exercise string `json:"exercise"`
+	// TrainingMaxPercent overrides the program's percentage for this lift when not zero.
+	TrainingMaxPercent float64     `json:"training_max_percent,omitempty"`
+	Progression        Progression `json:"progression"`
+}
+
+// Progression describes how a lift's training max and set scheme change.
+type Progression struct {
+	Rule          Rule    `json:"rule"`
+	Increment     float64 `json:"increment"`
+	FailureLimit  int     `json:"failure_limit"`
+	DeloadPercent float64 `json:"deload_percent"`
+	// Stages are the set schemes of RuleStages lifts, tried in order.
+	Stages []Stage `json:"stages,omitempty"`
+}
+
+// Stage is a named set scheme such as "5x3+".
+type Stage struct {
+	Name string            `json:"name"`
+	Sets []SetPrescription `json:"sets"`
+}
+
+// Week is one week of a cycle. Deload weeks are trained but never progress or fail a lift.
+type Week struct {
+	Deload bool  `json:"deload,omitempty"`
+	Days   []Day `json:"days"`
+}
+
+// Day is one session of a week.
+type Day struct {
+	Name  string `json:"name"`
+	Slots []Slot `json:"slots"`
+}
+
+// Slot is a lift trained on a day. RuleStages lifts take their sets from the current
+// stage and leave Sets empty.
+type Slot struct {
+	Lift string            `json:"lift"`
+	Sets []SetPrescription `json:"sets,omitempty"`
+}
+
+// SetPrescription is a set at Percent of the training max. AMRAP sets are taken to
+// as many reps as possible, with Reps as the minimum for the session to count.
+type SetPrescription struct {
+	Percent float64 `json:"percent"`
+	Reps    int     `json:"reps"`
+	AMRAP   bool    `json:"amrap,omitempty"`
+}
+
+// Lift returns the lift with key.
+func (p *Program) Lift(key string) (Lift, bool) {
+	for _, lift := range p.Lifts {
+		if lift.Key == key {
+			return lift, true
+		}
+	}
+	return Lift{}, false
+}
+
+// LiftTrainingMaxPercent returns the percentage of the one-rep max a lift's training
+// max starts at.
+func (p *Program) LiftTrainingMaxPercent(lift Lift) float64 {
+	if lift.TrainingMaxPercent > 0 {
+		return lift.TrainingMaxPercent
+	}
+	return p.TrainingMaxPercent
+}
+
+// Validate checks that a definition is complete and consistent.
+func (p *Program) Validate() error {
+	if p.Key == "" || p.Name == "" {
+		return fmt.Errorf("program key and name are required")
+	}
+	if p.Rounding <= 0 || p.TrainingMaxPercent <= 0 {
+		return fmt.Errorf("%s: rounding and training_max_percent must be greater than 0", p.Key)
+	}
+	lifts := make(map[string]Lift)
+	for _, lift := range p.Lifts {
+		if _, ok := lifts[lift.Key]; ok || lift.Key == "" {
+			return fmt.Errorf("%s: lift keys must be unique and not empty", p.Key)
+		}
+		if err := validateProgression(lift.Progression); err != nil {
+			return fmt.Errorf("%s: lift %s: %w", p.Key, lift.Key, err)
+		}
+		lifts[lift.Key] = lift
+	}
+	if len(p.Weeks) == 0 {
+		return fmt.Errorf("%s: at least one week is required", p.Key)
+	}
+	for w, week := range p.Weeks {
+		if len(week.Days) == 0 {
+			return fmt.Errorf("%s: week %d has no days", p.Key, w+1)
+		}
+		for d, day := range week.Days {
+			if len(day.Slots) == 0 {
+				return fmt.Errorf("%s: week %d day %d has no lifts", p.Key, w+1, d+1)
+			}
+			//logged sets are matched to slots by exercise, so an exercise can only appear once a day
+			exercises := make(map[string]bool)
+			for _, slot := range day.Slots {
+				lift, ok := lifts[slot.Lift]
+				if !ok {
+					return fmt.Errorf("%s: week %d day %d uses unknown lift %s", p.Key, w+1, d+1, slot.Lift)
+				}
+				if exercises[lift.Exercise] {
+					return fmt.Errorf("%s: week %d day %d trains %s twice", p.Key, w+1, d+1, lift.Exercise)
+				}
+				exercises[lift.Exercise] = true
+				if lift.Progression.Rule == RuleStages {
+					if len(slot.Sets) > 0 {
+						return fmt.Errorf("%s: lift %s takes its sets from its stages", p.Key, slot.Lift)
+					}
+				} else if err := validateSets(slot.Sets); err != nil {
+					return fmt.Errorf("%s: week %d day %d lift %s: %w", p.Key, w+1, d+1, slot.Lift, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateProgression(progression Progression) error {
+	switch progression.Rule {
+	case RuleLinear, RuleCycle:
+	case RuleStages:
+		if len(progression.Stages) == 0 {
+			return fmt.Errorf("the stages rule needs at least one stage")
+		}
+		for _, stage := range progression.Stages {
+			if err := validateSets(stage.Sets); err != nil {
+				return fmt.Errorf("stage %s: %w", stage.Name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown progression rule %q", progression.Rule)
+	}
+	if progression.Increment < 0 || progression.DeloadPercent < 0 || progression.DeloadPercent >= 100 {
+		return fmt.Errorf("increment and deload_percent are out of range")
+	}
+	if progression.FailureLimit <= 0 {
+		return fmt.Errorf("failure_limit must be greater than 0")
+	}
+	return nil
+}
+
+func validateSets(sets []SetPrescription) error {
+	if len(sets) == 0 {
+		return fmt.Errorf("at least one set is required")
+	}
+	for _, set := range sets {
+		if set.Percent <= 0 || set.Reps <= 0 {
+			return fmt.Errorf("sets need a percent and reps greater than 0")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"errors"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// ErrProfileIncomplete is returned for strength standards without a known sex and bodyweight.
+var ErrProfileIncomplete = errors.New("strength standards need the sex and weight of the profile")
+
+// AnalyticsService derives strength metrics from a user's logged sets. The catalog
+// tells it which muscles an exercise trains and the profile what the lifter weighs.
+type AnalyticsService struct {
+	dbService analyticsStore
+}
+
+type analyticsStore interface {
+	AnalyticsRepository
+	CatalogRepository
+	ProfileRepository
+}
+
+func NewAnalyticsService(dbService analyticsStore) *AnalyticsService {
+	return &AnalyticsService{dbService: dbService}
+}
+
+// OneRepMaxes returns the best estimated one-rep max and a 1-12 rep-max table for
+// every exercise the user has logged, or only for exercise when it is not empty.
+func (a *AnalyticsService) OneRepMaxes(userID int, exercise string, formula analytics.Formula) ([]models.OneRepMaxEstimate, error) {
+	history, err := a.dbService.GetSetHistory(userID, exercise)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	grouped := make(map[string][]models.LoggedSet)
+	for _, set := range history {
+		key := exerciseKey(set.Exercise)
+		if _, ok := grouped[key]; !ok {
+			order = append(order, key)
+		}
+		grouped[key] = append(grouped[key], set)
+	}
+
+	estimates := make([]models.OneRepMaxEstimate, 0, len(order))
+	for _, key := range order {
+		if estimate, ok := estimateFromSets(grouped[key], formula); ok {
+			estimates = append(estimates, estimate)
+		}
+	}
+	return estimates, nil
+}
+
+// CardioVolume buckets the distance and time the user logged by period, for every
+// exercise or only for exercise when it is not empty. Zero from or to leave that
+// side of the range open.
+func (a *AnalyticsService) CardioVolume(userID int, exercise, period string, from, to time.Time) (models.CardioVolume, error) {
+	history, err := a.dbService.GetSetHistory(userID, exercise)
+	if err != nil {
+		return models.CardioVolume{}, err
+	}
+	var sets []models.LoggedSet
+	for _, set := range history {
+		if (from.IsZero() || !set.PerformedAt.Before(from)) && (to.IsZero() || !set.PerformedAt.After(to)) {
+			sets = append(sets, set)
+		}
+	}
+	return analytics.CardioByPeriod(sets, period), nil
+}
+
+// BestPaces returns the user's fastest pace over each standard distance of exercise.
+func (a *AnalyticsService) BestPaces(userID int, exercise string) ([]models.BestPace, error) {
+	history, err := a.dbService.GetSetHistory(userID, exercise)
+	if err != nil {
+		return nil, err
+	}
+	return analytics.BestPaces(history), nil
+}
+
+// DefaultVolumeWeeks is the number of weeks MuscleVolume reports when no range is given.
+const DefaultVolumeWeeks = 8
+
+// MuscleVolume reports the user's weekly hard sets and tonnage per muscle against
+// their volume landmarks. A zero to is now and a zero from is DefaultVolumeWeeks
+// weeks before to, counting the week of to.
+func (a *AnalyticsService) MuscleVolume(userID int, from, to time.Time, secondaryCredit float64) (models.MuscleVolumeReport, error) {
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = analytics.PeriodStart(to, analytics.PeriodWeek).AddDate(0, 0, -7*(DefaultVolumeWeeks-1))
+	}
+	landmarks, err := a.VolumeLandmarks(userID)
+	if err != nil {
+		return models.MuscleVolumeReport{}, err
+	}
+	catalog, err := a.dbService.ListCatalogExercises(userID, models.CatalogFilter{})
+	if err != nil {
+		return models.MuscleVolumeReport{}, err
+	}
+	exercises := make(map[int]models.CatalogExercise, len(catalog))
+	for _, exercise := range catalog {
+		exercises[exercise.ID] = exercise
+	}
+	history, err := a.dbService.GetSetHistory(userID, "")
+	if err != nil {
+		return models.MuscleVolumeReport{}, err
+	}
+	return analytics.MuscleVolume(history, exercises, landmarks, from, to, secondaryCredit), nil
+}
+
+// VolumeLandmarks returns the landmarks of every muscle, the user's own where they set one.
+func (a *AnalyticsService) VolumeLandmarks(userID int) ([]models.VolumeLandmark, error) {
+	custom, err := a.dbService.GetVolumeLandmarks(userID)
+	if err != nil {
+		return nil, err
+	}
+	return analytics.VolumeLandmarks(custom), nil
+}
+
+// SetVolumeLandmarks replaces the user's own landmarks with landmarks, which must have
+// been validated with models.ValidateVolumeLandmarks, and returns the landmarks of
+// every muscle.
+func (a *AnalyticsService) SetVolumeLandmarks(userID int, landmarks []models.VolumeLandmark) ([]models.VolumeLandmark, error) {
+	if err := a.dbService.SaveVolumeLandmarks(userID, landmarks); err != nil {
+		return nil, err
+	}
+	return a.VolumeLandmarks(userID)
+}
+
+// DefaultLoadDays is the number of days TrainingLoad reports when no range is given.
+const DefaultLoadDays = 90
+
+// TrainingLoad returns the user's daily training load with its workload ratio and
+// fitness/fatigue model. A zero to is today and a zero from is DefaultLoadDays days
+// before to, counting the day of to.
+func (a *AnalyticsService) TrainingLoad(userID int, from, to time.Time) (models.TrainingLoad, error) {
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = analytics.PeriodStart(to, analytics.PeriodDay).AddDate(0, 0, -(DefaultLoadDays - 1))
+	}
+	history, err := a.dbService.GetSetHistory(userID, "")
+	if err != nil {
+		return models.TrainingLoad{}, err
+	}
+	return analytics.TrainingLoad(history, from, to), nil
+}
+
+// RecommendNext suggests the weight and reps of the next session of exercise, which
+// matches any alias of its catalog entry. target must have been validated with
+// models.ValidateOverloadTarget.
+func (a *AnalyticsService) RecommendNext(userID int, exercise string, target models.OverloadTarget) (models.OverloadRecommendation, error) {
+	history, err := a.dbService.GetSetHistory(userID, exercise)
+	if err != nil {
+		return models.OverloadRecommendation{}, err
+	}
+	recommendation := analytics.RecommendNext(history, target)
+	if recommendation.Exercise == "" {
+		recommendation.Exercise = exercise
+	}
+	return recommendation, nil
+}
+
+// strengthLifts maps the lifts strength is scored on to their catalog exercise.
+var strengthLifts = []struct{ lift, exercise string }{
+	{models.LiftSquat, "Back Squat"},
+	{models.LiftBench, "Bench Press"},
+	{models.LiftDeadlift, "Deadlift"},
+}
+
+// StrengthStandards scores the best estimated one-rep max of the user's squat, bench
+// and deadlift against their bodyweight. sex and bodyweight, in kg, default to those
+// of the profile; ErrProfileIncomplete is returned when neither gives them.
+func (a *AnalyticsService) StrengthStandards(userID int, sex string, bodyweight float64) (models.StrengthReport, error) {
+	if sex == "" || bodyweight == 0 {
+		profile, err := a.dbService.GetUserProfile(userID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return models.StrengthReport{}, err
+		}
+		if profile != nil && sex == "" {
+			sex = profile.Sex
+		}
+		if profile != nil && bodyweight == 0 {
+			bodyweight = float64(profile.Weight)
+		}
+	}
+	if sex == "" || bodyweight <= 0 {
+		return models.StrengthReport{}, ErrProfileIncomplete
+	}
+
+	report := models.StrengthReport{Sex: sex, Bodyweight: bodyweight, Lifts: []models.LiftStrength{}}
+	logged := 0
+	for _, lift := range strengthLifts {
+		estimates, err := a.OneRepMaxes(userID, lift.exercise, analytics.Epley)
+		if err != nil {
+			return models.StrengthReport{}, err
+		}
+		strength := models.LiftStrength{Lift: lift.lift, Exercise: lift.exercise, Standards: analytics.StrengthStandards(sex, lift.lift, bodyweight)}
+		if len(estimates) > 0 {
+			estimate := estimates[0]
+			logged++
+			strength.Exercise = estimate.ExerciseName
+			strength.EstimatedOneRepMax = estimate.EstimatedOneRepMax
+			strength.BasedOn = &estimate.BasedOn
+			strength.BodyweightMultiple = analytics.Round(estimate.EstimatedOneRepMax/bodyweight, 0.01)
+			strength.Wilks = analytics.Wilks(sex, bodyweight, estimate.EstimatedOneRepMax)
+			strength.DOTS = analytics.DOTS(sex, bodyweight, estimate.EstimatedOneRepMax)
+			if lift.lift == models.LiftBench {
+				strength.IPFGL = analytics.IPFGL(sex, bodyweight, estimate.EstimatedOneRepMax, true)
+			}
+			strength.Level, strength.NextLevel, strength.NextLevelWeight = analytics.StrengthLevel(strength.Standards, estimate.EstimatedOneRepMax)
+			report.Total += estimate.EstimatedOneRepMax
+		}
+		report.Lifts = append(report.Lifts, strength)
+	}
+
+	if logged < len(strengthLifts) {
+		report.Total = 0
+		return report, nil
+	}
+	report.Total = analytics.Round(report.Total, 0.1)
+	report.Wilks = analytics.Wilks(sex, bodyweight, report.Total)
+	report.DOTS = analytics.DOTS(sex, bodyweight, report.Total)
+	report.IPFGL = analytics.IPFGL(sex, bodyweight, report.Total, false)
+	return report, nil
+}
+
+// estimateFromSets picks the set with the highest estimated one-rep max and builds
+// the rep-max table from it, filling in the actual best weights where they exist.
+// Warm-ups and sets that were not completed are ignored.
+func estimateFromSets(sets []models.LoggedSet, formula analytics.Formula) (models.OneRepMaxEstimate, bool) {
+	var estimate models.OneRepMaxEstimate
+	bestByReps := make([]float64, analytics.MaxTableReps)
+	for _, set := range sets {
+		if !set.Counted() {
+			continue
+		}
+		e1rm := analytics.EstimateOneRepMax(formula, set.Weight, set.Reps, set.RPE)
+		if e1rm > estimate.EstimatedOneRepMax {
+			estimate.EstimatedOneRepMax = e1rm
+			estimate.BasedOn = set
+		}
+		//a set of n reps also counts as a rep max for every count below n
+		for reps := 1; reps <= set.Reps && reps <= analytics.MaxTableReps; reps++ {
+			if set.Weight > bestByReps[reps-1] {
+				bestByReps[reps-1] = set.Weight
+			}
+		}
+	}
+	if estimate.EstimatedOneRepMax == 0 {
+		return estimate, false
+	}
+
+	estimate.ExerciseName = estimate.BasedOn.Exercise
+	estimate.Formula = string(formula)
+	estimate.EstimatedOneRepMax = analytics.Round(estimate.EstimatedOneRepMax, 0.1)
+	for i, weight := range analytics.RepMaxTable(formula, estimate.EstimatedOneRepMax) {
+		estimate.RepMaxes = append(estimate.RepMaxes, models.RepMax{
+			Reps:            i + 1,
+			EstimatedWeight: weight,
+			ActualWeight:    bestByReps[i],
+		})
+	}
+	return estimate, true
+}
+
+// exerciseKey normalises an exercise name so "Bench Press" and "bench-press " group
+// together. Exercises linked to the catalog already share the catalog name.
+func exerciseKey(name string) string {
+	return models.NormalizeExerciseName(name)
+}
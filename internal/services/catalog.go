@@ -0,0 +1,523 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"the-gym-app/internal/models"
+)
+
+var (
+	// ErrUnknownExercise is returned when an exercise name, alias or catalog id does
+	// not match any catalog entry visible to the user.
+	ErrUnknownExercise = errors.New("unknown exercise")
+	// ErrExerciseExists is returned when a custom exercise would shadow a visible name or alias.
+	ErrExerciseExists = errors.New("an exercise with that name or alias already exists")
+	// ErrExerciseInUse is returned when deleting a custom exercise that has been logged
+	// or is planned in a template.
+	ErrExerciseInUse = errors.New("exercise is used by logged workouts or templates")
+)
+
+const catalogColumns = "c.id, c.user_id, c.name, c.primary_muscles, c.secondary_muscles, c.equipment, c.movement_pattern, c.unilateral, c.exercise_type"
+
+// ListCatalogExercises returns the library plus the user's custom exercises, by name.
+func (s *DatabaseService) ListCatalogExercises(userID int, filter models.CatalogFilter) ([]models.CatalogExercise, error) {
+	rows, err := s.db.Query(
+		"SELECT "+catalogColumns+" FROM exercise_catalog c WHERE c.user_id IS NULL OR c.user_id = ? ORDER BY c.name, c.id",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var exercises []models.CatalogExercise
+	for rows.Next() {
+		exercise, err := scanCatalogExercise(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		exercises = append(exercises, *exercise)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	aliases, err := s.loadAliases(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.NormalizeExerciseName(filter.Query)
+	matched := []models.CatalogExercise{}
+	for _, exercise := range exercises {
+		exercise.Aliases = aliases[exercise.ID]
+		if exercise.Aliases == nil {
+			exercise.Aliases = []string{}
+		}
+		if filter.Muscle != "" && !containsString(exercise.PrimaryMuscles, filter.Muscle) && !containsString(exercise.SecondaryMuscles, filter.Muscle) {
+			continue
+		}
+		if filter.Equipment != "" && exercise.Equipment != filter.Equipment {
+			continue
+		}
+		if filter.MovementPattern != "" && exercise.MovementPattern != filter.MovementPattern {
+			continue
+		}
+		if query != "" && !catalogNameContains(exercise, query) {
+			continue
+		}
+		matched = append(matched, exercise)
+	}
+	return matched, nil
+}
+
+// GetCatalogExercise returns one catalog entry if it is a library entry or one of the user's own.
+func (s *DatabaseService) GetCatalogExercise(userID, catalogID int) (*models.CatalogExercise, error) {
+	row := s.db.QueryRow(
+		"SELECT "+catalogColumns+" FROM exercise_catalog c WHERE c.id = ? AND (c.user_id IS NULL OR c.user_id = ?)",
+		catalogID, userID,
+	)
+	exercise, err := scanCatalogExercise(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.db.Query("SELECT alias FROM exercise_aliases WHERE catalog_id = ? ORDER BY id", catalogID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	exercise.Aliases = []string{}
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		exercise.Aliases = append(exercise.Aliases, alias)
+	}
+	return exercise, rows.Err()
+}
+
+// ResolveExercise finds the catalog entry a free text name or alias refers to.
+func (s *DatabaseService) ResolveExercise(userID int, name string) (*models.CatalogExercise, error) {
+	catalogID, _, err := lookupExercise(s.db, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCatalogExercise(userID, catalogID)
+}
+
+// CreateCustomExercise adds an exercise only the user can see and log. Neither its
+// name nor its aliases may match an exercise the user can already see.
+func (s *DatabaseService) CreateCustomExercise(userID int, exercise *models.CatalogExercise) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, name := range append([]string{exercise.Name}, exercise.Aliases...) {
+		_, existing, err := lookupExercise(tx, userID, name)
+		if err == nil {
+			return fmt.Errorf("%w: %s is already %s", ErrExerciseExists, name, existing)
+		}
+		if !errors.Is(err, ErrUnknownExercise) {
+			return err
+		}
+	}
+
+	exercise.Name = strings.TrimSpace(exercise.Name)
+	id, err := insertCatalogExercise(tx, &userID, exercise)
+	if err != nil {
+		return err
+	}
+	exercise.ID = id
+	exercise.Custom = true
+	if exercise.Aliases == nil {
+		exercise.Aliases = []string{}
+	}
+	if exercise.SecondaryMuscles == nil {
+		exercise.SecondaryMuscles = []string{}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteCustomExercise removes one of the user's custom exercises. Library entries
+// cannot be deleted and are reported as not found.
+func (s *DatabaseService) DeleteCustomExercise(userID, catalogID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM exercise_catalog WHERE id = ? AND user_id = ?", catalogID, userID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	err = tx.QueryRow(
+		"SELECT (SELECT COUNT(*) FROM exercises WHERE catalog_id = ?) + (SELECT COUNT(*) FROM template_exercises WHERE catalog_id = ?)",
+		catalogID, catalogID,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrExerciseInUse
+	}
+
+	if _, err := tx.Exec("DELETE FROM exercise_aliases WHERE catalog_id = ?", catalogID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM exercise_catalog WHERE id = ?", catalogID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SeedExerciseCatalog inserts the library entries that are missing and refreshes the
+// muscles, equipment and aliases of the existing ones. It does nothing when the
+// current exerciseLibraryVersion was already seeded.
+func (s *DatabaseService) SeedExerciseCatalog() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var seeded int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM catalog_seeds").Scan(&seeded); err != nil {
+		return err
+	}
+	if seeded >= exerciseLibraryVersion {
+		return nil
+	}
+
+	for i := range exerciseLibrary {
+		exercise := exerciseLibrary[i]
+		var catalogID int
+		err := tx.QueryRow(
+			"SELECT id FROM exercise_catalog WHERE user_id IS NULL AND normalized_name = ?",
+			models.NormalizeExerciseName(exercise.Name),
+		).Scan(&catalogID)
+		if err == sql.ErrNoRows {
+			if _, err := insertCatalogExercise(tx, nil, &exercise); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE exercise_catalog SET primary_muscles = ?, secondary_muscles = ?, equipment = ?, movement_pattern = ?, unilateral = ?, exercise_type = ?
+			WHERE id = ?
+		`, strings.Join(exercise.PrimaryMuscles, ","), strings.Join(exercise.SecondaryMuscles, ","), exercise.Equipment, exercise.MovementPattern, exercise.Unilateral,
+			catalogExerciseType(exercise), catalogID)
+		if err != nil {
+			return err
+		}
+		if err := insertAliases(tx, catalogID, exercise.Aliases); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("INSERT INTO catalog_seeds (version) VALUES (?)", exerciseLibraryVersion); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LinkLoggedExercises points unlinked exercises at the catalog entry their name or
+// alias matches and renames them to it, returning how many were linked. Names that
+// match nothing are left as they are. It rewrites logged history, so it only runs
+// from the migrate command.
+func (s *DatabaseService) LinkLoggedExercises() (int, error) {
+	type unlinked struct {
+		id     int
+		name   string
+		userID int
+	}
+	rows, err := s.db.Query(`
+		SELECT e.id, e.exercise, w.user_id FROM exercises e
+		JOIN workouts w on e.workout_id = w.id
+		WHERE e.catalog_id IS NULL
+	`)
+	if err != nil {
+		return 0, err
+	}
+	var pending []unlinked
+	for rows.Next() {
+		var exercise unlinked
+		if err := rows.Scan(&exercise.id, &exercise.name, &exercise.userID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, exercise)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	linked := 0
+	for _, exercise := range pending {
+		catalogID, name, err := lookupExercise(tx, exercise.userID, exercise.name)
+		if errors.Is(err, ErrUnknownExercise) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec("UPDATE exercises SET catalog_id = ?, exercise = ? WHERE id = ?", catalogID, name, exercise.id); err != nil {
+			return 0, err
+		}
+		linked++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return linked, nil
+}
+
+// resolveExercise links a logged exercise to the catalog, by CatalogID when it is
+// set and by name or alias otherwise, and replaces its name with the catalog name.
+func resolveExercise(tx *Tx, userID int, exercise *models.ExerciseLog) error {
+	if exercise.CatalogID != 0 {
+		var name string
+		err := tx.QueryRow(
+			"SELECT name FROM exercise_catalog WHERE id = ? AND (user_id IS NULL OR user_id = ?)",
+			exercise.CatalogID, userID,
+		).Scan(&name)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: catalog id %d", ErrUnknownExercise, exercise.CatalogID)
+		}
+		if err != nil {
+			return err
+		}
+		exercise.Exercise = name
+		return nil
+	}
+
+	catalogID, name, err := lookupExercise(tx, userID, exercise.Exercise)
+	if err != nil {
+		return err
+	}
+	exercise.CatalogID = catalogID
+	exercise.Exercise = name
+	return nil
+}
+
+// linkExercise is resolveExercise for logged exercises, which may also be free text:
+// a name that matches nothing is kept as typed and the exercise left unlinked, so
+// clients that predate the catalog keep working. Unknown catalog ids are still an error.
+func linkExercise(tx *Tx, userID int, exercise *models.ExerciseLog) error {
+	err := resolveExercise(tx, userID, exercise)
+	if !errors.Is(err, ErrUnknownExercise) || exercise.CatalogID != 0 || models.NormalizeExerciseName(exercise.Exercise) == "" {
+		exercise.Unlinked = false
+		return err
+	}
+	exercise.Exercise = strings.TrimSpace(exercise.Exercise)
+	exercise.Unlinked = true
+	return nil
+}
+
+// catalogRef is the catalog_id column value of a logged exercise, NULL when unlinked.
+func catalogRef(exercise *models.ExerciseLog) interface{} {
+	if exercise.CatalogID == 0 {
+		return nil
+	}
+	return exercise.CatalogID
+}
+
+// lookupExercise matches a free text name against the names and aliases visible to
+// the user. An exact name wins over an alias.
+func lookupExercise(q queryer, userID int, name string) (int, string, error) {
+	normalized := models.NormalizeExerciseName(name)
+	if normalized == "" {
+		return 0, "", fmt.Errorf("%w: exercise name is required", ErrUnknownExercise)
+	}
+
+	var catalogID int
+	var catalogName string
+	err := q.QueryRow(`
+		SELECT c.id, c.name FROM exercise_catalog c
+		WHERE (c.user_id IS NULL OR c.user_id = ?)
+		AND (c.normalized_name = ? OR c.id IN (SELECT catalog_id FROM exercise_aliases WHERE normalized = ?))
+		ORDER BY CASE WHEN c.normalized_name = ? THEN 0 ELSE 1 END, c.id
+		LIMIT 1
+	`, userID, normalized, normalized, normalized).Scan(&catalogID, &catalogName)
+	if err == sql.ErrNoRows {
+		return 0, "", fmt.Errorf("%w: %s", ErrUnknownExercise, strings.TrimSpace(name))
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return catalogID, catalogName, nil
+}
+
+// exerciseMatch returns the condition on the exercises table (aliased e) selecting
+// every logged exercise that means exercise: rows linked to its catalog entry and
+// unlinked rows with the same name.
+func exerciseMatch(q queryer, userID int, exercise string) (string, []interface{}, error) {
+	catalogID, _, err := lookupExercise(q, userID, exercise)
+	if errors.Is(err, ErrUnknownExercise) {
+		return "lower(e.exercise) = lower(?)", []interface{}{exercise}, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return "(e.catalog_id = ? OR (e.catalog_id IS NULL AND lower(e.exercise) = lower(?)))", []interface{}{catalogID, exercise}, nil
+}
+
+func insertCatalogExercise(tx *Tx, userID *int, exercise *models.CatalogExercise) (int, error) {
+	id, err := tx.insertID(`
+		INSERT INTO exercise_catalog (user_id, name, normalized_name, primary_muscles, secondary_muscles, equipment, movement_pattern, unilateral, exercise_type)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, userID, exercise.Name, models.NormalizeExerciseName(exercise.Name), strings.Join(exercise.PrimaryMuscles, ","),
+		strings.Join(exercise.SecondaryMuscles, ","), exercise.Equipment, exercise.MovementPattern, exercise.Unilateral, catalogExerciseType(*exercise))
+	if err != nil {
+		return 0, err
+	}
+	return id, insertAliases(tx, id, exercise.Aliases)
+}
+
+// insertAliases adds the aliases a catalog entry does not have yet.
+func insertAliases(tx *Tx, catalogID int, aliases []string) error {
+	rows, err := tx.Query("SELECT normalized FROM exercise_aliases WHERE catalog_id = ?", catalogID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var normalized string
+		if err := rows.Scan(&normalized); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[normalized] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, alias := range aliases {
+		normalized := models.NormalizeExerciseName(alias)
+		if normalized == "" || existing[normalized] {
+			continue
+		}
+		existing[normalized] = true
+		_, err := tx.Exec("INSERT INTO exercise_aliases (catalog_id, alias, normalized) VALUES (?, ?, ?)", catalogID, strings.TrimSpace(alias), normalized)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadAliases returns the aliases of every catalog entry visible to the user.
+func (s *DatabaseService) loadAliases(userID int) (map[int][]string, error) {
+	rows, err := s.db.Query(`
+		SELECT a.catalog_id, a.alias FROM exercise_aliases a
+		JOIN exercise_catalog c on a.catalog_id = c.id
+		WHERE c.user_id IS NULL OR c.user_id = ?
+		ORDER BY a.id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := make(map[int][]string)
+	for rows.Next() {
+		var catalogID int
+		var alias string
+		if err := rows.Scan(&catalogID, &alias); err != nil {
+			return nil, err
+		}
+		aliases[catalogID] = append(aliases[catalogID], alias)
+	}
+	return aliases, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCatalogExercise(row rowScanner) (*models.CatalogExercise, error) {
+	var exercise models.CatalogExercise
+	var owner sql.NullInt64
+	var primary, secondary string
+	err := row.Scan(&exercise.ID, &owner, &exercise.Name, &primary, &secondary, &exercise.Equipment, &exercise.MovementPattern, &exercise.Unilateral, &exercise.ExerciseType)
+	if err != nil {
+		return nil, err
+	}
+	exercise.Custom = owner.Valid
+	exercise.PrimaryMuscles = splitList(primary)
+	exercise.SecondaryMuscles = splitList(secondary)
+	return &exercise, nil
+}
+
+// catalogExerciseType returns the type of a catalog entry; library entries only
+// spell it out when they are not strength exercises.
+func catalogExerciseType(exercise models.CatalogExercise) string {
+	if exercise.ExerciseType == "" {
+		return models.ExerciseTypeStrength
+	}
+	return exercise.ExerciseType
+}
+
+func catalogNameContains(exercise models.CatalogExercise, query string) bool {
+	if strings.Contains(models.NormalizeExerciseName(exercise.Name), query) {
+		return true
+	}
+	for _, alias := range exercise.Aliases {
+		if strings.Contains(models.NormalizeExerciseName(alias), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma separated column, returning an empty slice for "".
+func splitList(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
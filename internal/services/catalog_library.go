@@ -0,0 +1,98 @@
+package services
+
+import "the-gym-app/internal/models"
+
+// exerciseLibraryVersion must be bumped whenever exerciseLibrary changes, otherwise
+// databases that already seeded the library will not pick the change up.
+const exerciseLibraryVersion = 1
+
+// exerciseLibrary is the standard catalog seeded into every database. Entries are
+// matched on their name when seeding, so renaming one adds a new entry; adding
+// aliases to an existing entry is picked up once exerciseLibraryVersion is bumped.
+var exerciseLibrary = []models.CatalogExercise{
+	//squat pattern
+	{Name: "Back Squat", Aliases: []string{"squat", "barbell squat", "bb squat", "high bar squat", "low bar squat"}, PrimaryMuscles: []string{"quads", "glutes"}, SecondaryMuscles: []string{"adductors", "lower back"}, Equipment: "barbell", MovementPattern: "squat"},
+	{Name: "Front Squat", Aliases: []string{"barbell front squat"}, PrimaryMuscles: []string{"quads"}, SecondaryMuscles: []string{"glutes", "upper back"}, Equipment: "barbell", MovementPattern: "squat"},
+	{Name: "Goblet Squat", Aliases: []string{"db goblet squat", "kb goblet squat"}, PrimaryMuscles: []string{"quads"}, SecondaryMuscles: []string{"glutes"}, Equipment: "dumbbell", MovementPattern: "squat"},
+	{Name: "Leg Press", Aliases: []string{"machine leg press", "45 degree leg press"}, PrimaryMuscles: []string{"quads"}, SecondaryMuscles: []string{"glutes"}, Equipment: "machine", MovementPattern: "squat"},
+	{Name: "Hack Squat", Aliases: []string{"machine hack squat"}, PrimaryMuscles: []string{"quads"}, SecondaryMuscles: []string{"glutes"}, Equipment: "machine", MovementPattern: "squat"},
+
+	//hinge pattern
+	{Name: "Deadlift", Aliases: []string{"conventional deadlift", "barbell deadlift", "bb deadlift", "dl"}, PrimaryMuscles: []string{"glutes", "hamstrings", "lower back"}, SecondaryMuscles: []string{"quads", "traps", "forearms"}, Equipment: "barbell", MovementPattern: "hinge"},
+	{Name: "Sumo Deadlift", Aliases: []string{"sumo dl"}, PrimaryMuscles: []string{"glutes", "quads", "adductors"}, SecondaryMuscles: []string{"hamstrings", "lower back"}, Equipment: "barbell", MovementPattern: "hinge"},
+	{Name: "Romanian Deadlift", Aliases: []string{"rdl", "barbell rdl", "stiff leg deadlift", "sldl"}, PrimaryMuscles: []string{"hamstrings", "glutes"}, SecondaryMuscles: []string{"lower back"}, Equipment: "barbell", MovementPattern: "hinge"},
+	{Name: "Trap Bar Deadlift", Aliases: []string{"hex bar deadlift"}, PrimaryMuscles: []string{"quads", "glutes"}, SecondaryMuscles: []string{"hamstrings", "traps"}, Equipment: "trap bar", MovementPattern: "hinge"},
+	{Name: "Hip Thrust", Aliases: []string{"barbell hip thrust", "bb hip thrust"}, PrimaryMuscles: []string{"glutes"}, SecondaryMuscles: []string{"hamstrings"}, Equipment: "barbell", MovementPattern: "hinge"},
+	{Name: "Good Morning", Aliases: []string{"barbell good morning"}, PrimaryMuscles: []string{"hamstrings", "lower back"}, SecondaryMuscles: []string{"glutes"}, Equipment: "barbell", MovementPattern: "hinge"},
+	{Name: "Kettlebell Swing", Aliases: []string{"kb swing", "swing"}, PrimaryMuscles: []string{"glutes", "hamstrings"}, SecondaryMuscles: []string{"lower back"}, Equipment: "kettlebell", MovementPattern: "hinge"},
+
+	//lunge pattern
+	{Name: "Bulgarian Split Squat", Aliases: []string{"bss", "rear foot elevated split squat"}, PrimaryMuscles: []string{"quads", "glutes"}, SecondaryMuscles: []string{"adductors"}, Equipment: "dumbbell", MovementPattern: "lunge", Unilateral: true},
+	{Name: "Walking Lunge", Aliases: []string{"lunge", "dumbbell lunge", "db lunge"}, PrimaryMuscles: []string{"quads", "glutes"}, SecondaryMuscles: []string{"adductors"}, Equipment: "dumbbell", MovementPattern: "lunge", Unilateral: true},
+	{Name: "Step Up", Aliases: []string{"dumbbell step up", "box step up"}, PrimaryMuscles: []string{"quads", "glutes"}, Equipment: "dumbbell", MovementPattern: "lunge", Unilateral: true},
+
+	//horizontal push
+	{Name: "Bench Press", Aliases: []string{"bench", "bb bench", "barbell bench press", "flat bench", "flat bench press"}, PrimaryMuscles: []string{"chest"}, SecondaryMuscles: []string{"triceps", "front delts"}, Equipment: "barbell", MovementPattern: "horizontal push"},
+	{Name: "Incline Bench Press", Aliases: []string{"incline bench", "incline barbell bench press"}, PrimaryMuscles: []string{"chest", "front delts"}, SecondaryMuscles: []string{"triceps"}, Equipment: "barbell", MovementPattern: "horizontal push"},
+	{Name: "Close Grip Bench Press", Aliases: []string{"cgbp", "close grip bench"}, PrimaryMuscles: []string{"triceps", "chest"}, SecondaryMuscles: []string{"front delts"}, Equipment: "barbell", MovementPattern: "horizontal push"},
+	{Name: "Dumbbell Bench Press", Aliases: []string{"db bench", "db bench press", "dumbbell bench"}, PrimaryMuscles: []string{"chest"}, SecondaryMuscles: []string{"triceps", "front delts"}, Equipment: "dumbbell", MovementPattern: "horizontal push"},
+	{Name: "Incline Dumbbell Press", Aliases: []string{"incline db press", "incline dumbbell bench press"}, PrimaryMuscles: []string{"chest", "front delts"}, SecondaryMuscles: []string{"triceps"}, Equipment: "dumbbell", MovementPattern: "horizontal push"},
+	{Name: "Push Up", Aliases: []string{"pushup", "press up"}, PrimaryMuscles: []string{"chest"}, SecondaryMuscles: []string{"triceps", "front delts"}, Equipment: "bodyweight", MovementPattern: "horizontal push"},
+	{Name: "Dip", Aliases: []string{"dips", "parallel bar dip", "chest dip"}, PrimaryMuscles: []string{"chest", "triceps"}, SecondaryMuscles: []string{"front delts"}, Equipment: "bodyweight", MovementPattern: "horizontal push"},
+	{Name: "Machine Chest Press", Aliases: []string{"chest press"}, PrimaryMuscles: []string{"chest"}, SecondaryMuscles: []string{"triceps", "front delts"}, Equipment: "machine", MovementPattern: "horizontal push"},
+
+	//vertical push
+	{Name: "Overhead Press", Aliases: []string{"ohp", "military press", "standing press", "barbell overhead press", "press"}, PrimaryMuscles: []string{"front delts"}, SecondaryMuscles: []string{"triceps", "side delts"}, Equipment: "barbell", MovementPattern: "vertical push"},
+	{Name: "Dumbbell Shoulder Press", Aliases: []string{"db shoulder press", "seated dumbbell press", "db ohp"}, PrimaryMuscles: []string{"front delts"}, SecondaryMuscles: []string{"triceps", "side delts"}, Equipment: "dumbbell", MovementPattern: "vertical push"},
+	{Name: "Push Press", Aliases: []string{"barbell push press"}, PrimaryMuscles: []string{"front delts"}, SecondaryMuscles: []string{"triceps", "quads"}, Equipment: "barbell", MovementPattern: "vertical push"},
+
+	//horizontal pull
+	{Name: "Barbell Row", Aliases: []string{"bb row", "bent over row", "pendlay row", "row"}, PrimaryMuscles: []string{"upper back", "lats"}, SecondaryMuscles: []string{"biceps", "rear delts", "lower back"}, Equipment: "barbell", MovementPattern: "horizontal pull"},
+	{Name: "Dumbbell Row", Aliases: []string{"db row", "one arm dumbbell row", "single arm row"}, PrimaryMuscles: []string{"lats", "upper back"}, SecondaryMuscles: []string{"biceps", "rear delts"}, Equipment: "dumbbell", MovementPattern: "horizontal pull", Unilateral: true},
+	{Name: "Seated Cable Row", Aliases: []string{"cable row", "seated row"}, PrimaryMuscles: []string{"upper back", "lats"}, SecondaryMuscles: []string{"biceps", "rear delts"}, Equipment: "cable", MovementPattern: "horizontal pull"},
+	{Name: "Chest Supported Row", Aliases: []string{"t bar row", "seal row"}, PrimaryMuscles: []string{"upper back"}, SecondaryMuscles: []string{"lats", "rear delts", "biceps"}, Equipment: "machine", MovementPattern: "horizontal pull"},
+	{Name: "Face Pull", Aliases: []string{"cable face pull"}, PrimaryMuscles: []string{"rear delts"}, SecondaryMuscles: []string{"upper back", "traps"}, Equipment: "cable", MovementPattern: "horizontal pull"},
+
+	//vertical pull
+	{Name: "Pull Up", Aliases: []string{"pullup", "pull-ups", "pull ups"}, PrimaryMuscles: []string{"lats"}, SecondaryMuscles: []string{"biceps", "upper back"}, Equipment: "bodyweight", MovementPattern: "vertical pull"},
+	{Name: "Chin Up", Aliases: []string{"chinup", "chin ups"}, PrimaryMuscles: []string{"lats", "biceps"}, SecondaryMuscles: []string{"upper back"}, Equipment: "bodyweight", MovementPattern: "vertical pull"},
+	{Name: "Lat Pulldown", Aliases: []string{"pulldown", "cable pulldown", "lat pull down"}, PrimaryMuscles: []string{"lats"}, SecondaryMuscles: []string{"biceps", "upper back"}, Equipment: "cable", MovementPattern: "vertical pull"},
+
+	//isolation
+	{Name: "Barbell Curl", Aliases: []string{"bb curl", "curl", "biceps curl"}, PrimaryMuscles: []string{"biceps"}, SecondaryMuscles: []string{"forearms"}, Equipment: "barbell", MovementPattern: "isolation"},
+	{Name: "Dumbbell Curl", Aliases: []string{"db curl", "dumbbell biceps curl"}, PrimaryMuscles: []string{"biceps"}, SecondaryMuscles: []string{"forearms"}, Equipment: "dumbbell", MovementPattern: "isolation"},
+	{Name: "Hammer Curl", Aliases: []string{"db hammer curl"}, PrimaryMuscles: []string{"biceps", "forearms"}, Equipment: "dumbbell", MovementPattern: "isolation"},
+	{Name: "Triceps Pushdown", Aliases: []string{"tricep pushdown", "cable pushdown", "rope pushdown"}, PrimaryMuscles: []string{"triceps"}, Equipment: "cable", MovementPattern: "isolation"},
+	{Name: "Skull Crusher", Aliases: []string{"skullcrusher", "lying triceps extension", "ez bar skull crusher"}, PrimaryMuscles: []string{"triceps"}, Equipment: "ez bar", MovementPattern: "isolation"},
+	{Name: "Overhead Triceps Extension", Aliases: []string{"overhead tricep extension", "cable overhead extension"}, PrimaryMuscles: []string{"triceps"}, Equipment: "cable", MovementPattern: "isolation"},
+	{Name: "Lateral Raise", Aliases: []string{"side raise", "db lateral raise", "dumbbell lateral raise"}, PrimaryMuscles: []string{"side delts"}, Equipment: "dumbbell", MovementPattern: "isolation"},
+	{Name: "Rear Delt Fly", Aliases: []string{"reverse fly", "rear delt flye", "reverse pec deck"}, PrimaryMuscles: []string{"rear delts"}, SecondaryMuscles: []string{"upper back"}, Equipment: "dumbbell", MovementPattern: "isolation"},
+	{Name: "Chest Fly", Aliases: []string{"dumbbell fly", "cable fly", "pec deck", "chest flye"}, PrimaryMuscles: []string{"chest"}, SecondaryMuscles: []string{"front delts"}, Equipment: "cable", MovementPattern: "isolation"},
+	{Name: "Leg Extension", Aliases: []string{"leg extensions", "quad extension"}, PrimaryMuscles: []string{"quads"}, Equipment: "machine", MovementPattern: "isolation"},
+	{Name: "Leg Curl", Aliases: []string{"lying leg curl", "seated leg curl", "hamstring curl"}, PrimaryMuscles: []string{"hamstrings"}, Equipment: "machine", MovementPattern: "isolation"},
+	{Name: "Standing Calf Raise", Aliases: []string{"calf raise", "calf raises"}, PrimaryMuscles: []string{"calves"}, Equipment: "machine", MovementPattern: "isolation"},
+	{Name: "Seated Calf Raise", Aliases: []string{"seated calf raises"}, PrimaryMuscles: []string{"calves"}, Equipment: "machine", MovementPattern: "isolation"},
+	{Name: "Shrug", Aliases: []string{"shrugs", "barbell shrug", "dumbbell shrug"}, PrimaryMuscles: []string{"traps"}, SecondaryMuscles: []string{"forearms"}, Equipment: "barbell", MovementPattern: "isolation"},
+	{Name: "Hip Adduction", Aliases: []string{"adductor machine", "adduction machine"}, PrimaryMuscles: []string{"adductors"}, Equipment: "machine", MovementPattern: "isolation"},
+
+	//core and carries
+	{Name: "Plank", Aliases: []string{"front plank"}, PrimaryMuscles: []string{"abs"}, SecondaryMuscles: []string{"obliques"}, Equipment: "bodyweight", MovementPattern: "core", ExerciseType: models.ExerciseTypeTimed},
+	{Name: "Hanging Leg Raise", Aliases: []string{"leg raise", "hanging knee raise"}, PrimaryMuscles: []string{"abs"}, SecondaryMuscles: []string{"obliques"}, Equipment: "bodyweight", MovementPattern: "core"},
+	{Name: "Cable Crunch", Aliases: []string{"kneeling cable crunch"}, PrimaryMuscles: []string{"abs"}, Equipment: "cable", MovementPattern: "core"},
+	{Name: "Ab Wheel Rollout", Aliases: []string{"ab wheel", "ab rollout"}, PrimaryMuscles: []string{"abs"}, SecondaryMuscles: []string{"lats"}, Equipment: "other", MovementPattern: "core"},
+	{Name: "Back Extension", Aliases: []string{"hyperextension", "45 degree back extension"}, PrimaryMuscles: []string{"lower back", "glutes"}, SecondaryMuscles: []string{"hamstrings"}, Equipment: "bodyweight", MovementPattern: "hinge"},
+	{Name: "Farmer's Walk", Aliases: []string{"farmers walk", "farmer carry", "farmers carry"}, PrimaryMuscles: []string{"forearms", "traps"}, SecondaryMuscles: []string{"abs", "obliques"}, Equipment: "dumbbell", MovementPattern: "carry", ExerciseType: models.ExerciseTypeCarry},
+	{Name: "Side Plank", PrimaryMuscles: []string{"obliques"}, SecondaryMuscles: []string{"abs"}, Equipment: "bodyweight", MovementPattern: "core", ExerciseType: models.ExerciseTypeTimed, Unilateral: true},
+	{Name: "Dead Hang", Aliases: []string{"bar hang"}, PrimaryMuscles: []string{"forearms"}, SecondaryMuscles: []string{"lats"}, Equipment: "bodyweight", MovementPattern: "vertical pull", ExerciseType: models.ExerciseTypeTimed},
+	{Name: "Sled Push", Aliases: []string{"prowler push"}, PrimaryMuscles: []string{"quads", "glutes"}, SecondaryMuscles: []string{"calves"}, Equipment: "other", MovementPattern: "carry", ExerciseType: models.ExerciseTypeCarry},
+
+	//cardio
+	{Name: "Running", Aliases: []string{"run", "jog", "jogging", "treadmill", "treadmill run"}, PrimaryMuscles: []string{"quads", "calves"}, SecondaryMuscles: []string{"hamstrings", "glutes"}, Equipment: "bodyweight", MovementPattern: "cardio", ExerciseType: models.ExerciseTypeCardio},
+	{Name: "Walking", Aliases: []string{"walk", "incline walk", "hiking"}, PrimaryMuscles: []string{"calves"}, SecondaryMuscles: []string{"quads", "glutes"}, Equipment: "bodyweight", MovementPattern: "cardio", ExerciseType: models.ExerciseTypeCardio},
+	{Name: "Cycling", Aliases: []string{"bike", "stationary bike", "spin bike", "ride"}, PrimaryMuscles: []string{"quads"}, SecondaryMuscles: []string{"glutes", "calves"}, Equipment: "cardio machine", MovementPattern: "cardio", ExerciseType: models.ExerciseTypeCardio},
+	{Name: "Rowing Machine", Aliases: []string{"rower", "erg", "row erg", "indoor rowing"}, PrimaryMuscles: []string{"upper back", "quads"}, SecondaryMuscles: []string{"lats", "glutes", "biceps"}, Equipment: "cardio machine", MovementPattern: "cardio", ExerciseType: models.ExerciseTypeCardio},
+	{Name: "Elliptical", Aliases: []string{"cross trainer", "elliptical trainer"}, PrimaryMuscles: []string{"quads"}, SecondaryMuscles: []string{"glutes", "hamstrings"}, Equipment: "cardio machine", MovementPattern: "cardio", ExerciseType: models.ExerciseTypeCardio},
+	{Name: "Stair Climber", Aliases: []string{"stairmaster", "stair machine"}, PrimaryMuscles: []string{"quads", "glutes"}, SecondaryMuscles: []string{"calves"}, Equipment: "cardio machine", MovementPattern: "cardio", ExerciseType: models.ExerciseTypeCardio},
+	{Name: "Swimming", Aliases: []string{"swim", "freestyle swim"}, PrimaryMuscles: []string{"lats"}, SecondaryMuscles: []string{"front delts", "triceps"}, Equipment: "other", MovementPattern: "cardio", ExerciseType: models.ExerciseTypeCardio},
+	{Name: "Jump Rope", Aliases: []string{"skipping", "skipping rope"}, PrimaryMuscles: []string{"calves"}, SecondaryMuscles: []string{"forearms"}, Equipment: "other", MovementPattern: "cardio", ExerciseType: models.ExerciseTypeCardio},
+}
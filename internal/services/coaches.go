@@ -0,0 +1,99 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"the-gym-app/internal/models"
+)
+
+// ErrNotCoach is returned when assigning clients to a user whose roles do not allow
+// reading client workouts.
+var ErrNotCoach = errors.New("user is not a coach")
+
+// AssignClient lets coachID read the training of clientID. Assigning a client twice
+// is not an error.
+func (s *DatabaseService) AssignClient(coachID, clientID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var roles string
+	err = tx.QueryRow("SELECT roles FROM users WHERE id = ?", coachID).Scan(&roles)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !models.HasPermission(models.ParseRoles(roles), models.PermViewClientWorkouts) {
+		return ErrNotCoach
+	}
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", clientID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 || clientID == coachID {
+		return ErrNotFound
+	}
+
+	_, err = tx.Exec("INSERT INTO coach_clients (coach_id, client_id) VALUES (?, ?) ON CONFLICT (coach_id, client_id) DO NOTHING", coachID, clientID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UnassignClient removes clientID from the clients of coachID.
+func (s *DatabaseService) UnassignClient(coachID, clientID int) error {
+	result, err := s.db.Exec("DELETE FROM coach_clients WHERE coach_id = ? AND client_id = ?", coachID, clientID)
+	if err != nil {
+		return err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetClients returns the users assigned to coachID without password hashes.
+func (s *DatabaseService) GetClients(coachID int) ([]models.User, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, u.email, u.roles, u.fitness_goal, u.experience_level, u.created_at, u.updated_at
+		FROM coach_clients c
+		JOIN users u on c.client_id = u.id
+		WHERE c.coach_id = ?
+		ORDER BY u.id
+	`, coachID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []models.User{}
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.FitnessGoal, &user.ExperienceLevel, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		user.Role = models.JoinRoles(models.ParseRoles(user.Role))
+		clients = append(clients, user)
+	}
+	return clients, rows.Err()
+}
+
+// IsCoachOf reports whether clientID is assigned to coachID.
+func (s *DatabaseService) IsCoachOf(coachID, clientID int) (bool, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM coach_clients WHERE coach_id = ? AND client_id = ?", coachID, clientID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
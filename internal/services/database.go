@@ -2,123 +2,142 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
 	"the-gym-app/internal/models"
 
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// DefaultSQLitePath is where the database lives when nothing is configured.
+const DefaultSQLitePath = "./gym_app.db"
+
+// Config selects the storage backend.
+type Config struct {
+	// Driver is sqlite3 (the default) or postgres.
+	Driver string
+	// DSN is a file path for sqlite3 or a connection string for postgres.
+	DSN string
+}
+
+// ConfigFromEnv reads GYM_APP_DB_DRIVER and GYM_APP_DB_DSN, falling back to the local
+// SQLite file. Several API replicas can only share a postgres database.
+func ConfigFromEnv() Config {
+	return Config{
+		Driver: os.Getenv("GYM_APP_DB_DRIVER"),
+		DSN:    os.Getenv("GYM_APP_DB_DSN"),
+	}
+}
+
 type DatabaseService struct {
-	db *sql.DB
+	db       *DB
+	migrator *Migrator
 }
 
-func NewDatabaseService() (*DatabaseService, error) {
-	db, err := sql.Open("sqlite3", "./gym_app.db")
+// NewDatabaseService opens the configured database and applies any pending migrations.
+func NewDatabaseService(config Config) (*DatabaseService, error) {
+	dbService, err := OpenDatabaseService(config)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := createTables(db); err != nil {
+	if err := dbService.setUp(); err != nil {
+		dbService.Close()
 		return nil, err
 	}
-	return &DatabaseService{db: db}, nil
+	return dbService, nil
 }
 
-func dropTable(s *sql.DB, tableName string) error {
-	if _, err := s.Exec("DROP TABLE IF EXISTS " + tableName); err != nil {
-		return err
+// OpenDatabaseService opens the database without touching the schema, for tools
+// such as the migrate command that manage migrations themselves.
+func OpenDatabaseService(config Config) (*DatabaseService, error) {
+	dialect, err := ParseDialect(config.Driver)
+	if err != nil {
+		return nil, err
 	}
-	return nil
-}
-
-func cleanupDatabase(db *sql.DB, tables []string) error {
-	for i := 0; i <= len(tables)-1; i++ {
-		if err := dropTable(db, tables[i]); err != nil {
-			return err
+	dsn := config.DSN
+	if dsn == "" {
+		if dialect != DialectSQLite {
+			return nil, fmt.Errorf("GYM_APP_DB_DSN is required for the %s driver", dialect)
 		}
+		dsn = DefaultSQLitePath
 	}
-	return nil
+
+	sqlDB, err := sql.Open(string(dialect), dsn)
+	if err != nil {
+		return nil, err
+	}
+	db := &DB{DB: sqlDB, dialect: dialect}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return &DatabaseService{db: db, migrator: migrator}, nil
 }
 
-func (dbService *DatabaseService) Cleanup() error {
-	//find a way to list the tables in decreasing order of dependencies
-	tables := []string{"sets", "exercises", "workouts"}
-	return cleanupDatabase(dbService.db, tables)
+// Dialect reports which backend this service talks to.
+func (dbService *DatabaseService) Dialect() Dialect {
+	return dbService.db.dialect
 }
 
-func createTables(db *sql.DB) error {
-	//create users table
-	_, err := db.Exec(
-		`CREATE TABLE IF NOT EXISTS users (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			username TEXT NOT NULL,
-			email TEXT NOT NULL,
-			password_hash TEXT NOT NULL,
-			roles TEXT NOT NULL,
-			fitness_goal TEXT NOT NULL,
-			experience_level TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
+// Close releases the underlying connection pool.
+func (dbService *DatabaseService) Close() error {
+	return dbService.db.Close()
+}
+
+// Migrator returns the schema migrator bound to this database.
+func (dbService *DatabaseService) Migrator() *Migrator {
+	return dbService.migrator
+}
+
+// QueryCount returns the number of statements this service has run, for the bench
+// command to check how the queries of a call grow with the data.
+func (dbService *DatabaseService) QueryCount() int64 {
+	return dbService.db.QueryCount()
+}
+
+// workoutTables holds the logged training and everything derived from it, children
+// before the tables they reference.
+var workoutTables = []string{"sets", "workout_groups", "personal_records", "workout_imports", "program_sessions", "insights", "exercises", "workouts"}
+
+// Cleanup deletes every logged workout together with its sets and the records,
+// imports, program sessions and insights derived from it. Users, tokens, profiles,
+// templates and the exercise catalog are kept.
+func (dbService *DatabaseService) Cleanup() error {
+	tx, err := dbService.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	//create workoutlog table
-	_, err = db.Exec(
-		`CREATE TABLE IF NOT EXISTS workouts (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			workout_name TEXT NOT NULL, 
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			user_id INTEGER NOT NULL,
-			FOREIGN KEY (user_id) REFERENCES users (id)
-		)
-	`)
-	if err != nil {
-		return err
+	for _, table := range workoutTables {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return err
+		}
 	}
+	return tx.Commit()
+}
 
-	//create exercises table
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS exercises (
-		id INTEGER PRIMARY KEY AUTOINCREMENT, 
-		exercise TEXT NOT NULL,
-		workout_id INTEGER NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (workout_id) REFERENCES workouts (id)
-		)
-	`)
-	if err != nil {
+// Reset rolls back every migration, dropping all tables and data including users,
+// and then re-applies them to leave an empty database with the current schema.
+func (dbService *DatabaseService) Reset() error {
+	if _, err := dbService.migrator.Down(len(dbService.migrator.migrations)); err != nil {
 		return err
 	}
+	return dbService.setUp()
+}
 
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS sets (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		exercise_id INTEGER NOT NULL,
-		reps INTEGER NOT NULL,
-		weight REAL NOT NULL,
-		rpe REAL NOT NULL,
-		set_number INTEGER NOT NULL,
-		FOREIGN KEY (exercise_id) REFERENCES exercises (id)
-	)
-	`)
-
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS user_profiles(
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		user_id INTEGER NOT NULL,
-		height INTEGER NOT NULL,
-		weight INTEGER NOT NULL,
-		bodyfat REAL,
-		target_weight INTEGER,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	)
-	`)
-
-	return err
+// setUp applies pending migrations and seeds the exercise library if it changed.
+// Logged workouts are never touched here; see LinkLoggedExercises.
+func (dbService *DatabaseService) setUp() error {
+	if _, err := dbService.migrator.Up(); err != nil {
+		return err
+	}
+	return dbService.SeedExerciseCatalog()
 }
 
 func (s *DatabaseService) CheckIfUserExists(username string) (bool, error) {
@@ -139,6 +158,9 @@ func (s *DatabaseService) FetchPassword(username string) (string, error) {
 	return savedPassword, err
 }
 
+// ErrUserExists is returned by SaveUser when the username is already taken.
+var ErrUserExists = errors.New("username already taken")
+
 func (s *DatabaseService) SaveUser(user *models.User) error {
 	//check if the username already exists
 	exists, err := s.CheckIfUserExists(user.Username)
@@ -146,7 +168,10 @@ func (s *DatabaseService) SaveUser(user *models.User) error {
 		return err
 	}
 	if exists {
-		return err
+		return ErrUserExists
+	}
+	if user.Role == "" {
+		user.Role = models.DefaultRole
 	}
 	//if not then create the user
 	tx, err := s.db.Begin()
@@ -163,7 +188,11 @@ func (s *DatabaseService) SaveUser(user *models.User) error {
 	return tx.Commit()
 }
 
-func (s *DatabaseService) SaveWorkout(workout *models.Workout) error {
+// SaveWorkout stores the workout, its exercises and sets against the given user.
+func (s *DatabaseService) SaveWorkout(userID int, workout *models.Workout) error {
+	if err := validateWorkoutGroups(workout); err != nil {
+		return err
+	}
 	//Start a transaction
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -171,117 +200,227 @@ func (s *DatabaseService) SaveWorkout(workout *models.Workout) error {
 	}
 	defer tx.Rollback()
 
-	//fetch user id from context to create a mapping between the workout and the user
+	if err := saveWorkout(tx, userID, workout); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
-	result, err := tx.Exec("INSERT INTO workouts (workout_name) VALUES (?)", workout.Name)
+// saveWorkout inserts a validated workout inside tx. A workout without CreatedAt is
+// stamped by the database; a given one is stored in UTC like every other time, as
+// SQLite compares them as text.
+func saveWorkout(tx *Tx, userID int, workout *models.Workout) error {
+	var workoutID int
+	var err error
+	if workout.CreatedAt.IsZero() {
+		workoutID, err = tx.insertID("INSERT INTO workouts (workout_name, user_id) VALUES (?, ?)", workout.Name, userID)
+	} else {
+		workout.CreatedAt = workout.CreatedAt.UTC()
+		workoutID, err = tx.insertID("INSERT INTO workouts (workout_name, user_id, created_at) VALUES (?, ?, ?)", workout.Name, userID, workout.CreatedAt)
+	}
 	if err != nil {
 		return err
 	}
-	workoutID, err := result.LastInsertId()
-	if err != nil {
+	workout.ID = workoutID
+	workout.UserID = userID
+
+	if err := insertExercises(tx, userID, workout.ID, workout.Exercises); err != nil {
 		return err
 	}
+	return insertGroups(tx, workoutGroups, workout.ID, workout.Groups)
+}
 
-	for _, exercise := range workout.Exercises {
-		//Insert exercises
-		result, err = tx.Exec("INSERT INTO exercises (exercise, workout_id) VALUES (?, ?)", exercise.Exercise, workoutID)
-		if err != nil {
+// insertExercises writes the exercises and their sets for a workout inside tx,
+// resolving them against the user's catalog and filling in the generated ids on the
+// passed in slice.
+func insertExercises(tx *Tx, userID, workoutID int, exercises []models.ExerciseLog) error {
+	for i := range exercises {
+		if err := insertExercise(tx, userID, workoutID, &exercises[i]); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		exerciseID, err := result.LastInsertId()
+func insertExercise(tx *Tx, userID, workoutID int, exercise *models.ExerciseLog) error {
+	if err := linkExercise(tx, userID, exercise); err != nil {
+		return err
+	}
+	//Insert exercises
+	exerciseID, err := tx.insertID(
+		"INSERT INTO exercises (exercise, catalog_id, group_label, workout_id) VALUES (?, ?, ?, ?)",
+		exercise.Exercise, catalogRef(exercise), exercise.Group, workoutID,
+	)
+	if err != nil {
+		return err
+	}
+	exercise.ID = exerciseID
+	exercise.WorkoutID = workoutID
+
+	return insertSets(tx, exercise.ID, exercise.Sets)
+}
+
+func insertSets(tx *Tx, exerciseID int, sets []models.Set) error {
+	exerciseType, err := exerciseTypeOf(tx, exerciseID)
+	if err != nil {
+		return err
+	}
+	//Insert sets
+	for i := range sets {
+		if err := models.ValidateSet(&sets[i], exerciseType); err != nil {
+			return fmt.Errorf("%w: set %d: %v", ErrInvalidSet, i+1, err)
+		}
+		sets[i].SetNumber = i + 1
+		setID, err := tx.insertID(`
+			INSERT INTO sets (exercise_id, reps, weight, rpe, set_number, set_type, rir, tempo, remarks, completed,
+				duration_seconds, distance_meters, avg_heart_rate, max_heart_rate, calories)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, exerciseID, sets[i].Reps, sets[i].Weight, sets[i].RPE, sets[i].SetNumber, sets[i].Type, sets[i].RIR, sets[i].Tempo, sets[i].Remarks, sets[i].Completed,
+			sets[i].DurationSeconds, sets[i].DistanceMeters, sets[i].AvgHeartRate, sets[i].MaxHeartRate, sets[i].Calories,
+		)
 		if err != nil {
 			return err
 		}
-
-		//Insert sets
-		for i, set := range exercise.Sets {
-			_, err := tx.Exec(
-				"INSERT INTO sets (exercise_id, reps, weight, rpe, set_number) VALUES (?, ?, ?, ?, ?)",
-				exerciseID, set.Reps, set.Weight, set.RPE, i+1,
-			)
-			if err != nil {
-				return err
-			}
-		}
+		sets[i].ID = setID
+		sets[i].ExerciseID = exerciseID
 	}
+	return nil
+}
 
-	return tx.Commit()
+// updateSet validates set against the type of its exercise and writes all of its
+// values to the row with setID.
+func updateSet(tx *Tx, exerciseID, setID int, set *models.Set) error {
+	exerciseType, err := exerciseTypeOf(tx, exerciseID)
+	if err != nil {
+		return err
+	}
+	if err := models.ValidateSet(set, exerciseType); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSet, err)
+	}
+	_, err = tx.Exec(`
+		UPDATE sets SET reps = ?, weight = ?, rpe = ?, set_type = ?, rir = ?, tempo = ?, remarks = ?, completed = ?,
+			duration_seconds = ?, distance_meters = ?, avg_heart_rate = ?, max_heart_rate = ?, calories = ?
+		WHERE id = ?
+	`, set.Reps, set.Weight, set.RPE, set.Type, set.RIR, set.Tempo, set.Remarks, set.Completed,
+		set.DurationSeconds, set.DistanceMeters, set.AvgHeartRate, set.MaxHeartRate, set.Calories, setID)
+	return err
 }
 
-func (s *DatabaseService) GetWorkouts() ([]models.Workout, error) {
-	var totalLogs []models.Workout
+// exerciseTypeOf returns the catalog type of a logged exercise, or an empty type for
+// exercises logged as free text.
+func exerciseTypeOf(q queryer, exerciseID int) (string, error) {
+	var exerciseType sql.NullString
+	err := q.QueryRow(`
+		SELECT c.exercise_type FROM exercises e
+		LEFT JOIN exercise_catalog c on e.catalog_id = c.id
+		WHERE e.id = ?
+	`, exerciseID).Scan(&exerciseType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return exerciseType.String, nil
+}
 
-	rows, err := s.db.Query("SELECT id, workout_name, created_at FROM workouts")
+// GetWorkouts returns the full workout history of the given user only, in the order
+// it was logged. Use ListWorkouts to read it a page at a time.
+func (s *DatabaseService) GetWorkouts(userID int) ([]models.Workout, error) {
+	rows, err := s.db.Query("SELECT id, workout_name, created_at, user_id FROM workouts WHERE user_id = ? ORDER BY id", userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var totalLogs []models.Workout
 	for rows.Next() {
 		var workout models.Workout
-		err := rows.Scan(&workout.ID, &workout.Name, &workout.CreatedAt)
+		err := rows.Scan(&workout.ID, &workout.Name, &workout.CreatedAt, &workout.UserID)
 		if err != nil {
 			return nil, err
 		}
-		exerciseRows, err := s.db.Query("SELECT id, exercise FROM exercises WHERE workout_id = ?", workout.ID)
-		if err != nil {
-			return nil, err
-		}
-		for exerciseRows.Next() {
-			var exerciseLog models.ExerciseLog
-
-			err := exerciseRows.Scan(&exerciseLog.ID, &exerciseLog.Exercise)
-			if err != nil {
-				return nil, err
-			}
-			setRows, err := s.db.Query("SELECT reps, weight, rpe, set_number FROM sets WHERE exercise_id = ?", exerciseLog.ID)
-			if err != nil {
-				return nil, err
-			}
-			for setRows.Next() {
-				var set models.Set
-				err := setRows.Scan(&set.Reps, &set.Weight, &set.RPE, &set.SetNumber)
-				if err != nil {
-					return nil, err
-				}
-				exerciseLog.Sets = append(exerciseLog.Sets, set)
-			}
-			setRows.Close()
-			workout.Exercises = append(workout.Exercises, exerciseLog)
-		}
-		exerciseRows.Close()
 		totalLogs = append(totalLogs, workout)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if len(totalLogs) == 0 {
+		return totalLogs, nil
+	}
+
+	if err := s.loadWorkoutDetails(totalLogs, "w.user_id = ?", userID); err != nil {
+		return nil, err
+	}
 	return totalLogs, nil
 }
 
+func (s *DatabaseService) loadSets(exerciseID int) ([]models.Set, error) {
+	var sets []models.Set
+	setRows, err := s.db.Query("SELECT "+setColumns+" FROM sets s WHERE s.exercise_id = ? ORDER BY s.set_number", exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	defer setRows.Close()
+
+	for setRows.Next() {
+		set, err := scanSet(setRows)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, *set)
+	}
+	return sets, setRows.Err()
+}
+
+const setColumns = "s.id, s.exercise_id, s.reps, s.weight, s.rpe, s.set_number, s.set_type, s.rir, s.tempo, s.remarks, s.completed, " +
+	"s.duration_seconds, s.distance_meters, s.avg_heart_rate, s.max_heart_rate, s.calories"
+
+func scanSet(row rowScanner) (*models.Set, error) {
+	var set models.Set
+	var rir sql.NullFloat64
+	var completed bool
+	err := row.Scan(&set.ID, &set.ExerciseID, &set.Reps, &set.Weight, &set.RPE, &set.SetNumber, &set.Type, &rir, &set.Tempo, &set.Remarks, &completed,
+		&set.DurationSeconds, &set.DistanceMeters, &set.AvgHeartRate, &set.MaxHeartRate, &set.Calories)
+	if err != nil {
+		return nil, err
+	}
+	set.PaceSecondsPerKm = models.Pace(set.DurationSeconds, set.DistanceMeters)
+	set.Completed = &completed
+	if rir.Valid {
+		set.RIR = &rir.Float64
+	}
+	return &set, nil
+}
+
+// GetSetRep returns the heaviest weight the user has lifted for at least reps reps
+// of the exercise, which may be given by any of its catalog aliases. Warm-ups and
+// sets that were not completed are left out.
+// ErrNotFound is returned when no such set was logged.
 func (s *DatabaseService) GetSetRep(userId int, exercise string, reps int) (models.SetRep, error) {
-	var setRep models.SetRep
-	row, err := s.db.Query(`
-		SELECT max(weight) FROM sets s
-		JOIN exercises e on s.exercise_id = e.id
-		JOIN workouts w on e.workout_id = w.id
-		JOIN users u on w.user_id = u.id
-		WHERE u.id = ? 
-		AND s.reps = ?
-		AND e.exercise = ?	
-	`, userId, reps, exercise)
+	setRep := models.SetRep{ExerciseName: exercise, Reps: reps}
+	match, matchArgs, err := exerciseMatch(s.db, userId, exercise)
 	if err != nil {
 		return setRep, err
 	}
-	defer row.Close()
-	var maxWeight float64
-	err = row.Scan(&maxWeight)
+	var maxWeight sql.NullFloat64
+	err = s.db.QueryRow(`
+		SELECT max(s.weight) FROM sets s
+		JOIN exercises e on s.exercise_id = e.id
+		JOIN workouts w on e.workout_id = w.id
+		WHERE w.user_id = ?
+		AND s.reps >= ?
+		AND s.completed = ? AND s.set_type <> ?
+		AND `+match, append([]interface{}{userId, reps, true, models.SetTypeWarmup}, matchArgs...)...).Scan(&maxWeight)
 	if err != nil {
 		return setRep, err
 	}
+	if !maxWeight.Valid {
+		return setRep, ErrNotFound
+	}
 
-	//more logic to map to vo
-
+	setRep.Weight = maxWeight.Float64
 	return setRep, nil
 }
 
@@ -297,4 +436,52 @@ func (s *DatabaseService) GetUserIdFromUsername(username string) (int, error) {
 	return userId, nil
 }
 
-// func (s *DatabaseService) GetUserProfileFromUsername(username string)
+// GetUserRoles returns the roles stored for a user.
+func (s *DatabaseService) GetUserRoles(username string) ([]string, error) {
+	var roles string
+	err := s.db.QueryRow("SELECT roles FROM users WHERE username = ?", username).Scan(&roles)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found: %s", username)
+		}
+		return nil, err
+	}
+	return models.ParseRoles(roles), nil
+}
+
+// SetUserRoles replaces the roles of a user. The change applies to tokens issued afterwards.
+func (s *DatabaseService) SetUserRoles(userID int, roles []string) error {
+	result, err := s.db.Exec("UPDATE users SET roles = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", models.JoinRoles(roles), userID)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListUsers returns every user without password hashes.
+func (s *DatabaseService) ListUsers() ([]models.User, error) {
+	rows, err := s.db.Query("SELECT id, username, email, roles, fitness_goal, experience_level, created_at, updated_at FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.FitnessGoal, &user.ExperienceLevel, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		user.Role = models.JoinRoles(models.ParseRoles(user.Role))
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
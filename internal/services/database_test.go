@@ -0,0 +1,46 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"the-gym-app/internal/services"
+	"the-gym-app/internal/services/storetest"
+)
+
+// conformancePostgresEnv names a postgres database the tests may wipe, as for the
+// conformance command.
+const conformancePostgresEnv = "GYM_APP_CONFORMANCE_POSTGRES_DSN"
+
+func TestConformanceSQLite(t *testing.T) {
+	runConformance(t, services.Config{Driver: string(services.DialectSQLite), DSN: filepath.Join(t.TempDir(), "conformance.db")})
+}
+
+func TestConformancePostgres(t *testing.T) {
+	dsn := os.Getenv(conformancePostgresEnv)
+	if dsn == "" {
+		t.Skip(conformancePostgresEnv + " is not set")
+	}
+	runConformance(t, services.Config{Driver: string(services.DialectPostgres), DSN: dsn})
+}
+
+// runConformance wipes the database of config and runs every check of the shared
+// suite against it as a subtest.
+func runConformance(t *testing.T, config services.Config) {
+	dbService, err := services.NewDatabaseService(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbService.Close()
+	if err := dbService.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, check := range storetest.Checks {
+		t.Run(check.Name, func(t *testing.T) {
+			if err := check.Run(dbService); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Dialect names a supported database backend. The values double as database/sql driver names.
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite3"
+	DialectPostgres Dialect = "postgres"
+)
+
+// ParseDialect validates a configured driver name. An empty name means SQLite.
+func ParseDialect(value string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	case "postgres", "postgresql", "pgx":
+		return DialectPostgres, nil
+	}
+	return "", fmt.Errorf("unsupported database driver %q", value)
+}
+
+// DB wraps *sql.DB so queries can be written once with ? placeholders and
+// rewritten for the active dialect. It counts the statements it runs.
+type DB struct {
+	*sql.DB
+	dialect Dialect
+	queries atomic.Int64
+}
+
+// Tx is the transaction counterpart of DB.
+type Tx struct {
+	*sql.Tx
+	dialect Dialect
+	queries *atomic.Int64
+}
+
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	db.queries.Add(1)
+	return db.DB.Exec(rebind(db.dialect, query), args...)
+}
+
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	db.queries.Add(1)
+	return db.DB.Query(rebind(db.dialect, query), args...)
+}
+
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	db.queries.Add(1)
+	return db.DB.QueryRow(rebind(db.dialect, query), args...)
+}
+
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.dialect, queries: &db.queries}, nil
+}
+
+// QueryCount returns the number of statements run through db and its transactions.
+func (db *DB) QueryCount() int64 {
+	return db.queries.Load()
+}
+
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	tx.queries.Add(1)
+	return tx.Tx.Exec(rebind(tx.dialect, query), args...)
+}
+
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	tx.queries.Add(1)
+	return tx.Tx.Query(rebind(tx.dialect, query), args...)
+}
+
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	tx.queries.Add(1)
+	return tx.Tx.QueryRow(rebind(tx.dialect, query), args...)
+}
+
+// insertID runs an INSERT and returns the generated id. RETURNING is understood by
+// both SQLite and Postgres, whose driver does not implement LastInsertId.
+func (tx *Tx) insertID(query string, args ...interface{}) (int, error) {
+	var id int
+	err := tx.QueryRow(query+" RETURNING id", args...).Scan(&id)
+	return id, err
+}
+
+// rebind turns ? placeholders into $1, $2, ... for Postgres. Question marks inside
+// quoted literals are left alone.
+func rebind(dialect Dialect, query string) string {
+	if dialect != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote rune
+	for _, c := range query {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// queryer is satisfied by both DB and Tx, for helpers that read either inside or
+// outside of a transaction.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
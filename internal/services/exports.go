@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"the-gym-app/internal/exporter"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// StreamPageSize is the number of workouts StreamWorkouts reads per page.
+const StreamPageSize = models.MaxPageSize
+
+// StreamWorkouts calls fn with every workout of the user, oldest first. Workouts are
+// read in keyset pages of StreamPageSize, so at most one page is held in memory and
+// no query stays open while fn runs. The workout passed to fn must not be retained.
+// Iteration stops at the first error of fn.
+func (s *DatabaseService) StreamWorkouts(userID int, fn func(*models.Workout) error) error {
+	query := models.WorkoutQuery{Sort: models.SortByDate, Order: models.SortAscending, Limit: StreamPageSize}
+	for {
+		workouts, next, err := s.pageWorkouts(userID, query)
+		if err != nil {
+			return err
+		}
+		if len(workouts) > 0 {
+			where, args := idsIn("w.id", workoutIDs(workouts))
+			if err := s.loadWorkoutDetails(workouts, where, args...); err != nil {
+				return err
+			}
+		}
+		for i := range workouts {
+			if err := fn(&workouts[i]); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		query.Cursor = next
+	}
+}
+
+// ExportService writes a user's data in the export formats.
+type ExportService struct {
+	dbService Store
+}
+
+func NewExportService(dbService Store) *ExportService {
+	return &ExportService{dbService: dbService}
+}
+
+// Export writes the workouts of a user to w in format. The full archive also holds
+// the profile, personal records and body measurements of the account; everything it
+// needs besides the workouts is read before the first byte is written, so a failure
+// to load the account is reported before any output.
+func (e *ExportService) Export(userID int, username string, format exporter.Format, w io.Writer) error {
+	source := func(fn func(*models.Workout) error) error {
+		return e.dbService.StreamWorkouts(userID, fn)
+	}
+	exportedAt := time.Now().UTC()
+
+	switch format {
+	case exporter.FormatCSV:
+		return exporter.WriteCSV(w, source)
+	case exporter.FormatJSON:
+		return exporter.WriteJSON(w, source, exportedAt)
+	}
+
+	account := exporter.Account{Username: username, ExportedAt: exportedAt}
+	profile, err := e.dbService.GetUserProfile(userID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	account.Profile = profile
+	if account.PersonalRecords, err = e.dbService.GetPersonalRecords(userID, "", ""); err != nil {
+		return err
+	}
+	if account.Measurements, err = e.dbService.GetMeasurements(userID, time.Time{}, time.Time{}); err != nil {
+		return err
+	}
+	return exporter.WriteArchive(w, account, source)
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"the-gym-app/internal/models"
+)
+
+// Group tables and the column pointing at the workout or template that owns a group.
+const (
+	workoutGroups  = "workout_groups"
+	templateGroups = "template_groups"
+)
+
+var groupOwnerColumn = map[string]string{workoutGroups: "workout_id", templateGroups: "template_id"}
+
+// validateWorkoutGroups checks the groups of a workout against the group labels of
+// its exercises.
+func validateWorkoutGroups(workout *models.Workout) error {
+	members := make([]string, len(workout.Exercises))
+	for i, exercise := range workout.Exercises {
+		members[i] = exercise.Group
+	}
+	if err := models.ValidateGroups(workout.Groups, members); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGroup, err)
+	}
+	return nil
+}
+
+// insertGroups stores the groups of the workout or template ownerID in table.
+func insertGroups(tx *Tx, table string, ownerID int, groups []models.ExerciseGroup) error {
+	for _, group := range groups {
+		_, err := tx.Exec(
+			"INSERT INTO "+table+" ("+groupOwnerColumn[table]+", label, group_type, rounds, rest_seconds) VALUES (?, ?, ?, ?, ?)",
+			ownerID, group.Label, group.Type, group.Rounds, group.RestSeconds,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadGroups returns the groups in table of the owners matched by where, keyed by owner.
+func (s *DatabaseService) loadGroups(table, where string, args ...interface{}) (map[int][]models.ExerciseGroup, error) {
+	owner := groupOwnerColumn[table]
+	rows, err := s.db.Query("SELECT g."+owner+", g.label, g.group_type, g.rounds, g.rest_seconds FROM "+table+" g WHERE "+where+" ORDER BY g."+owner+", g.id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make(map[int][]models.ExerciseGroup)
+	for rows.Next() {
+		var ownerID int
+		var group models.ExerciseGroup
+		if err := rows.Scan(&ownerID, &group.Label, &group.Type, &group.Rounds, &group.RestSeconds); err != nil {
+			return nil, err
+		}
+		groups[ownerID] = append(groups[ownerID], group)
+	}
+	return groups, rows.Err()
+}
+
+// pruneWorkoutGroups fixes up the groups of a workout after one of its exercises was
+// removed: a group left with a single exercise is dissolved and a giant set left
+// with two becomes a superset.
+func pruneWorkoutGroups(tx *Tx, workoutID int) error {
+	rows, err := tx.Query(`
+		SELECT g.label, g.group_type, COUNT(e.id) FROM workout_groups g
+		LEFT JOIN exercises e on e.workout_id = g.workout_id AND e.group_label = g.label
+		WHERE g.workout_id = ?
+		GROUP BY g.label, g.group_type
+	`, workoutID)
+	if err != nil {
+		return err
+	}
+	type groupSize struct {
+		label, groupType string
+		size             int
+	}
+	var sizes []groupSize
+	for rows.Next() {
+		var size groupSize
+		if err := rows.Scan(&size.label, &size.groupType, &size.size); err != nil {
+			rows.Close()
+			return err
+		}
+		sizes = append(sizes, size)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, size := range sizes {
+		switch {
+		case size.size < 2:
+			if _, err := tx.Exec("UPDATE exercises SET group_label = '' WHERE workout_id = ? AND group_label = ?", workoutID, size.label); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("DELETE FROM workout_groups WHERE workout_id = ? AND label = ?", workoutID, size.label); err != nil {
+				return err
+			}
+		case size.size == 2 && size.groupType == models.GroupTypeGiantSet:
+			_, err := tx.Exec("UPDATE workout_groups SET group_type = ? WHERE workout_id = ? AND label = ?", models.GroupTypeSuperset, workoutID, size.label)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a page cursor that was not issued for the query.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// workoutCursor points after the last workout of a page. The key of that workout is
+// read from the database while it still exists, so Key only matters once the
+// workout was deleted.
+type workoutCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int    `json:"id"`
+}
+
+func encodeCursor(query models.WorkoutQuery, workout models.Workout) string {
+	cursor := workoutCursor{Sort: query.Sort + " " + query.Order, ID: workout.ID, Key: strings.ToLower(workout.Name)}
+	if query.Sort == models.SortByDate {
+		cursor.Key = workout.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(query models.WorkoutQuery) (*workoutCursor, interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(query.Cursor)
+	if err != nil {
+		return nil, nil, ErrInvalidCursor
+	}
+	var cursor workoutCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 || cursor.Sort != query.Sort+" "+query.Order {
+		return nil, nil, ErrInvalidCursor
+	}
+	if query.Sort == models.SortByName {
+		return &cursor, cursor.Key, nil
+	}
+	key, err := time.Parse(time.RFC3339Nano, cursor.Key)
+	if err != nil {
+		return nil, nil, ErrInvalidCursor
+	}
+	return &cursor, key, nil
+}
+
+// pageWorkouts returns the workouts of one page without their exercises, together
+// with the cursor of the next page.
+func (s *DatabaseService) pageWorkouts(userID int, query models.WorkoutQuery) ([]models.Workout, string, error) {
+	where := []string{"w.user_id = ?"}
+	args := []interface{}{userID}
+	if !query.From.IsZero() {
+		where = append(where, "w.created_at >= ?")
+		args = append(args, query.From.UTC())
+	}
+	if !query.To.IsZero() {
+		where = append(where, "w.created_at <= ?")
+		args = append(args, query.To.UTC())
+	}
+	if query.Name != "" {
+		where = append(where, `lower(w.workout_name) LIKE ? ESCAPE '\'`)
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(query.Name))
+		args = append(args, "%"+escaped+"%")
+	}
+	if query.Exercise != "" {
+		match, matchArgs, err := exerciseMatch(s.db, userID, query.Exercise)
+		if err != nil {
+			return nil, "", err
+		}
+		where = append(where, "EXISTS (SELECT 1 FROM exercises e WHERE e.workout_id = w.id AND "+match+")")
+		args = append(args, matchArgs...)
+	}
+
+	//sort by the key, then by id so workouts with the same key keep a stable order
+	column, cursorColumn := "w.created_at", "c.created_at"
+	if query.Sort == models.SortByName {
+		column, cursorColumn = "lower(w.workout_name)", "lower(c.workout_name)"
+	}
+	direction, comparison := "DESC", "<"
+	if query.Order == models.SortAscending {
+		direction, comparison = "ASC", ">"
+	}
+	if query.Cursor != "" {
+		cursor, key, err := decodeCursor(query)
+		if err != nil {
+			return nil, "", err
+		}
+		cursorKey := "COALESCE((SELECT " + cursorColumn + " FROM workouts c WHERE c.id = ? AND c.user_id = ?), ?)"
+		where = append(where, "("+column+" "+comparison+" "+cursorKey+" OR ("+column+" = "+cursorKey+" AND w.id "+comparison+" ?))")
+		args = append(args, cursor.ID, userID, key, cursor.ID, userID, key, cursor.ID)
+	}
+
+	rows, err := s.db.Query(
+		"SELECT w.id, w.workout_name, w.created_at, w.user_id FROM workouts w WHERE "+strings.Join(where, " AND ")+
+			" ORDER BY "+column+" "+direction+", w.id "+direction+" LIMIT ?",
+		append(args, query.Limit+1)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	workouts := []models.Workout{}
+	for rows.Next() {
+		var workout models.Workout
+		if err := rows.Scan(&workout.ID, &workout.Name, &workout.CreatedAt, &workout.UserID); err != nil {
+			return nil, "", err
+		}
+		workouts = append(workouts, workout)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	//one workout more than the page holds is fetched to know whether another page follows
+	if len(workouts) <= query.Limit {
+		return workouts, "", nil
+	}
+	workouts = workouts[:query.Limit]
+	return workouts, encodeCursor(query, workouts[len(workouts)-1]), nil
+}
+
+// ListWorkouts returns a page of the user's workouts with their exercises and sets,
+// filtered and sorted as the query asks. The query must have been validated with
+// models.ValidateWorkoutQuery. ErrInvalidCursor is returned for a cursor of another query.
+func (s *DatabaseService) ListWorkouts(userID int, query models.WorkoutQuery) (*models.WorkoutPage, error) {
+	workouts, next, err := s.pageWorkouts(userID, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(workouts) > 0 {
+		where, args := idsIn("w.id", workoutIDs(workouts))
+		if err := s.loadWorkoutDetails(workouts, where, args...); err != nil {
+			return nil, err
+		}
+	}
+	return &models.WorkoutPage{Workouts: workouts, NextCursor: next}, nil
+}
+
+// ListWorkoutSummaries is ListWorkouts without the sets: every workout lists the names
+// of its exercises, its number of sets and its volume.
+func (s *DatabaseService) ListWorkoutSummaries(userID int, query models.WorkoutQuery) (*models.WorkoutSummaryPage, error) {
+	workouts, next, err := s.pageWorkouts(userID, query)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.WorkoutSummaryPage{Workouts: make([]models.WorkoutSummary, len(workouts)), NextCursor: next}
+	if len(workouts) == 0 {
+		return page, nil
+	}
+	byID := make(map[int]*models.WorkoutSummary, len(workouts))
+	for i, workout := range workouts {
+		page.Workouts[i] = models.WorkoutSummary{ID: workout.ID, Name: workout.Name, CreatedAt: workout.CreatedAt, Exercises: []string{}}
+		byID[workout.ID] = &page.Workouts[i]
+	}
+	where, args := idsIn("e.workout_id", workoutIDs(workouts))
+
+	rows, err := s.db.Query("SELECT e.workout_id, e.exercise FROM exercises e WHERE "+where+" ORDER BY e.workout_id, e.id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var workoutID int
+		var exercise string
+		if err := rows.Scan(&workoutID, &exercise); err != nil {
+			return nil, err
+		}
+		byID[workoutID].Exercises = append(byID[workoutID].Exercises, exercise)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	//volume follows models.Set.Counted: completed sets that are not warm-ups
+	setRows, err := s.db.Query(`
+		SELECT e.workout_id, COUNT(s.id), COALESCE(SUM(CASE WHEN s.completed = ? AND s.set_type <> ? THEN s.weight * s.reps ELSE 0 END), 0)
+		FROM sets s JOIN exercises e on s.exercise_id = e.id
+		WHERE `+where+`
+		GROUP BY e.workout_id
+	`, append([]interface{}{true, models.SetTypeWarmup}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer setRows.Close()
+	for setRows.Next() {
+		var workoutID, sets int
+		var volume float64
+		if err := setRows.Scan(&workoutID, &sets, &volume); err != nil {
+			return nil, err
+		}
+		byID[workoutID].Sets = sets
+		byID[workoutID].Volume = volume
+	}
+	return page, setRows.Err()
+}
+
+// loadWorkoutDetails fills in the exercises, sets and groups of workouts with one
+// query each, whatever the number of workouts. where selects the same workouts on
+// the workouts table (aliased w).
+func (s *DatabaseService) loadWorkoutDetails(workouts []models.Workout, where string, args ...interface{}) error {
+	byID := make(map[int]*models.Workout, len(workouts))
+	for i := range workouts {
+		workouts[i].Exercises = []models.ExerciseLog{}
+		byID[workouts[i].ID] = &workouts[i]
+	}
+
+	rows, err := s.db.Query(`
+		SELECT e.id, e.exercise, COALESCE(e.catalog_id, 0), e.group_label, e.workout_id, e.created_at
+		FROM exercises e JOIN workouts w on e.workout_id = w.id
+		WHERE `+where+`
+		ORDER BY e.workout_id, e.id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	//exercises are referenced by index as appending to a workout may move them
+	type position struct{ workoutID, index int }
+	exercises := make(map[int]position)
+	for rows.Next() {
+		var exercise models.ExerciseLog
+		if err := rows.Scan(&exercise.ID, &exercise.Exercise, &exercise.CatalogID, &exercise.Group, &exercise.WorkoutID, &exercise.CreatedAt); err != nil {
+			return err
+		}
+		workout, ok := byID[exercise.WorkoutID]
+		if !ok {
+			continue
+		}
+		exercise.Sets = []models.Set{}
+		exercise.Unlinked = exercise.CatalogID == 0
+		exercises[exercise.ID] = position{exercise.WorkoutID, len(workout.Exercises)}
+		workout.Exercises = append(workout.Exercises, exercise)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	setRows, err := s.db.Query(`
+		SELECT `+setColumns+` FROM sets s
+		JOIN exercises e on s.exercise_id = e.id
+		JOIN workouts w on e.workout_id = w.id
+		WHERE `+where+`
+		ORDER BY s.exercise_id, s.set_number, s.id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer setRows.Close()
+	for setRows.Next() {
+		set, err := scanSet(setRows)
+		if err != nil {
+			return err
+		}
+		if at, ok := exercises[set.ExerciseID]; ok {
+			exercise := &byID[at.workoutID].Exercises[at.index]
+			exercise.Sets = append(exercise.Sets, *set)
+		}
+	}
+	if err := setRows.Err(); err != nil {
+		return err
+	}
+
+	groups, err := s.loadGroups(workoutGroups, "g.workout_id IN (SELECT w.id FROM workouts w WHERE "+where+")", args...)
+	if err != nil {
+		return err
+	}
+	for id, workout := range byID {
+		workout.Groups = groups[id]
+	}
+	return nil
+}
+
+func workoutIDs(workouts []models.Workout) []int {
+	ids := make([]int, len(workouts))
+	for i, workout := range workouts {
+		ids[i] = workout.ID
+	}
+	return ids
+}
+
+// idsIn returns the condition "column IN (?, ...)" with ids as its arguments.
+func idsIn(column string, ids []int) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return column + " IN (?" + strings.Repeat(", ?", len(ids)-1) + ")", args
+}
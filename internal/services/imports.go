@@ -0,0 +1,264 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"the-gym-app/internal/importer"
+	"the-gym-app/internal/models"
+)
+
+// ErrInvalidImport wraps the reason an export file could not be read.
+var ErrInvalidImport = errors.New("invalid import file")
+
+// ImportOptions control an import.
+type ImportOptions struct {
+	importer.Options
+	// Mapping maps exported exercise names to a catalog name or alias, for names that
+	// do not match the catalog on their own.
+	Mapping map[string]string
+	// DryRun reports what would be imported without writing anything.
+	DryRun bool
+}
+
+// ImportService imports workout history exported by other apps.
+type ImportService struct {
+	dbService Store
+}
+
+func NewImportService(dbService Store) *ImportService {
+	return &ImportService{dbService: dbService}
+}
+
+// Import reads an export, matches its exercise names to the catalog and stores every
+// session that was not imported before in a single transaction. Sessions with an
+// exercise that matches no catalog entry are held back whole and the names listed in
+// the report: importing them partially would record the session as imported and the
+// rest could never follow. Sets that do not fit their exercise's type are left out.
+// Personal records are not detected for imported history.
+func (i *ImportService) Import(userID int, r io.Reader, opts ImportOptions) (*models.ImportReport, error) {
+	sessions, format, err := importer.Parse(r, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	report := &models.ImportReport{
+		Format:     string(format),
+		DryRun:     opts.DryRun,
+		Sessions:   len(sessions),
+		WorkoutIDs: []int{},
+		Unmatched:  []models.UnmatchedExercise{},
+	}
+
+	mapping := make(map[string]string)
+	for from, to := range opts.Mapping {
+		mapping[models.NormalizeExerciseName(from)] = to
+	}
+	matcher := &exerciseMatcher{dbService: i.dbService, userID: userID, mapping: mapping, resolved: make(map[string]*models.CatalogExercise)}
+	//unmatched names are reported in the order they first appear
+	var unmatchedOrder []string
+	unmatched := make(map[string]*models.UnmatchedExercise)
+
+	var importable []models.ImportedSession
+	for _, session := range sessions {
+		workout := &session.Workout
+		var exercises []models.ExerciseLog
+		seen := make(map[string]bool)
+		resolved := true
+		for _, exercise := range workout.Exercises {
+			catalog, err := matcher.match(exercise.Exercise)
+			if err != nil {
+				return nil, err
+			}
+			if catalog == nil {
+				entry, ok := unmatched[exercise.Exercise]
+				if !ok {
+					entry = &models.UnmatchedExercise{Name: exercise.Exercise}
+					unmatched[exercise.Exercise] = entry
+					unmatchedOrder = append(unmatchedOrder, exercise.Exercise)
+				}
+				if !seen[exercise.Exercise] {
+					entry.Sessions++
+					seen[exercise.Exercise] = true
+				}
+				entry.Sets += len(exercise.Sets)
+				resolved = false
+				continue
+			}
+
+			exercise.CatalogID = catalog.ID
+			exercise.Exercise = catalog.Name
+			var sets []models.Set
+			for _, set := range exercise.Sets {
+				if models.ValidateSet(&set, catalog.ExerciseType) != nil {
+					report.SkippedSets++
+					continue
+				}
+				sets = append(sets, set)
+			}
+			if len(sets) == 0 {
+				continue
+			}
+			exercise.Sets = sets
+			exercises = append(exercises, exercise)
+		}
+		if !resolved {
+			report.Unresolved++
+			continue
+		}
+		if len(exercises) == 0 {
+			report.Empty++
+			continue
+		}
+		workout.Exercises = exercises
+		//groups that lost exercises to invalid sets are dropped
+		if validateWorkoutGroups(workout) != nil {
+			workout.Groups = nil
+			for n := range workout.Exercises {
+				workout.Exercises[n].Group = ""
+			}
+		}
+		importable = append(importable, session)
+	}
+
+	for _, name := range unmatchedOrder {
+		report.Unmatched = append(report.Unmatched, *unmatched[name])
+	}
+
+	if opts.DryRun {
+		imported, err := i.dbService.GetImportedSessionKeys(userID, report.Format)
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range importable {
+			if imported[session.Key] {
+				report.Duplicates++
+				continue
+			}
+			report.Imported++
+			report.Sets += countSets(session.Workout)
+		}
+		return report, nil
+	}
+
+	workoutIDs, duplicates, err := i.dbService.ImportWorkouts(userID, report.Format, importable)
+	if err != nil {
+		return nil, err
+	}
+	report.WorkoutIDs = workoutIDs
+	report.Imported = len(workoutIDs)
+	report.Duplicates = duplicates
+	for _, session := range importable {
+		if session.Workout.ID != 0 {
+			report.Sets += countSets(session.Workout)
+		}
+	}
+	return report, nil
+}
+
+func countSets(workout models.Workout) int {
+	sets := 0
+	for _, exercise := range workout.Exercises {
+		sets += len(exercise.Sets)
+	}
+	return sets
+}
+
+// exerciseMatcher resolves exported exercise names against the user's catalog,
+// remembering every name it has looked up.
+type exerciseMatcher struct {
+	dbService Store
+	userID    int
+	mapping   map[string]string
+	resolved  map[string]*models.CatalogExercise
+}
+
+// match returns the catalog entry for name, or nil when nothing matches.
+func (m *exerciseMatcher) match(name string) (*models.CatalogExercise, error) {
+	if catalog, ok := m.resolved[name]; ok {
+		return catalog, nil
+	}
+	candidates := importer.NameCandidates(name)
+	if mapped, ok := m.mapping[models.NormalizeExerciseName(name)]; ok {
+		candidates = []string{mapped}
+	}
+
+	var match *models.CatalogExercise
+	for _, candidate := range candidates {
+		catalog, err := m.dbService.ResolveExercise(m.userID, candidate)
+		if errors.Is(err, ErrUnknownExercise) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		match = catalog
+		break
+	}
+	m.resolved[name] = match
+	return match, nil
+}
+
+// ImportWorkouts stores imported sessions in one transaction, skipping those whose
+// key was imported from source before. The sessions must already be validated.
+// It returns the ids of the new workouts and the number of skipped sessions.
+func (s *DatabaseService) ImportWorkouts(userID int, source string, sessions []models.ImportedSession) ([]int, int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	workoutIDs := []int{}
+	duplicates := 0
+	for i := range sessions {
+		session := &sessions[i]
+		var count int
+		err := tx.QueryRow(
+			"SELECT COUNT(*) FROM workout_imports WHERE user_id = ? AND source = ? AND session_key = ?",
+			userID, source, session.Key,
+		).Scan(&count)
+		if err != nil {
+			return nil, 0, err
+		}
+		if count > 0 {
+			duplicates++
+			continue
+		}
+
+		if err := saveWorkout(tx, userID, &session.Workout); err != nil {
+			return nil, 0, fmt.Errorf("session %s: %w", session.Key, err)
+		}
+		_, err = tx.Exec(
+			"INSERT INTO workout_imports (user_id, source, session_key, workout_id) VALUES (?, ?, ?, ?)",
+			userID, source, session.Key, session.Workout.ID,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		workoutIDs = append(workoutIDs, session.Workout.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+	return workoutIDs, duplicates, nil
+}
+
+// GetImportedSessionKeys returns the keys of every session the user imported from source.
+func (s *DatabaseService) GetImportedSessionKeys(userID int, source string) (map[string]bool, error) {
+	rows, err := s.db.Query("SELECT session_key FROM workout_imports WHERE user_id = ? AND source = ?", userID, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys[key] = true
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// maxVariations caps the exercises a variation swap suggests.
+const maxVariations = 3
+
+// InsightService scans users' estimated 1RM trends for plateaus and regressions.
+type InsightService struct {
+	dbService Store
+}
+
+func NewInsightService(dbService Store) *InsightService {
+	return &InsightService{dbService: dbService}
+}
+
+// Run scans every user right away and then every interval, until stop is closed.
+// Errors are logged, as nobody waits on the scan.
+func (i *InsightService) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := i.ScanAll(time.Now().UTC()); err != nil {
+			log.Printf("insight scan: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// ScanAll scans every user. A failing user does not stop the others; their errors
+// are returned together.
+func (i *InsightService) ScanAll(now time.Time) error {
+	users, err := i.dbService.ListUsers()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, user := range users {
+		if _, err := i.Scan(user.ID, now); err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", user.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Scan looks for plateaus and regressions in the training of the user as of now,
+// records them and returns the user's open insights.
+func (i *InsightService) Scan(userID int, now time.Time) ([]models.Insight, error) {
+	history, err := i.dbService.GetSetHistory(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := i.dbService.ListCatalogExercises(userID, models.CatalogFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	insights := analytics.DetectPlateaus(history, analytics.DefaultPlateauRules, now, func(catalogID int) []string {
+		return variationsOf(catalog, catalogID)
+	})
+	if err := i.dbService.SaveInsights(userID, insights, now); err != nil {
+		return nil, err
+	}
+	return i.dbService.GetInsights(userID, models.InsightFilter{})
+}
+
+// variationsOf names the strength exercises of the catalog with the movement pattern
+// of catalogID that share one of its primary muscles.
+func variationsOf(catalog []models.CatalogExercise, catalogID int) []string {
+	var exercise *models.CatalogExercise
+	for i := range catalog {
+		if catalog[i].ID == catalogID {
+			exercise = &catalog[i]
+		}
+	}
+	if exercise == nil || exercise.MovementPattern == "" {
+		return nil
+	}
+	primary := make(map[string]bool)
+	for _, muscle := range exercise.PrimaryMuscles {
+		primary[muscle] = true
+	}
+	var variations []string
+	for _, other := range catalog {
+		if len(variations) == maxVariations {
+			break
+		}
+		if other.ID == catalogID || other.MovementPattern != exercise.MovementPattern || other.ExerciseType == models.ExerciseTypeCardio {
+			continue
+		}
+		for _, muscle := range other.PrimaryMuscles {
+			if primary[muscle] {
+				variations = append(variations, other.Name)
+				break
+			}
+		}
+	}
+	return variations
+}
+
+// SaveInsights records the result of a scan of the user at now. Insights found before
+// are updated and stay open, new ones are added and open insights the scan no
+// longer found are resolved.
+func (s *DatabaseService) SaveInsights(userID int, insights []models.Insight, now time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now = now.UTC()
+	found := make([]int, 0, len(insights))
+	for _, insight := range insights {
+		interventions, err := json.Marshal(insight.Interventions)
+		if err != nil {
+			return err
+		}
+		var catalogID interface{}
+		if insight.CatalogID != 0 {
+			catalogID = insight.CatalogID
+		}
+
+		var id int
+		err = tx.QueryRow(
+			"SELECT id FROM insights WHERE user_id = ? AND exercise = ? AND kind = ? AND peak_workout_id = ?",
+			userID, insight.Exercise, insight.Kind, insight.PeakWorkoutID,
+		).Scan(&id)
+		switch {
+		case err == sql.ErrNoRows:
+			id, err = tx.insertID(`
+				INSERT INTO insights (user_id, exercise, catalog_id, kind, peak_workout_id, peak_at, peak_one_rep_max, current_one_rep_max,
+					change_percent, sessions_since, weeks_since, interventions, detected_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, userID, insight.Exercise, catalogID, insight.Kind, insight.PeakWorkoutID, insight.PeakAt.UTC(), insight.PeakOneRepMax, insight.CurrentOneRepMax,
+				insight.ChangePercent, insight.SessionsSince, insight.WeeksSince, string(interventions), now)
+		case err == nil:
+			_, err = tx.Exec(`
+				UPDATE insights SET current_one_rep_max = ?, change_percent = ?, sessions_since = ?, weeks_since = ?, interventions = ?, resolved_at = NULL
+				WHERE id = ?
+			`, insight.CurrentOneRepMax, insight.ChangePercent, insight.SessionsSince, insight.WeeksSince, string(interventions), id)
+		}
+		if err != nil {
+			return err
+		}
+		found = append(found, id)
+	}
+
+	query := "UPDATE insights SET resolved_at = ? WHERE user_id = ? AND resolved_at IS NULL"
+	args := []interface{}{now, userID}
+	if len(found) > 0 {
+		where, ids := idsIn("id", found)
+		query += " AND NOT " + where
+		args = append(args, ids...)
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetInsights returns the user's insights selected by filter, newest first.
+func (s *DatabaseService) GetInsights(userID int, filter models.InsightFilter) ([]models.Insight, error) {
+	//aliased e so exerciseMatch applies to the insight's exercise and catalog id
+	query := `
+		SELECT e.id, e.user_id, e.exercise, COALESCE(e.catalog_id, 0), e.kind, e.peak_workout_id, e.peak_at, e.peak_one_rep_max,
+			e.current_one_rep_max, e.change_percent, e.sessions_since, e.weeks_since, e.interventions, e.detected_at, e.resolved_at
+		FROM insights e WHERE e.user_id = ?`
+	args := []interface{}{userID}
+	if filter.Exercise != "" {
+		match, matchArgs, err := exerciseMatch(s.db, userID, filter.Exercise)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND " + match
+		args = append(args, matchArgs...)
+	}
+	if filter.Kind != "" {
+		query += " AND e.kind = ?"
+		args = append(args, filter.Kind)
+	}
+	if !filter.IncludeResolved {
+		query += " AND e.resolved_at IS NULL"
+	}
+	query += " ORDER BY e.detected_at DESC, e.id DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	insights := []models.Insight{}
+	for rows.Next() {
+		var insight models.Insight
+		var interventions string
+		var resolvedAt sql.NullTime
+		err := rows.Scan(&insight.ID, &insight.UserID, &insight.Exercise, &insight.CatalogID, &insight.Kind, &insight.PeakWorkoutID, &insight.PeakAt,
+			&insight.PeakOneRepMax, &insight.CurrentOneRepMax, &insight.ChangePercent, &insight.SessionsSince, &insight.WeeksSince, &interventions,
+			&insight.DetectedAt, &resolvedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(interventions), &insight.Interventions); err != nil {
+			return nil, err
+		}
+		if resolvedAt.Valid {
+			insight.ResolvedAt = &resolvedAt.Time
+		}
+		insights = append(insights, insight)
+	}
+	return insights, rows.Err()
+}
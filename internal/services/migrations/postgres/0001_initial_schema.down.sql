@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS personal_records;
+DROP TABLE IF EXISTS body_measurements;
+DROP TABLE IF EXISTS user_profiles;
+DROP TABLE IF EXISTS sets;
+DROP TABLE IF EXISTS exercises;
+DROP TABLE IF EXISTS workouts;
+DROP TABLE IF EXISTS users;
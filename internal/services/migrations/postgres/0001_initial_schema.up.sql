@@ -0,0 +1,98 @@
+-- same schema as the sqlite baseline, in postgres types.
+
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	username TEXT NOT NULL,
+	email TEXT NOT NULL,
+	password_hash TEXT NOT NULL,
+	roles TEXT NOT NULL,
+	fitness_goal TEXT NOT NULL,
+	experience_level TEXT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS workouts (
+	id SERIAL PRIMARY KEY,
+	workout_name TEXT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	user_id INTEGER NOT NULL REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS exercises (
+	id SERIAL PRIMARY KEY,
+	exercise TEXT NOT NULL,
+	workout_id INTEGER NOT NULL REFERENCES workouts (id),
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sets (
+	id SERIAL PRIMARY KEY,
+	exercise_id INTEGER NOT NULL REFERENCES exercises (id),
+	reps INTEGER NOT NULL,
+	weight DOUBLE PRECISION NOT NULL,
+	rpe DOUBLE PRECISION NOT NULL,
+	set_number INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user_profiles (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	weight INTEGER NOT NULL,
+	bodyfat DOUBLE PRECISION,
+	target_weight INTEGER,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- entries are appended and never overwritten
+CREATE TABLE IF NOT EXISTS body_measurements (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	measured_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	weight DOUBLE PRECISION,
+	bodyfat DOUBLE PRECISION,
+	neck DOUBLE PRECISION,
+	chest DOUBLE PRECISION,
+	waist DOUBLE PRECISION,
+	hips DOUBLE PRECISION,
+	arm DOUBLE PRECISION,
+	thigh DOUBLE PRECISION,
+	calf DOUBLE PRECISION,
+	notes TEXT NOT NULL DEFAULT ''
+);
+
+-- one row per record broken
+CREATE TABLE IF NOT EXISTS personal_records (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	workout_id INTEGER NOT NULL REFERENCES workouts (id),
+	set_id INTEGER,
+	exercise TEXT NOT NULL,
+	record_type TEXT NOT NULL,
+	reps INTEGER,
+	weight DOUBLE PRECISION,
+	value DOUBLE PRECISION NOT NULL,
+	previous_value DOUBLE PRECISION NOT NULL,
+	achieved_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- only the sha256 of a refresh token is stored
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	token_hash TEXT NOT NULL UNIQUE,
+	family_id TEXT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	revoked_at TIMESTAMPTZ,
+	replaced_by INTEGER,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- revoked access tokens keyed by jwt id
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+	jti TEXT PRIMARY KEY,
+	expires_at TIMESTAMPTZ NOT NULL,
+	revoked_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+ALTER TABLE exercises DROP COLUMN catalog_id;
+DROP TABLE IF EXISTS exercise_aliases;
+DROP TABLE IF EXISTS exercise_catalog;
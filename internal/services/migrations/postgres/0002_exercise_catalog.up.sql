@@ -0,0 +1,26 @@
+-- library entries have no user_id, custom exercises belong to the user who added them
+CREATE TABLE exercise_catalog (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users (id),
+	name TEXT NOT NULL,
+	normalized_name TEXT NOT NULL,
+	primary_muscles TEXT NOT NULL DEFAULT '',
+	secondary_muscles TEXT NOT NULL DEFAULT '',
+	equipment TEXT NOT NULL DEFAULT '',
+	movement_pattern TEXT NOT NULL DEFAULT '',
+	unilateral BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_exercise_catalog_normalized_name ON exercise_catalog (normalized_name);
+
+CREATE TABLE exercise_aliases (
+	id SERIAL PRIMARY KEY,
+	catalog_id INTEGER NOT NULL REFERENCES exercise_catalog (id),
+	alias TEXT NOT NULL,
+	normalized TEXT NOT NULL
+);
+
+CREATE INDEX idx_exercise_aliases_normalized ON exercise_aliases (normalized);
+
+ALTER TABLE exercises ADD COLUMN catalog_id INTEGER REFERENCES exercise_catalog (id);
@@ -0,0 +1,25 @@
+CREATE TABLE workout_templates (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	name TEXT NOT NULL,
+	notes TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- planned exercises in the order they are performed
+CREATE TABLE template_exercises (
+	id SERIAL PRIMARY KEY,
+	template_id INTEGER NOT NULL REFERENCES workout_templates (id),
+	catalog_id INTEGER NOT NULL REFERENCES exercise_catalog (id),
+	exercise TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	target_sets INTEGER NOT NULL,
+	min_reps INTEGER NOT NULL,
+	max_reps INTEGER NOT NULL,
+	target_rpe DOUBLE PRECISION NOT NULL DEFAULT 0,
+	rest_seconds INTEGER NOT NULL DEFAULT 0,
+	notes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_template_exercises_template_id ON template_exercises (template_id);
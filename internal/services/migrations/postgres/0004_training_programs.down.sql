@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS program_sessions;
+DROP TABLE IF EXISTS program_lift_states;
+DROP TABLE IF EXISTS program_enrollments;
@@ -0,0 +1,42 @@
+-- a user's run through a built-in program; ended_at is NULL while it is active.
+-- cycle, week and day point at the next session to train.
+CREATE TABLE program_enrollments (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	program_key TEXT NOT NULL,
+	cycle INTEGER NOT NULL,
+	week INTEGER NOT NULL,
+	day INTEGER NOT NULL,
+	started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ended_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_program_enrollments_user_id ON program_enrollments (user_id);
+
+CREATE TABLE program_lift_states (
+	id SERIAL PRIMARY KEY,
+	enrollment_id INTEGER NOT NULL REFERENCES program_enrollments (id),
+	lift TEXT NOT NULL,
+	catalog_id INTEGER NOT NULL REFERENCES exercise_catalog (id),
+	exercise TEXT NOT NULL,
+	training_max DOUBLE PRECISION NOT NULL,
+	stage INTEGER NOT NULL DEFAULT 0,
+	failures INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_program_lift_states_lift ON program_lift_states (enrollment_id, lift);
+
+-- workouts that completed a program session. workout_id has no foreign key so
+-- deleting a workout does not rewrite the program history.
+CREATE TABLE program_sessions (
+	id SERIAL PRIMARY KEY,
+	enrollment_id INTEGER NOT NULL REFERENCES program_enrollments (id),
+	workout_id INTEGER NOT NULL,
+	cycle INTEGER NOT NULL,
+	week INTEGER NOT NULL,
+	day INTEGER NOT NULL,
+	completed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_program_sessions_workout ON program_sessions (enrollment_id, workout_id);
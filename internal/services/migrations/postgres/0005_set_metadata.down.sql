@@ -0,0 +1,5 @@
+ALTER TABLE sets DROP COLUMN completed;
+ALTER TABLE sets DROP COLUMN tempo;
+ALTER TABLE sets DROP COLUMN rir;
+ALTER TABLE sets DROP COLUMN set_type;
+ALTER TABLE sets DROP COLUMN remarks;
@@ -0,0 +1,7 @@
+-- time and distance based exercises. existing catalog entries are strength exercises.
+ALTER TABLE exercise_catalog ADD COLUMN exercise_type TEXT NOT NULL DEFAULT 'strength';
+ALTER TABLE sets ADD COLUMN duration_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sets ADD COLUMN distance_meters DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE sets ADD COLUMN avg_heart_rate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sets ADD COLUMN max_heart_rate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sets ADD COLUMN calories INTEGER NOT NULL DEFAULT 0;
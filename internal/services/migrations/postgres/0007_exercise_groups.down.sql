@@ -0,0 +1,4 @@
+ALTER TABLE template_exercises DROP COLUMN group_label;
+ALTER TABLE exercises DROP COLUMN group_label;
+DROP TABLE IF EXISTS template_groups;
+DROP TABLE IF EXISTS workout_groups;
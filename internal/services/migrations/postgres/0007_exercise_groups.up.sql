@@ -0,0 +1,24 @@
+-- supersets, giant sets and circuits. exercises join a group of their workout or
+-- template by its label; an empty label means the exercise is not grouped.
+CREATE TABLE workout_groups (
+	id SERIAL PRIMARY KEY,
+	workout_id INTEGER NOT NULL REFERENCES workouts (id),
+	label TEXT NOT NULL,
+	group_type TEXT NOT NULL,
+	rounds INTEGER NOT NULL DEFAULT 1,
+	rest_seconds INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (workout_id, label)
+);
+
+CREATE TABLE template_groups (
+	id SERIAL PRIMARY KEY,
+	template_id INTEGER NOT NULL REFERENCES workout_templates (id),
+	label TEXT NOT NULL,
+	group_type TEXT NOT NULL,
+	rounds INTEGER NOT NULL DEFAULT 1,
+	rest_seconds INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (template_id, label)
+);
+
+ALTER TABLE exercises ADD COLUMN group_label TEXT NOT NULL DEFAULT '';
+ALTER TABLE template_exercises ADD COLUMN group_label TEXT NOT NULL DEFAULT '';
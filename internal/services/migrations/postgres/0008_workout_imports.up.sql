@@ -0,0 +1,11 @@
+-- sessions imported from other apps, so importing the same export twice skips them.
+-- deleting an imported workout removes its row and lets it be imported again.
+CREATE TABLE workout_imports (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	source TEXT NOT NULL,
+	session_key TEXT NOT NULL,
+	workout_id INTEGER NOT NULL REFERENCES workouts (id),
+	imported_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (user_id, source, session_key)
+);
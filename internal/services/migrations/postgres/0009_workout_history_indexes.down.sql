@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_exercises_catalog_workout;
+DROP INDEX IF EXISTS idx_workouts_user_name;
+DROP INDEX IF EXISTS idx_workouts_user_created;
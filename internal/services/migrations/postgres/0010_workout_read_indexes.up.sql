@@ -0,0 +1,4 @@
+-- workouts are read with their exercises and sets in bulk by these foreign keys.
+-- lookups of workouts by user_id use idx_workouts_user_created from 0009.
+CREATE INDEX idx_exercises_workout_id ON exercises (workout_id);
+CREATE INDEX idx_sets_exercise_id ON sets (exercise_id, set_number);
@@ -0,0 +1,9 @@
+-- a user's own weekly hard set landmarks, replacing the built-in default of a muscle.
+CREATE TABLE volume_landmarks (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	muscle TEXT NOT NULL,
+	mev DOUBLE PRECISION NOT NULL,
+	mrv DOUBLE PRECISION NOT NULL,
+	UNIQUE (user_id, muscle)
+);
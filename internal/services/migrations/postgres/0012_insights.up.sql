@@ -0,0 +1,21 @@
+-- plateaus and regressions found by the insight scan, one per exercise, kind and peak.
+-- the peak workout and catalog entry are not foreign keys so insights never block
+-- deleting them; once the peak workout is gone the next scan resolves its insight.
+CREATE TABLE insights (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	exercise TEXT NOT NULL,
+	catalog_id INTEGER,
+	kind TEXT NOT NULL,
+	peak_workout_id INTEGER NOT NULL,
+	peak_at TIMESTAMPTZ NOT NULL,
+	peak_one_rep_max DOUBLE PRECISION NOT NULL,
+	current_one_rep_max DOUBLE PRECISION NOT NULL,
+	change_percent DOUBLE PRECISION NOT NULL,
+	sessions_since INTEGER NOT NULL,
+	weeks_since INTEGER NOT NULL,
+	interventions TEXT NOT NULL,
+	detected_at TIMESTAMPTZ NOT NULL,
+	resolved_at TIMESTAMPTZ,
+	UNIQUE (user_id, exercise, kind, peak_workout_id)
+);
@@ -0,0 +1,2 @@
+-- sex of the lifter, for strength standards and points formulas. empty when not given.
+ALTER TABLE user_profiles ADD COLUMN sex TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS plate_inventories;
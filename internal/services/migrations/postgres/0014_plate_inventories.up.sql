@@ -0,0 +1,10 @@
+-- the bar and plates a user has, one inventory per user. plates is a JSON list of
+-- weight and count pairs.
+CREATE TABLE plate_inventories (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL UNIQUE REFERENCES users (id),
+	unit TEXT NOT NULL,
+	bar_weight DOUBLE PRECISION NOT NULL,
+	plates TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,10 @@
+-- which users a coach may read the training of. Coach routes answer 404 for anyone
+-- not listed here.
+CREATE TABLE coach_clients (
+	coach_id INTEGER NOT NULL REFERENCES users (id),
+	client_id INTEGER NOT NULL REFERENCES users (id),
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (coach_id, client_id)
+);
+
+CREATE INDEX idx_coach_clients_client ON coach_clients (client_id);
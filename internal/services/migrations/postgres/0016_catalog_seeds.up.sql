@@ -0,0 +1,6 @@
+-- which version of the built-in exercise library has been seeded, so startup only
+-- writes the catalog when the library changed.
+CREATE TABLE catalog_seeds (
+	version INTEGER PRIMARY KEY,
+	seeded_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
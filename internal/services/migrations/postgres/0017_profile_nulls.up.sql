@@ -0,0 +1,3 @@
+-- profiles used to store 0 for a bodyfat or target weight that was not given
+UPDATE user_profiles SET bodyfat = NULL WHERE bodyfat = 0;
+UPDATE user_profiles SET target_weight = NULL WHERE target_weight = 0;
@@ -0,0 +1,106 @@
+-- baseline of the schema that used to be created by createTables.
+-- IF NOT EXISTS keeps it a no-op on databases created before migrations existed.
+
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	username TEXT NOT NULL,
+	email TEXT NOT NULL,
+	password_hash TEXT NOT NULL,
+	roles TEXT NOT NULL,
+	fitness_goal TEXT NOT NULL,
+	experience_level TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS workouts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workout_name TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS exercises (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exercise TEXT NOT NULL,
+	workout_id INTEGER NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (workout_id) REFERENCES workouts (id)
+);
+
+CREATE TABLE IF NOT EXISTS sets (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	exercise_id INTEGER NOT NULL,
+	reps INTEGER NOT NULL,
+	weight REAL NOT NULL,
+	rpe REAL NOT NULL,
+	set_number INTEGER NOT NULL,
+	FOREIGN KEY (exercise_id) REFERENCES exercises (id)
+);
+
+CREATE TABLE IF NOT EXISTS user_profiles (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	weight INTEGER NOT NULL,
+	bodyfat REAL,
+	target_weight INTEGER,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- entries are appended and never overwritten
+CREATE TABLE IF NOT EXISTS body_measurements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	measured_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	weight REAL,
+	bodyfat REAL,
+	neck REAL,
+	chest REAL,
+	waist REAL,
+	hips REAL,
+	arm REAL,
+	thigh REAL,
+	calf REAL,
+	notes TEXT NOT NULL DEFAULT '',
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+-- one row per record broken
+CREATE TABLE IF NOT EXISTS personal_records (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	workout_id INTEGER NOT NULL,
+	set_id INTEGER,
+	exercise TEXT NOT NULL,
+	record_type TEXT NOT NULL,
+	reps INTEGER,
+	weight REAL,
+	value REAL NOT NULL,
+	previous_value REAL NOT NULL,
+	achieved_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users (id),
+	FOREIGN KEY (workout_id) REFERENCES workouts (id)
+);
+
+-- only the sha256 of a refresh token is stored
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	family_id TEXT NOT NULL,
+	expires_at DATETIME NOT NULL,
+	revoked_at DATETIME,
+	replaced_by INTEGER,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+-- revoked access tokens keyed by jwt id
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+	jti TEXT PRIMARY KEY,
+	expires_at DATETIME NOT NULL,
+	revoked_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
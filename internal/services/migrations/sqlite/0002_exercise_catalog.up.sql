@@ -0,0 +1,29 @@
+-- library entries have no user_id, custom exercises belong to the user who added them
+CREATE TABLE exercise_catalog (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER,
+	name TEXT NOT NULL,
+	normalized_name TEXT NOT NULL,
+	primary_muscles TEXT NOT NULL DEFAULT '',
+	secondary_muscles TEXT NOT NULL DEFAULT '',
+	equipment TEXT NOT NULL DEFAULT '',
+	movement_pattern TEXT NOT NULL DEFAULT '',
+	unilateral BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX idx_exercise_catalog_normalized_name ON exercise_catalog (normalized_name);
+
+CREATE TABLE exercise_aliases (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	catalog_id INTEGER NOT NULL,
+	alias TEXT NOT NULL,
+	normalized TEXT NOT NULL,
+	FOREIGN KEY (catalog_id) REFERENCES exercise_catalog (id)
+);
+
+CREATE INDEX idx_exercise_aliases_normalized ON exercise_aliases (normalized);
+
+-- no foreign key here: sqlite cannot drop a column that is part of one
+ALTER TABLE exercises ADD COLUMN catalog_id INTEGER;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS template_exercises;
+DROP TABLE IF EXISTS workout_templates;
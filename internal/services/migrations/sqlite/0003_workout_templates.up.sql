@@ -0,0 +1,28 @@
+CREATE TABLE workout_templates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	notes TEXT NOT NULL DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+-- planned exercises in the order they are performed
+CREATE TABLE template_exercises (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	template_id INTEGER NOT NULL,
+	catalog_id INTEGER NOT NULL,
+	exercise TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	target_sets INTEGER NOT NULL,
+	min_reps INTEGER NOT NULL,
+	max_reps INTEGER NOT NULL,
+	target_rpe REAL NOT NULL DEFAULT 0,
+	rest_seconds INTEGER NOT NULL DEFAULT 0,
+	notes TEXT NOT NULL DEFAULT '',
+	FOREIGN KEY (template_id) REFERENCES workout_templates (id),
+	FOREIGN KEY (catalog_id) REFERENCES exercise_catalog (id)
+);
+
+CREATE INDEX idx_template_exercises_template_id ON template_exercises (template_id);
@@ -0,0 +1,46 @@
+-- a user's run through a built-in program; ended_at is NULL while it is active.
+-- cycle, week and day point at the next session to train.
+CREATE TABLE program_enrollments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	program_key TEXT NOT NULL,
+	cycle INTEGER NOT NULL,
+	week INTEGER NOT NULL,
+	day INTEGER NOT NULL,
+	started_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ended_at DATETIME,
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX idx_program_enrollments_user_id ON program_enrollments (user_id);
+
+CREATE TABLE program_lift_states (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	enrollment_id INTEGER NOT NULL,
+	lift TEXT NOT NULL,
+	catalog_id INTEGER NOT NULL,
+	exercise TEXT NOT NULL,
+	training_max REAL NOT NULL,
+	stage INTEGER NOT NULL DEFAULT 0,
+	failures INTEGER NOT NULL DEFAULT 0,
+	FOREIGN KEY (enrollment_id) REFERENCES program_enrollments (id),
+	FOREIGN KEY (catalog_id) REFERENCES exercise_catalog (id)
+);
+
+CREATE UNIQUE INDEX idx_program_lift_states_lift ON program_lift_states (enrollment_id, lift);
+
+-- workouts that completed a program session. workout_id has no foreign key so
+-- deleting a workout does not rewrite the program history.
+CREATE TABLE program_sessions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	enrollment_id INTEGER NOT NULL,
+	workout_id INTEGER NOT NULL,
+	cycle INTEGER NOT NULL,
+	week INTEGER NOT NULL,
+	day INTEGER NOT NULL,
+	completed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (enrollment_id) REFERENCES program_enrollments (id)
+);
+
+CREATE UNIQUE INDEX idx_program_sessions_workout ON program_sessions (enrollment_id, workout_id);
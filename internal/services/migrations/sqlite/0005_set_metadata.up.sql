@@ -0,0 +1,6 @@
+-- optional set metadata. existing sets are completed working sets.
+ALTER TABLE sets ADD COLUMN remarks TEXT NOT NULL DEFAULT '';
+ALTER TABLE sets ADD COLUMN set_type TEXT NOT NULL DEFAULT 'working';
+ALTER TABLE sets ADD COLUMN rir REAL;
+ALTER TABLE sets ADD COLUMN tempo TEXT NOT NULL DEFAULT '';
+ALTER TABLE sets ADD COLUMN completed BOOLEAN NOT NULL DEFAULT TRUE;
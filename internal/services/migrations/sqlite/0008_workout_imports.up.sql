@@ -0,0 +1,13 @@
+-- sessions imported from other apps, so importing the same export twice skips them.
+-- deleting an imported workout removes its row and lets it be imported again.
+CREATE TABLE workout_imports (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	source TEXT NOT NULL,
+	session_key TEXT NOT NULL,
+	workout_id INTEGER NOT NULL,
+	imported_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users (id),
+	FOREIGN KEY (workout_id) REFERENCES workouts (id),
+	UNIQUE (user_id, source, session_key)
+);
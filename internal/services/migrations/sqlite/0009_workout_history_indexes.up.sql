@@ -0,0 +1,5 @@
+-- keyset pagination of the workout history walks these in either direction.
+CREATE INDEX idx_workouts_user_created ON workouts (user_id, created_at, id);
+CREATE INDEX idx_workouts_user_name ON workouts (user_id, lower(workout_name), id);
+-- the exercise filter looks up a workout's exercises by catalog entry.
+CREATE INDEX idx_exercises_catalog_workout ON exercises (catalog_id, workout_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sets_exercise_id;
+DROP INDEX IF EXISTS idx_exercises_workout_id;
@@ -0,0 +1,10 @@
+-- a user's own weekly hard set landmarks, replacing the built-in default of a muscle.
+CREATE TABLE volume_landmarks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	muscle TEXT NOT NULL,
+	mev REAL NOT NULL,
+	mrv REAL NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users (id),
+	UNIQUE (user_id, muscle)
+);
@@ -0,0 +1,22 @@
+-- plateaus and regressions found by the insight scan, one per exercise, kind and peak.
+-- the peak workout and catalog entry are not foreign keys so insights never block
+-- deleting them; once the peak workout is gone the next scan resolves its insight.
+CREATE TABLE insights (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	exercise TEXT NOT NULL,
+	catalog_id INTEGER,
+	kind TEXT NOT NULL,
+	peak_workout_id INTEGER NOT NULL,
+	peak_at DATETIME NOT NULL,
+	peak_one_rep_max REAL NOT NULL,
+	current_one_rep_max REAL NOT NULL,
+	change_percent REAL NOT NULL,
+	sessions_since INTEGER NOT NULL,
+	weeks_since INTEGER NOT NULL,
+	interventions TEXT NOT NULL,
+	detected_at DATETIME NOT NULL,
+	resolved_at DATETIME,
+	FOREIGN KEY (user_id) REFERENCES users (id),
+	UNIQUE (user_id, exercise, kind, peak_workout_id)
+);
@@ -0,0 +1,11 @@
+-- the bar and plates a user has, one inventory per user. plates is a JSON list of
+-- weight and count pairs.
+CREATE TABLE plate_inventories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL UNIQUE,
+	unit TEXT NOT NULL,
+	bar_weight REAL NOT NULL,
+	plates TEXT NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
@@ -0,0 +1,12 @@
+-- which users a coach may read the training of. Coach routes answer 404 for anyone
+-- not listed here.
+CREATE TABLE coach_clients (
+	coach_id INTEGER NOT NULL,
+	client_id INTEGER NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (coach_id, client_id),
+	FOREIGN KEY (coach_id) REFERENCES users (id),
+	FOREIGN KEY (client_id) REFERENCES users (id)
+);
+
+CREATE INDEX idx_coach_clients_client ON coach_clients (client_id);
@@ -0,0 +1,2 @@
+UPDATE user_profiles SET target_weight = 0 WHERE target_weight IS NULL;
+UPDATE user_profiles SET bodyfat = 0 WHERE bodyfat IS NULL;
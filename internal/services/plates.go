@@ -0,0 +1,109 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/plates"
+	"time"
+)
+
+// PlateService works out bar loading and warm-ups from a user's plate inventory.
+type PlateService struct {
+	dbService Store
+}
+
+func NewPlateService(dbService Store) *PlateService {
+	return &PlateService{dbService: dbService}
+}
+
+// Calculate returns the plates that load target and the warm-up sets for working sets
+// of workReps reps. unit and barWeight override the user's inventory when not empty
+// and not nil; a unit other than the inventory's switches to the default plates of
+// that unit.
+func (p *PlateService) Calculate(userID int, target float64, workReps int, unit string, barWeight *float64) (*models.PlateCalculation, error) {
+	inventory, err := plateInventory(p.dbService, userID)
+	if err != nil {
+		return nil, err
+	}
+	if unit != "" && unit != inventory.Unit {
+		inventory = models.DefaultPlateInventory(unit)
+	}
+	if barWeight != nil {
+		inventory.BarWeight = *barWeight
+	}
+	return &models.PlateCalculation{PlateLoad: plates.Load(target, inventory), Warmups: plates.Warmups(target, workReps, inventory)}, nil
+}
+
+// plateInventory returns the user's plate inventory, or the default kg one when they
+// did not save any.
+func plateInventory(store Store, userID int) (models.PlateInventory, error) {
+	inventory, err := store.GetPlateInventory(userID)
+	if errors.Is(err, ErrNotFound) {
+		return models.DefaultPlateInventory(models.UnitKg), nil
+	}
+	if err != nil {
+		return models.PlateInventory{}, err
+	}
+	return *inventory, nil
+}
+
+// GetPlateInventory returns the plate inventory of the user, or ErrNotFound if they
+// did not save one.
+func (s *DatabaseService) GetPlateInventory(userID int) (*models.PlateInventory, error) {
+	var inventory models.PlateInventory
+	var plates string
+	var updatedAt time.Time
+	err := s.db.QueryRow(
+		"SELECT unit, bar_weight, plates, updated_at FROM plate_inventories WHERE user_id = ?", userID,
+	).Scan(&inventory.Unit, &inventory.BarWeight, &plates, &updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(plates), &inventory.Plates); err != nil {
+		return nil, err
+	}
+	inventory.UpdatedAt = &updatedAt
+	return &inventory, nil
+}
+
+// SavePlateInventory creates or replaces the plate inventory of the user.
+func (s *DatabaseService) SavePlateInventory(userID int, inventory *models.PlateInventory) error {
+	plates, err := json.Marshal(inventory.Plates)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.Exec(
+		"UPDATE plate_inventories SET unit = ?, bar_weight = ?, plates = ?, updated_at = ? WHERE user_id = ?",
+		inventory.Unit, inventory.BarWeight, string(plates), now, userID,
+	)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		_, err := tx.Exec(
+			"INSERT INTO plate_inventories (user_id, unit, bar_weight, plates, updated_at) VALUES (?, ?, ?, ?, ?)",
+			userID, inventory.Unit, inventory.BarWeight, string(plates), now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	inventory.UpdatedAt = &now
+	return tx.Commit()
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// ErrInvalidMeasurement wraps the reason a measurement was rejected by
+// models.ValidateMeasurement.
+var ErrInvalidMeasurement = errors.New("invalid measurement")
+
+// MeasurementMetrics lists the body measurement fields a trend can be computed for.
+var MeasurementMetrics = []string{"weight", "bodyfat", "neck", "chest", "waist", "hips", "arm", "thigh", "calf"}
+
+// GetUserProfile returns the profile of a user, or ErrNotFound if none was saved yet.
+func (s *DatabaseService) GetUserProfile(userID int) (*models.UserProfile, error) {
+	var profile models.UserProfile
+	var bodyfat sql.NullFloat64
+	var targetWeight sql.NullInt64
+	err := s.db.QueryRow(`
+		SELECT id, user_id, height, weight, bodyfat, target_weight, sex, updated_at
+		FROM user_profiles WHERE user_id = ? ORDER BY id DESC LIMIT 1
+	`, userID).Scan(&profile.ID, &profile.UserID, &profile.Height, &profile.Weight, &bodyfat, &targetWeight, &profile.Sex, &profile.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	profile.Bodyfat = bodyfat.Float64
+	profile.TargetWeight = int(targetWeight.Int64)
+	return &profile, nil
+}
+
+// SaveUserProfile creates or updates the profile of a user. When weight or bodyfat
+// changed a measurement entry is appended too, so the profile always mirrors the
+// latest values while the history is kept.
+func (s *DatabaseService) SaveUserProfile(userID int, profile *models.UserProfile) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var existingID, existingWeight int
+	var existingBodyfat sql.NullFloat64
+	err = tx.QueryRow(
+		"SELECT id, weight, bodyfat FROM user_profiles WHERE user_id = ? ORDER BY id DESC LIMIT 1", userID,
+	).Scan(&existingID, &existingWeight, &existingBodyfat)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	//bodyfat and target weight are optional and stored as NULL when not given
+	var bodyfat, targetWeight interface{}
+	if profile.Bodyfat > 0 {
+		bodyfat = profile.Bodyfat
+	}
+	if profile.TargetWeight > 0 {
+		targetWeight = profile.TargetWeight
+	}
+	if err == sql.ErrNoRows {
+		id, err := tx.insertID(
+			"INSERT INTO user_profiles (user_id, height, weight, bodyfat, target_weight, sex) VALUES (?, ?, ?, ?, ?, ?)",
+			userID, profile.Height, profile.Weight, bodyfat, targetWeight, profile.Sex,
+		)
+		if err != nil {
+			return err
+		}
+		profile.ID = id
+	} else {
+		_, err := tx.Exec(
+			"UPDATE user_profiles SET height = ?, weight = ?, bodyfat = ?, target_weight = ?, sex = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+			profile.Height, profile.Weight, bodyfat, targetWeight, profile.Sex, existingID,
+		)
+		if err != nil {
+			return err
+		}
+		profile.ID = existingID
+	}
+	profile.UserID = userID
+
+	weightChanged := profile.Weight > 0 && profile.Weight != existingWeight
+	bodyfatChanged := profile.Bodyfat > 0 && profile.Bodyfat != existingBodyfat.Float64
+	if weightChanged || bodyfatChanged {
+		measurement := models.BodyMeasurement{MeasuredAt: time.Now().UTC()}
+		if profile.Weight > 0 {
+			weight := float64(profile.Weight)
+			measurement.Weight = &weight
+		}
+		if profile.Bodyfat > 0 {
+			bodyfat := profile.Bodyfat
+			measurement.Bodyfat = &bodyfat
+		}
+		if err := insertMeasurement(tx, userID, &measurement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// AddMeasurement appends a body measurement entry for a user, or returns
+// ErrInvalidMeasurement if models.ValidateMeasurement rejects it.
+func (s *DatabaseService) AddMeasurement(userID int, measurement *models.BodyMeasurement) error {
+	if err := models.ValidateMeasurement(measurement); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMeasurement, err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if measurement.MeasuredAt.IsZero() {
+		measurement.MeasuredAt = time.Now().UTC()
+	}
+	if err := insertMeasurement(tx, userID, measurement); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetMeasurements returns a user's measurements between from and to (either may be
+// zero for an open range), oldest first.
+func (s *DatabaseService) GetMeasurements(userID int, from, to time.Time) ([]models.BodyMeasurement, error) {
+	query := `
+		SELECT id, user_id, measured_at, weight, bodyfat, neck, chest, waist, hips, arm, thigh, calf, notes
+		FROM body_measurements WHERE user_id = ?`
+	args := []interface{}{userID}
+	if !from.IsZero() {
+		query += " AND measured_at >= ?"
+		args = append(args, from.UTC())
+	}
+	if !to.IsZero() {
+		query += " AND measured_at <= ?"
+		args = append(args, to.UTC())
+	}
+	query += " ORDER BY measured_at, id"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	measurements := []models.BodyMeasurement{}
+	for rows.Next() {
+		var m models.BodyMeasurement
+		var weight, bodyfat, neck, chest, waist, hips, arm, thigh, calf sql.NullFloat64
+		err := rows.Scan(&m.ID, &m.UserID, &m.MeasuredAt, &weight, &bodyfat, &neck, &chest, &waist, &hips, &arm, &thigh, &calf, &m.Notes)
+		if err != nil {
+			return nil, err
+		}
+		m.Weight, m.Bodyfat, m.Neck = nullableFloat(weight), nullableFloat(bodyfat), nullableFloat(neck)
+		m.Chest, m.Waist, m.Hips = nullableFloat(chest), nullableFloat(waist), nullableFloat(hips)
+		m.Arm, m.Thigh, m.Calf = nullableFloat(arm), nullableFloat(thigh), nullableFloat(calf)
+		measurements = append(measurements, m)
+	}
+	return measurements, rows.Err()
+}
+
+// DeleteMeasurement removes a single measurement entry owned by userID.
+func (s *DatabaseService) DeleteMeasurement(userID, measurementID int) error {
+	result, err := s.db.Exec("DELETE FROM body_measurements WHERE id = ? AND user_id = ?", measurementID, userID)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetMeasurementTrend buckets one metric of a user's measurements by period.
+func (s *DatabaseService) GetMeasurementTrend(userID int, metric, period string, from, to time.Time) (*models.MeasurementTrend, error) {
+	measurements, err := s.GetMeasurements(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := &models.MeasurementTrend{Metric: metric, Period: period, Points: []models.MeasurementPoint{}}
+	var first, last *float64
+	for _, m := range measurements {
+		value, err := measurementValue(m, metric)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		if first == nil {
+			first = value
+		}
+		last = value
+
+		start := analytics.PeriodStart(m.MeasuredAt, period)
+		n := len(trend.Points)
+		if n == 0 || !trend.Points[n-1].PeriodStart.Equal(start) {
+			trend.Points = append(trend.Points, models.MeasurementPoint{PeriodStart: start, Min: *value, Max: *value})
+			n++
+		}
+		point := &trend.Points[n-1]
+		point.Average = (point.Average*float64(point.Count) + *value) / float64(point.Count+1)
+		point.Count++
+		if *value < point.Min {
+			point.Min = *value
+		}
+		if *value > point.Max {
+			point.Max = *value
+		}
+	}
+	for i := range trend.Points {
+		trend.Points[i].Average = analytics.Round(trend.Points[i].Average, 0.01)
+	}
+	if first != nil {
+		trend.Change = analytics.Round(*last-*first, 0.01)
+	}
+	return trend, nil
+}
+
+func insertMeasurement(tx *Tx, userID int, m *models.BodyMeasurement) error {
+	id, err := tx.insertID(`
+		INSERT INTO body_measurements (user_id, measured_at, weight, bodyfat, neck, chest, waist, hips, arm, thigh, calf, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, userID, m.MeasuredAt.UTC(), m.Weight, m.Bodyfat, m.Neck, m.Chest, m.Waist, m.Hips, m.Arm, m.Thigh, m.Calf, m.Notes)
+	if err != nil {
+		return err
+	}
+	m.ID = id
+	m.UserID = userID
+	return nil
+}
+
+func measurementValue(m models.BodyMeasurement, metric string) (*float64, error) {
+	switch metric {
+	case "weight":
+		return m.Weight, nil
+	case "bodyfat":
+		return m.Bodyfat, nil
+	case "neck":
+		return m.Neck, nil
+	case "chest":
+		return m.Chest, nil
+	case "waist":
+		return m.Waist, nil
+	case "hips":
+		return m.Hips, nil
+	case "arm":
+		return m.Arm, nil
+	case "thigh":
+		return m.Thigh, nil
+	case "calf":
+		return m.Calf, nil
+	default:
+		return nil, fmt.Errorf("unknown metric: %s", metric)
+	}
+}
+
+func nullableFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/programs"
+	"time"
+)
+
+var (
+	// ErrUnknownProgram is returned when enrolling in a program that is not in the library.
+	ErrUnknownProgram = errors.New("unknown program")
+	// ErrTrainingMaxRequired is returned when a lift has neither a given training max
+	// nor any logged history to estimate one from.
+	ErrTrainingMaxRequired = errors.New("training max required")
+	// ErrSessionRecorded is returned when a workout already completed a session of the program.
+	ErrSessionRecorded = errors.New("workout already completed a program session")
+)
+
+// ProgramService enrolls users in training programs and advances them with the
+// workouts they log.
+type ProgramService struct {
+	dbService Store
+}
+
+func NewProgramService(dbService Store) *ProgramService {
+	return &ProgramService{dbService: dbService}
+}
+
+// Enroll starts the program with key for the user, ending any program they were
+// running. trainingMaxes is keyed by lift; lifts missing from it start at the
+// program's percentage of the best estimated one-rep max in the user's history.
+func (p *ProgramService) Enroll(userID int, key string, trainingMaxes map[string]float64) (*models.ActiveProgram, error) {
+	program, ok := programs.Lookup(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProgram, key)
+	}
+	for lift, trainingMax := range trainingMaxes {
+		if _, ok := program.Lift(lift); !ok {
+			return nil, fmt.Errorf("%w: %s has no lift %s", ErrUnknownProgram, key, lift)
+		}
+		if trainingMax <= 0 {
+			return nil, fmt.Errorf("%w: training max of %s must be greater than 0", ErrTrainingMaxRequired, lift)
+		}
+	}
+
+	enrollment := &models.ProgramEnrollment{ProgramKey: program.Key, Cycle: 1, Week: 1, Day: 1}
+	//several lifts can share an exercise, so every history is only estimated once
+	oneRepMaxes := make(map[string]float64)
+	for _, lift := range program.Lifts {
+		exercise, err := p.dbService.ResolveExercise(userID, lift.Exercise)
+		if err != nil {
+			return nil, err
+		}
+
+		trainingMax, ok := trainingMaxes[lift.Key]
+		if !ok {
+			oneRepMax, seen := oneRepMaxes[exercise.Name]
+			if !seen {
+				history, err := p.dbService.GetSetHistory(userID, exercise.Name)
+				if err != nil {
+					return nil, err
+				}
+				estimate, _ := estimateFromSets(history, analytics.Epley)
+				oneRepMax = estimate.EstimatedOneRepMax
+				oneRepMaxes[exercise.Name] = oneRepMax
+			}
+			if oneRepMax == 0 {
+				return nil, fmt.Errorf("%w: no history for %s, give a training max for %s", ErrTrainingMaxRequired, exercise.Name, lift.Key)
+			}
+			trainingMax = analytics.Round(oneRepMax*program.LiftTrainingMaxPercent(lift)/100, program.Rounding)
+		}
+
+		enrollment.Lifts = append(enrollment.Lifts, models.ProgramLiftState{
+			Lift:        lift.Key,
+			CatalogID:   exercise.ID,
+			Exercise:    exercise.Name,
+			TrainingMax: trainingMax,
+		})
+	}
+
+	if err := p.dbService.StartProgram(userID, enrollment); err != nil {
+		return nil, err
+	}
+	return &models.ActiveProgram{Enrollment: *enrollment, Next: program.Prescribe(enrollment)}, nil
+}
+
+// Active returns the user's current enrollment and the session they train next, or
+// ErrNotFound when they are not running a program.
+func (p *ProgramService) Active(userID int) (*models.ActiveProgram, error) {
+	enrollment, program, err := p.activeProgram(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ActiveProgram{Enrollment: *enrollment, Next: program.Prescribe(enrollment)}, nil
+}
+
+// CompleteSession counts a logged workout as the user's next program session. The
+// sets of the workout are judged against the prescription, training maxes and set
+// schemes are progressed and the enrollment moves on to the following session.
+func (p *ProgramService) CompleteSession(userID, workoutID int) (*models.ProgramSessionResult, error) {
+	enrollment, program, err := p.activeProgram(userID)
+	if err != nil {
+		return nil, err
+	}
+	workout, err := p.dbService.GetWorkout(userID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	performed := make(map[int][]models.Set)
+	for _, exercise := range workout.Exercises {
+		if exercise.CatalogID != 0 {
+			performed[exercise.CatalogID] = append(performed[exercise.CatalogID], exercise.Sets...)
+		}
+	}
+
+	completed := program.Prescribe(enrollment)
+	progress := program.Complete(enrollment, performed)
+	if err := p.dbService.RecordProgramSession(userID, workoutID, completed, enrollment); err != nil {
+		return nil, err
+	}
+	return &models.ProgramSessionResult{
+		Completed: completed,
+		Progress:  progress,
+		Next:      program.Prescribe(enrollment),
+	}, nil
+}
+
+func (p *ProgramService) activeProgram(userID int) (*models.ProgramEnrollment, *programs.Program, error) {
+	enrollment, err := p.dbService.GetActiveProgram(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	program, ok := programs.Lookup(enrollment.ProgramKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s is no longer available", ErrUnknownProgram, enrollment.ProgramKey)
+	}
+	return enrollment, program, nil
+}
+
+// StartProgram stores a new enrollment with its lift states and ends the user's
+// previous one, if any.
+func (s *DatabaseService) StartProgram(userID int, enrollment *models.ProgramEnrollment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec("UPDATE program_enrollments SET ended_at = ? WHERE user_id = ? AND ended_at IS NULL", now, userID); err != nil {
+		return err
+	}
+	enrollmentID, err := tx.insertID(
+		"INSERT INTO program_enrollments (user_id, program_key, cycle, week, day, started_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		userID, enrollment.ProgramKey, enrollment.Cycle, enrollment.Week, enrollment.Day, now, now,
+	)
+	if err != nil {
+		return err
+	}
+	for _, lift := range enrollment.Lifts {
+		_, err := tx.Exec(
+			"INSERT INTO program_lift_states (enrollment_id, lift, catalog_id, exercise, training_max, stage, failures) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			enrollmentID, lift.Lift, lift.CatalogID, lift.Exercise, lift.TrainingMax, lift.Stage, lift.Failures,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	enrollment.ID = enrollmentID
+	enrollment.UserID = userID
+	enrollment.StartedAt = now
+	enrollment.UpdatedAt = now
+
+	return tx.Commit()
+}
+
+// GetActiveProgram returns the user's current enrollment with its lift states, or
+// ErrNotFound when they are not running a program.
+func (s *DatabaseService) GetActiveProgram(userID int) (*models.ProgramEnrollment, error) {
+	var enrollment models.ProgramEnrollment
+	err := s.db.QueryRow(`
+		SELECT id, user_id, program_key, cycle, week, day, started_at, updated_at FROM program_enrollments
+		WHERE user_id = ? AND ended_at IS NULL
+		ORDER BY id DESC LIMIT 1
+	`, userID).Scan(&enrollment.ID, &enrollment.UserID, &enrollment.ProgramKey, &enrollment.Cycle, &enrollment.Week, &enrollment.Day, &enrollment.StartedAt, &enrollment.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		"SELECT lift, catalog_id, exercise, training_max, stage, failures FROM program_lift_states WHERE enrollment_id = ? ORDER BY id",
+		enrollment.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	enrollment.Lifts = []models.ProgramLiftState{}
+	for rows.Next() {
+		var lift models.ProgramLiftState
+		if err := rows.Scan(&lift.Lift, &lift.CatalogID, &lift.Exercise, &lift.TrainingMax, &lift.Stage, &lift.Failures); err != nil {
+			return nil, err
+		}
+		enrollment.Lifts = append(enrollment.Lifts, lift)
+	}
+	return &enrollment, rows.Err()
+}
+
+// RecordProgramSession stores the state of an enrollment after workoutID completed
+// the session at the position of completed. It returns ErrSessionRecorded when the
+// workout already completed a session of the enrollment.
+func (s *DatabaseService) RecordProgramSession(userID, workoutID int, completed models.PrescribedSession, enrollment *models.ProgramEnrollment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM program_sessions WHERE enrollment_id = ? AND workout_id = ?", enrollment.ID, workoutID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSessionRecorded
+	}
+
+	now := time.Now().UTC()
+	result, err := tx.Exec(
+		"UPDATE program_enrollments SET cycle = ?, week = ?, day = ?, updated_at = ? WHERE id = ? AND user_id = ? AND ended_at IS NULL",
+		enrollment.Cycle, enrollment.Week, enrollment.Day, now, enrollment.ID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrNotFound
+	}
+	for _, lift := range enrollment.Lifts {
+		_, err := tx.Exec(
+			"UPDATE program_lift_states SET training_max = ?, stage = ?, failures = ? WHERE enrollment_id = ? AND lift = ?",
+			lift.TrainingMax, lift.Stage, lift.Failures, enrollment.ID, lift.Lift,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(
+		"INSERT INTO program_sessions (enrollment_id, workout_id, cycle, week, day, completed_at) VALUES (?, ?, ?, ?, ?, ?)",
+		enrollment.ID, workoutID, completed.Cycle, completed.Week, completed.Day, now,
+	)
+	if err != nil {
+		return err
+	}
+	enrollment.UpdatedAt = now
+
+	return tx.Commit()
+}
+
+// EndProgram stops the user's current program. Its history is kept.
+func (s *DatabaseService) EndProgram(userID int) error {
+	result, err := s.db.Exec("UPDATE program_enrollments SET ended_at = ? WHERE user_id = ? AND ended_at IS NULL", time.Now().UTC(), userID)
+	if err != nil {
+		return err
+	}
+	ended, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if ended == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
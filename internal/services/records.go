@@ -0,0 +1,245 @@
+package services
+
+import (
+	"fmt"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// RecordService detects and stores personal records when workouts are logged and
+// keeps them in line with the history when workouts are edited or deleted.
+type RecordService struct {
+	dbService AnalyticsRepository
+}
+
+func NewRecordService(dbService AnalyticsRepository) *RecordService {
+	return &RecordService{dbService: dbService}
+}
+
+// RecordPersonalRecords recomputes the records of the exercises in a freshly saved
+// workout and returns the ones it broke. Sessions logged after it are compared
+// against it too, so back-logging an older workout can take records from them.
+func (r *RecordService) RecordPersonalRecords(userID int, workout *models.Workout) ([]models.PersonalRecord, error) {
+	names := make([]string, len(workout.Exercises))
+	for i, exercise := range workout.Exercises {
+		names[i] = exercise.Exercise
+	}
+	records, err := r.recompute(userID, names)
+	if err != nil {
+		return nil, err
+	}
+	var broken []models.PersonalRecord
+	for _, record := range records {
+		if record.WorkoutID == workout.ID {
+			broken = append(broken, record)
+		}
+	}
+	return broken, nil
+}
+
+// RefreshPersonalRecords recomputes the records of the given exercises from the
+// history, for after sets of them were edited or deleted.
+func (r *RecordService) RefreshPersonalRecords(userID int, exercises []string) error {
+	_, err := r.recompute(userID, exercises)
+	return err
+}
+
+// recompute replays the history of each exercise session by session, comparing every
+// session only with the ones before it, and replaces the stored records of the
+// exercises with the result.
+func (r *RecordService) recompute(userID int, exercises []string) ([]models.PersonalRecord, error) {
+	var records []models.PersonalRecord
+	var names []string
+	seen := make(map[string]bool)
+	for _, exercise := range exercises {
+		key := exerciseKey(exercise)
+		if exercise == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, exercise)
+
+		history, err := r.dbService.GetSetHistory(userID, exercise)
+		if err != nil {
+			return nil, err
+		}
+		//aliases share a history, so every name logged in it is covered from here on
+		for _, set := range history {
+			if !seen[exerciseKey(set.Exercise)] {
+				seen[exerciseKey(set.Exercise)] = true
+				names = append(names, set.Exercise)
+			}
+		}
+		//the history is ordered by date, so each session starts where the previous one ended
+		for start := 0; start < len(history); {
+			end := start
+			for end < len(history) && history[end].WorkoutID == history[start].WorkoutID {
+				end++
+			}
+			records = append(records, analytics.DetectRecords(history[:start], history[start:end])...)
+			start = end
+		}
+	}
+
+	for i := range records {
+		records[i].UserID = userID
+	}
+	if err := r.dbService.ReplacePersonalRecords(userID, names, records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ReplacePersonalRecords makes the user's records of the named exercises equal to
+// records in a single transaction, filling in their ids. Records that are already
+// stored keep their row and id, so only the ones that changed are deleted or added.
+func (s *DatabaseService) ReplacePersonalRecords(userID int, exercises []string, records []models.PersonalRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	//ids of the stored records by their content; names differing only in case share rows
+	stored := make(map[string][]int)
+	seen := make(map[int]bool)
+	for _, exercise := range exercises {
+		rows, err := tx.Query(`
+			SELECT id, workout_id, COALESCE(set_id, 0), exercise, record_type, COALESCE(reps, 0), COALESCE(weight, 0), value, previous_value, achieved_at
+			FROM personal_records WHERE user_id = ? AND lower(exercise) = lower(?) ORDER BY id
+		`, userID, exercise)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var record models.PersonalRecord
+			err := rows.Scan(&record.ID, &record.WorkoutID, &record.SetID, &record.Exercise, &record.RecordType, &record.Reps, &record.Weight, &record.Value, &record.PreviousValue, &record.AchievedAt)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			if !seen[record.ID] {
+				seen[record.ID] = true
+				stored[recordKey(record)] = append(stored[recordKey(record)], record.ID)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	for i, record := range records {
+		key := recordKey(record)
+		if ids := stored[key]; len(ids) > 0 {
+			records[i].ID = ids[0]
+			stored[key] = ids[1:]
+			continue
+		}
+		var setID interface{}
+		if record.SetID != 0 {
+			setID = record.SetID
+		}
+		id, err := tx.insertID(`
+			INSERT INTO personal_records (user_id, workout_id, set_id, exercise, record_type, reps, weight, value, previous_value, achieved_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, userID, record.WorkoutID, setID, record.Exercise, record.RecordType, record.Reps, record.Weight, record.Value, record.PreviousValue, record.AchievedAt)
+		if err != nil {
+			return err
+		}
+		records[i].ID = id
+	}
+	for _, ids := range stored {
+		for _, id := range ids {
+			if _, err := tx.Exec("DELETE FROM personal_records WHERE id = ?", id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// recordKey identifies a record by everything but its id. Times are compared to the
+// microsecond, which is all postgres keeps.
+func recordKey(record models.PersonalRecord) string {
+	return fmt.Sprintf("%d|%d|%s|%s|%d|%v|%v|%v|%s", record.WorkoutID, record.SetID, record.Exercise, record.RecordType, record.Reps,
+		record.Weight, record.Value, record.PreviousValue, record.AchievedAt.UTC().Round(time.Microsecond).Format(time.RFC3339Nano))
+}
+
+// GetPersonalRecords returns the PR history of a user, newest first, optionally
+// narrowed to one exercise and/or record type.
+func (s *DatabaseService) GetPersonalRecords(userID int, exercise, recordType string) ([]models.PersonalRecord, error) {
+	query := `
+		SELECT id, user_id, workout_id, COALESCE(set_id, 0), exercise, record_type, COALESCE(reps, 0), COALESCE(weight, 0), value, previous_value, achieved_at
+		FROM personal_records WHERE user_id = ?`
+	args := []interface{}{userID}
+	if exercise != "" {
+		query += " AND lower(exercise) = lower(?)"
+		args = append(args, exercise)
+	}
+	if recordType != "" {
+		query += " AND record_type = ?"
+		args = append(args, recordType)
+	}
+	query += " ORDER BY achieved_at DESC, id DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []models.PersonalRecord{}
+	for rows.Next() {
+		var record models.PersonalRecord
+		err := rows.Scan(&record.ID, &record.UserID, &record.WorkoutID, &record.SetID, &record.Exercise, &record.RecordType, &record.Reps, &record.Weight, &record.Value, &record.PreviousValue, &record.AchievedAt)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// GetVolumeLandmarks returns the landmarks the user set for their muscles, without
+// the defaults of the others.
+func (s *DatabaseService) GetVolumeLandmarks(userID int) ([]models.VolumeLandmark, error) {
+	rows, err := s.db.Query("SELECT muscle, mev, mrv FROM volume_landmarks WHERE user_id = ? ORDER BY muscle", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	landmarks := []models.VolumeLandmark{}
+	for rows.Next() {
+		landmark := models.VolumeLandmark{Custom: true}
+		if err := rows.Scan(&landmark.Muscle, &landmark.MEV, &landmark.MRV); err != nil {
+			return nil, err
+		}
+		landmarks = append(landmarks, landmark)
+	}
+	return landmarks, rows.Err()
+}
+
+// SaveVolumeLandmarks replaces the landmarks the user set with landmarks. Muscles
+// left out fall back to their defaults.
+func (s *DatabaseService) SaveVolumeLandmarks(userID int, landmarks []models.VolumeLandmark) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM volume_landmarks WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	for _, landmark := range landmarks {
+		_, err := tx.Exec("INSERT INTO volume_landmarks (user_id, muscle, mev, mrv) VALUES (?, ?, ?, ?)", userID, landmark.Muscle, landmark.MEV, landmark.MRV)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"the-gym-app/internal/models"
+	"time"
+)
+
+// UserRepository stores accounts and their roles.
+type UserRepository interface {
+	CheckIfUserExists(username string) (bool, error)
+	FetchPassword(username string) (string, error)
+	SaveUser(user *models.User) error
+	GetUserIdFromUsername(username string) (int, error)
+	GetUserRoles(username string) ([]string, error)
+	SetUserRoles(userID int, roles []string) error
+	ListUsers() ([]models.User, error)
+}
+
+// TokenRepository stores refresh token families and revoked access tokens.
+type TokenRepository interface {
+	SaveRefreshToken(userID int, token, familyID string, expiresAt time.Time) error
+	RotateRefreshToken(oldToken, newToken string, expiresAt time.Time) (*RefreshToken, error)
+	RevokeRefreshTokenFamily(userID int, token string) error
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	IsTokenRevoked(jti string) (bool, error)
+}
+
+// CoachRepository stores which clients each coach may read the training of.
+type CoachRepository interface {
+	AssignClient(coachID, clientID int) error
+	UnassignClient(coachID, clientID int) error
+	GetClients(coachID int) ([]models.User, error)
+	IsCoachOf(coachID, clientID int) (bool, error)
+}
+
+// WorkoutRepository stores workouts with their exercises and sets. Every method is
+// scoped to the owning user and returns ErrNotFound for anything the user does not own.
+// Exercises are resolved against the catalog on write; names that match no catalog
+// entry are stored unlinked and ErrUnknownExercise is only returned for catalog ids
+// the user cannot see.
+type WorkoutRepository interface {
+	SaveWorkout(userID int, workout *models.Workout) error
+	GetWorkouts(userID int) ([]models.Workout, error)
+	StreamWorkouts(userID int, fn func(*models.Workout) error) error
+	ListWorkouts(userID int, query models.WorkoutQuery) (*models.WorkoutPage, error)
+	ListWorkoutSummaries(userID int, query models.WorkoutQuery) (*models.WorkoutSummaryPage, error)
+	GetWorkout(userID, workoutID int) (*models.Workout, error)
+	ReplaceWorkout(userID, workoutID int, workout *models.Workout) error
+	PatchWorkout(userID, workoutID int, patch *models.WorkoutPatch) error
+	DeleteWorkout(userID, workoutID int) error
+
+	GetExercise(userID, workoutID, exerciseID int) (*models.ExerciseLog, error)
+	ReplaceExercise(userID, workoutID, exerciseID int, exercise *models.ExerciseLog) error
+	PatchExercise(userID, workoutID, exerciseID int, patch *models.ExercisePatch) error
+	DeleteExercise(userID, workoutID, exerciseID int) error
+
+	GetSet(userID, workoutID, exerciseID, setID int) (*models.Set, error)
+	ReplaceSet(userID, workoutID, exerciseID, setID int, set *models.Set) error
+	PatchSet(userID, workoutID, exerciseID, setID int, patch *models.SetPatch) error
+	DeleteSet(userID, workoutID, exerciseID, setID int) error
+
+	GetLastPerformance(userID, catalogID int) (*models.ExerciseLog, error)
+}
+
+// TemplateRepository stores workout templates with their planned exercises.
+type TemplateRepository interface {
+	SaveTemplate(userID int, template *models.WorkoutTemplate) error
+	GetTemplates(userID int) ([]models.WorkoutTemplate, error)
+	GetTemplate(userID, templateID int) (*models.WorkoutTemplate, error)
+	ReplaceTemplate(userID, templateID int, template *models.WorkoutTemplate) error
+	DeleteTemplate(userID, templateID int) error
+}
+
+// ProgramRepository stores training program enrollments and the progression state
+// of their lifts. A user has at most one active enrollment.
+type ProgramRepository interface {
+	StartProgram(userID int, enrollment *models.ProgramEnrollment) error
+	GetActiveProgram(userID int) (*models.ProgramEnrollment, error)
+	RecordProgramSession(userID, workoutID int, completed models.PrescribedSession, enrollment *models.ProgramEnrollment) error
+	EndProgram(userID int) error
+}
+
+// CatalogRepository stores the exercise library and users' custom exercises.
+type CatalogRepository interface {
+	ListCatalogExercises(userID int, filter models.CatalogFilter) ([]models.CatalogExercise, error)
+	GetCatalogExercise(userID, catalogID int) (*models.CatalogExercise, error)
+	ResolveExercise(userID int, name string) (*models.CatalogExercise, error)
+	CreateCustomExercise(userID int, exercise *models.CatalogExercise) error
+	DeleteCustomExercise(userID, catalogID int) error
+}
+
+// ProfileRepository stores user profiles and the body measurement history.
+type ProfileRepository interface {
+	GetUserProfile(userID int) (*models.UserProfile, error)
+	SaveUserProfile(userID int, profile *models.UserProfile) error
+	AddMeasurement(userID int, measurement *models.BodyMeasurement) error
+	GetMeasurements(userID int, from, to time.Time) ([]models.BodyMeasurement, error)
+	DeleteMeasurement(userID, measurementID int) error
+	GetMeasurementTrend(userID int, metric, period string, from, to time.Time) (*models.MeasurementTrend, error)
+}
+
+// AnalyticsRepository is the read side used for maxes and personal records, plus the
+// volume landmarks users set for their muscles.
+type AnalyticsRepository interface {
+	GetSetRep(userID int, exercise string, reps int) (models.SetRep, error)
+	GetSetHistory(userID int, exercise string) ([]models.LoggedSet, error)
+	ReplacePersonalRecords(userID int, exercises []string, records []models.PersonalRecord) error
+	GetPersonalRecords(userID int, exercise, recordType string) ([]models.PersonalRecord, error)
+	GetVolumeLandmarks(userID int) ([]models.VolumeLandmark, error)
+	SaveVolumeLandmarks(userID int, landmarks []models.VolumeLandmark) error
+}
+
+// ImportRepository stores workouts imported from other apps, remembering which
+// sessions of a source were imported already.
+type ImportRepository interface {
+	ImportWorkouts(userID int, source string, sessions []models.ImportedSession) (workoutIDs []int, duplicates int, err error)
+	GetImportedSessionKeys(userID int, source string) (map[string]bool, error)
+}
+
+// InsightRepository stores the plateaus and regressions found in users' training.
+type InsightRepository interface {
+	SaveInsights(userID int, insights []models.Insight, now time.Time) error
+	GetInsights(userID int, filter models.InsightFilter) ([]models.Insight, error)
+}
+
+// PlateRepository stores the bar and plates users load.
+type PlateRepository interface {
+	GetPlateInventory(userID int) (*models.PlateInventory, error)
+	SavePlateInventory(userID int, inventory *models.PlateInventory) error
+}
+
+// Store is everything the API needs from a storage backend. DatabaseService
+// implements it for both SQLite and Postgres.
+type Store interface {
+	UserRepository
+	TokenRepository
+	CoachRepository
+	WorkoutRepository
+	TemplateRepository
+	ProgramRepository
+	CatalogRepository
+	ProfileRepository
+	AnalyticsRepository
+	ImportRepository
+	InsightRepository
+	PlateRepository
+}
+
+var _ Store = (*DatabaseService)(nil)
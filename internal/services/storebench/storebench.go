@@ -0,0 +1,162 @@
+// Package storebench measures how the workout read path of a services.Store scales
+// with the size of a user's history. It backs the bench command and the query count
+// test and benchmarks of this package.
+package storebench
+
+import (
+	"fmt"
+	"strings"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+	"time"
+)
+
+// Store is a services.Store that counts the statements it runs.
+type Store interface {
+	services.Store
+	QueryCount() int64
+}
+
+// Result is the cost of one call against a history of Workouts workouts.
+type Result struct {
+	Call     string
+	Workouts int
+	Queries  int64
+	PerOp    time.Duration
+}
+
+// call is a read measured by the bench. workoutID is a workout of the history.
+// Calls that read the whole history in pages of pageSize workouts may run more
+// queries per page, but not per workout.
+type call struct {
+	name     string
+	pageSize int
+	run      func(store Store, userID, workoutID int) error
+}
+
+// perPage returns the queries of one page for a paged call that ran queries against
+// a history of workouts, and queries unchanged otherwise.
+func (c call) perPage(queries int64, workouts int) int64 {
+	if c.pageSize == 0 || workouts <= c.pageSize {
+		return queries
+	}
+	pages := (workouts + c.pageSize - 1) / c.pageSize
+	return queries / int64(pages)
+}
+
+var calls = []call{
+	{"GetWorkouts", 0, func(store Store, userID, _ int) error {
+		_, err := store.GetWorkouts(userID)
+		return err
+	}},
+	{"GetWorkout", 0, func(store Store, userID, workoutID int) error {
+		_, err := store.GetWorkout(userID, workoutID)
+		return err
+	}},
+	{"ListWorkouts", 0, func(store Store, userID, _ int) error {
+		_, err := store.ListWorkouts(userID, models.WorkoutQuery{Sort: models.SortByDate, Order: models.SortDescending, Limit: models.DefaultPageSize})
+		return err
+	}},
+	{"ListWorkouts exercise", 0, func(store Store, userID, _ int) error {
+		_, err := store.ListWorkouts(userID, models.WorkoutQuery{Exercise: "Squat", Sort: models.SortByName, Order: models.SortAscending, Limit: models.DefaultPageSize})
+		return err
+	}},
+	{"ListWorkoutSummaries", 0, func(store Store, userID, _ int) error {
+		_, err := store.ListWorkoutSummaries(userID, models.WorkoutQuery{Sort: models.SortByDate, Order: models.SortDescending, Limit: models.DefaultPageSize})
+		return err
+	}},
+	{"StreamWorkouts", services.StreamPageSize, func(store Store, userID, _ int) error {
+		return store.StreamWorkouts(userID, func(*models.Workout) error { return nil })
+	}},
+}
+
+// exercises make up every seeded workout, each with setsPerExercise sets.
+var exercises = []string{"Squat", "Bench Press", "Barbell Row", "Overhead Press"}
+
+const setsPerExercise = 4
+
+// Run seeds a history of every size in sizes, which must be positive, for a new user and runs each call runs
+// times against it. It fails when the number of queries of a call changes with the
+// size of the history, which is what an N+1 query looks like, or for paged calls
+// when the queries of a page do.
+func Run(store Store, sizes []int, runs int, logf func(format string, args ...interface{})) ([]Result, error) {
+	var results []Result
+	for _, size := range sizes {
+		userID, workoutID, err := seed(store, size)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range calls {
+			//the first run warms up caches such as prepared statement plans
+			if err := c.run(store, userID, workoutID); err != nil {
+				return nil, fmt.Errorf("%s: %w", c.name, err)
+			}
+			before := store.QueryCount()
+			start := time.Now()
+			for i := 0; i < runs; i++ {
+				if err := c.run(store, userID, workoutID); err != nil {
+					return nil, fmt.Errorf("%s: %w", c.name, err)
+				}
+			}
+			result := Result{
+				Call:     c.name,
+				Workouts: size,
+				Queries:  (store.QueryCount() - before) / int64(runs),
+				PerOp:    time.Since(start) / time.Duration(runs),
+			}
+			logf("%-22s %6d workouts %4d queries %12v/op", result.Call, result.Workouts, result.Queries, result.PerOp)
+			results = append(results, result)
+		}
+	}
+
+	var growing []string
+	for _, c := range calls {
+		var first *Result
+		for i := range results {
+			if results[i].Call != c.name {
+				continue
+			}
+			if first == nil {
+				first = &results[i]
+			} else if c.perPage(results[i].Queries, results[i].Workouts) != c.perPage(first.Queries, first.Workouts) {
+				growing = append(growing, fmt.Sprintf("%s (%d queries for %d workouts, %d for %d)", c.name, first.Queries, first.Workouts, results[i].Queries, results[i].Workouts))
+				break
+			}
+		}
+	}
+	if len(growing) > 0 {
+		return results, fmt.Errorf("query count grows with the history: %s", strings.Join(growing, ", "))
+	}
+	return results, nil
+}
+
+// seed creates a user with size workouts and returns its id and the id of a workout.
+func seed(store Store, size int) (int, int, error) {
+	username := fmt.Sprintf("bench_%d_%d", size, time.Now().UnixNano())
+	if err := store.SaveUser(&models.User{Username: username, Email: username + "@example.com", PasswordHash: "-", FitnessGoal: "strength", ExperienceLevel: "intermediate"}); err != nil {
+		return 0, 0, err
+	}
+	userID, err := store.GetUserIdFromUsername(username)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	start := time.Date(2020, 1, 1, 18, 0, 0, 0, time.UTC)
+	sessions := make([]models.ImportedSession, size)
+	for i := range sessions {
+		workout := models.Workout{Name: fmt.Sprintf("Workout %d", i+1), CreatedAt: start.Add(time.Duration(i) * 48 * time.Hour)}
+		for _, exercise := range exercises {
+			logged := models.ExerciseLog{Exercise: exercise}
+			for set := 0; set < setsPerExercise; set++ {
+				logged.Sets = append(logged.Sets, models.Set{Reps: 5, Weight: float64(60 + i%40 + set*5)})
+			}
+			workout.Exercises = append(workout.Exercises, logged)
+		}
+		sessions[i] = models.ImportedSession{Key: fmt.Sprint(i), Workout: workout}
+	}
+	ids, _, err := store.ImportWorkouts(userID, "bench", sessions)
+	if err != nil {
+		return 0, 0, err
+	}
+	return userID, ids[len(ids)/2], nil
+}
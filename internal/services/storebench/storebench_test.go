@@ -0,0 +1,67 @@
+package storebench
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"the-gym-app/internal/services"
+)
+
+func newStore(tb testing.TB) Store {
+	tb.Helper()
+	dbService, err := services.NewDatabaseService(services.Config{DSN: filepath.Join(tb.TempDir(), "bench.db")})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { dbService.Close() })
+	return dbService
+}
+
+func TestQueryCountDoesNotGrowWithHistory(t *testing.T) {
+	store := newStore(t)
+	const n = 20
+	queries := make(map[string][]int64)
+	for _, size := range []int{n, 10 * n} {
+		userID, workoutID, err := seed(store, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range calls {
+			//warm up first, as Run does
+			if err := c.run(store, userID, workoutID); err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			before := store.QueryCount()
+			if err := c.run(store, userID, workoutID); err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			queries[c.name] = append(queries[c.name], store.QueryCount()-before)
+		}
+	}
+	for _, c := range calls {
+		if counts := queries[c.name]; c.perPage(counts[0], n) != c.perPage(counts[1], 10*n) {
+			t.Errorf("%s ran %d queries for %d workouts and %d for %d", c.name, counts[0], n, counts[1], 10*n)
+		}
+	}
+}
+
+func BenchmarkGetWorkouts(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d workouts", size), func(b *testing.B) {
+			store := newStore(b)
+			userID, _, err := seed(store, size)
+			if err != nil {
+				b.Fatal(err)
+			}
+			before := store.QueryCount()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := store.GetWorkouts(userID); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(store.QueryCount()-before)/float64(b.N), "queries/op")
+		})
+	}
+}
@@ -0,0 +1,1585 @@
+// Package storetest is the conformance suite every services.Store backend has to pass.
+// It lives outside of _test files so the conformance command can run it against any
+// configured database, including a throwaway postgres container.
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/services"
+	"time"
+)
+
+// Check is a single named conformance check.
+type Check struct {
+	Name string
+	Run  func(store services.Store) error
+}
+
+// Checks lists the suite in the order it runs. Every check creates its own users,
+// so checks do not depend on each other.
+var Checks = []Check{
+	{"users", checkUsers},
+	{"tokens", checkTokens},
+	{"coach clients", checkCoachClients},
+	{"workouts", checkWorkouts},
+	{"workout ownership", checkWorkoutOwnership},
+	{"set metadata", checkSetMetadata},
+	{"cardio sets", checkCardioSets},
+	{"exercise groups", checkExerciseGroups},
+	{"imports", checkImports},
+	{"workout stream", checkStreamWorkouts},
+	{"workout history", checkWorkoutHistory},
+	{"exercise catalog", checkCatalog},
+	{"templates", checkTemplates},
+	{"programs", checkPrograms},
+	{"profiles", checkProfiles},
+	{"analytics", checkAnalytics},
+	{"volume landmarks", checkVolumeLandmarks},
+	{"insights", checkInsights},
+	{"plate inventories", checkPlateInventories},
+}
+
+// Run executes every check against store, which must be migrated and empty. It
+// writes data, so never point it at a database you want to keep. logf, when not
+// nil, is told about every check.
+func Run(store services.Store, logf func(format string, args ...interface{})) error {
+	var failed []string
+	for _, check := range Checks {
+		err := check.Run(store)
+		if logf != nil {
+			if err != nil {
+				logf("FAIL %s: %v", check.Name, err)
+			} else {
+				logf("ok   %s", check.Name)
+			}
+		}
+		if err != nil {
+			failed = append(failed, check.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d conformance checks failed: %s", len(failed), len(Checks), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func createUser(store services.Store, username string) (int, error) {
+	err := store.SaveUser(&models.User{
+		Username:        username,
+		Email:           username + "@example.com",
+		PasswordHash:    "hash-" + username,
+		FitnessGoal:     "strength",
+		ExperienceLevel: "beginner",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("save user %s: %w", username, err)
+	}
+	return store.GetUserIdFromUsername(username)
+}
+
+func expectNotFound(what string, err error) error {
+	if !errors.Is(err, services.ErrNotFound) {
+		return fmt.Errorf("%s: want ErrNotFound, got %v", what, err)
+	}
+	return nil
+}
+
+func checkUsers(store services.Store) error {
+	userID, err := createUser(store, "conformance_user")
+	if err != nil {
+		return err
+	}
+
+	if _, err := createUser(store, "conformance_user"); !errors.Is(err, services.ErrUserExists) {
+		return fmt.Errorf("saving a taken username: want ErrUserExists, got %v", err)
+	}
+
+	exists, err := store.CheckIfUserExists("conformance_user")
+	if err != nil || !exists {
+		return fmt.Errorf("CheckIfUserExists = %v, %v; want true", exists, err)
+	}
+	exists, err = store.CheckIfUserExists("conformance_nobody")
+	if err != nil || exists {
+		return fmt.Errorf("CheckIfUserExists for unknown user = %v, %v; want false", exists, err)
+	}
+	password, err := store.FetchPassword("conformance_user")
+	if err != nil || password != "hash-conformance_user" {
+		return fmt.Errorf("FetchPassword = %q, %v", password, err)
+	}
+	if _, err := store.GetUserIdFromUsername("conformance_nobody"); err == nil {
+		return fmt.Errorf("GetUserIdFromUsername for unknown user succeeded")
+	}
+
+	roles, err := store.GetUserRoles("conformance_user")
+	if err != nil {
+		return err
+	}
+	if len(roles) != 1 || roles[0] != models.DefaultRole {
+		return fmt.Errorf("new user roles = %v, want [%s]", roles, models.DefaultRole)
+	}
+	if err := store.SetUserRoles(userID, []string{models.RoleMember, models.RoleCoach}); err != nil {
+		return err
+	}
+	roles, err = store.GetUserRoles("conformance_user")
+	if err != nil {
+		return err
+	}
+	if !models.HasRole(roles, models.RoleCoach) {
+		return fmt.Errorf("roles after SetUserRoles = %v, want coach", roles)
+	}
+	if err := expectNotFound("SetUserRoles for unknown user", store.SetUserRoles(-1, []string{models.RoleMember})); err != nil {
+		return err
+	}
+
+	users, err := store.ListUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.ID == userID {
+			if user.PasswordHash != "" {
+				return fmt.Errorf("ListUsers leaked a password hash")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("ListUsers did not return user %d", userID)
+}
+
+func checkTokens(store services.Store) error {
+	userID, err := createUser(store, "conformance_tokens")
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	if err := store.SaveRefreshToken(userID, "refresh-1", "family-1", expiresAt); err != nil {
+		return err
+	}
+	rotated, err := store.RotateRefreshToken("refresh-1", "refresh-2", expiresAt)
+	if err != nil {
+		return fmt.Errorf("RotateRefreshToken: %w", err)
+	}
+	if rotated.UserID != userID || rotated.FamilyID != "family-1" || rotated.Username != "conformance_tokens" || rotated.ID == 0 {
+		return fmt.Errorf("rotated token = %+v", rotated)
+	}
+	if _, err := store.RotateRefreshToken("refresh-1", "refresh-3", expiresAt); !errors.Is(err, services.ErrTokenReused) {
+		return fmt.Errorf("reusing a rotated token: want ErrTokenReused, got %v", err)
+	}
+	//reuse revokes the whole family, including the token that was still current
+	if _, err := store.RotateRefreshToken("refresh-2", "refresh-4", expiresAt); !errors.Is(err, services.ErrTokenReused) {
+		return fmt.Errorf("token of a revoked family: want ErrTokenReused, got %v", err)
+	}
+	_, err = store.RotateRefreshToken("refresh-unknown", "refresh-5", expiresAt)
+	if err := expectNotFound("rotating an unknown token", err); err != nil {
+		return err
+	}
+
+	if err := store.SaveRefreshToken(userID, "refresh-expired", "family-2", time.Now().Add(-time.Minute)); err != nil {
+		return err
+	}
+	if _, err := store.RotateRefreshToken("refresh-expired", "refresh-6", expiresAt); !errors.Is(err, services.ErrTokenExpired) {
+		return fmt.Errorf("rotating an expired token: want ErrTokenExpired, got %v", err)
+	}
+	if err := store.RevokeRefreshTokenFamily(userID, "refresh-expired"); err != nil {
+		return err
+	}
+	if err := expectNotFound("revoking another user's family", store.RevokeRefreshTokenFamily(userID+1000, "refresh-expired")); err != nil {
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		//revoking twice must not fail
+		if err := store.RevokeAccessToken("jti-1", expiresAt); err != nil {
+			return fmt.Errorf("RevokeAccessToken #%d: %w", i+1, err)
+		}
+	}
+	revoked, err := store.IsTokenRevoked("jti-1")
+	if err != nil || !revoked {
+		return fmt.Errorf("IsTokenRevoked(jti-1) = %v, %v; want true", revoked, err)
+	}
+	revoked, err = store.IsTokenRevoked("jti-2")
+	if err != nil || revoked {
+		return fmt.Errorf("IsTokenRevoked(jti-2) = %v, %v; want false", revoked, err)
+	}
+	return nil
+}
+
+func sampleWorkout() *models.Workout {
+	return &models.Workout{
+		Name: "Push",
+		Exercises: []models.ExerciseLog{
+			{Exercise: "Bench Press", Sets: []models.Set{
+				{Reps: 5, Weight: 100, RPE: 8},
+				{Reps: 5, Weight: 102.5, RPE: 8.5},
+				{Reps: 4, Weight: 105, RPE: 9.5},
+			}},
+			{Exercise: "Overhead Press", Sets: []models.Set{{Reps: 8, Weight: 50, RPE: 7}}},
+		},
+	}
+}
+
+func checkCoachClients(store services.Store) error {
+	coachID, err := createUser(store, "conformance_coach")
+	if err != nil {
+		return err
+	}
+	clientID, err := createUser(store, "conformance_client")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_not_a_client")
+	if err != nil {
+		return err
+	}
+
+	if err := store.AssignClient(coachID, clientID); !errors.Is(err, services.ErrNotCoach) {
+		return fmt.Errorf("assigning a client to a member: want ErrNotCoach, got %v", err)
+	}
+	if err := store.SetUserRoles(coachID, []string{models.RoleCoach}); err != nil {
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		//assigning twice must not fail
+		if err := store.AssignClient(coachID, clientID); err != nil {
+			return fmt.Errorf("AssignClient #%d: %w", i+1, err)
+		}
+	}
+	if err := expectNotFound("assigning an unknown client", store.AssignClient(coachID, -1)); err != nil {
+		return err
+	}
+
+	clients, err := store.GetClients(coachID)
+	if err != nil {
+		return err
+	}
+	if len(clients) != 1 || clients[0].ID != clientID || clients[0].PasswordHash != "" {
+		return fmt.Errorf("GetClients = %+v", clients)
+	}
+	for _, c := range []struct {
+		coachID, clientID int
+		want              bool
+	}{{coachID, clientID, true}, {coachID, otherID, false}, {clientID, coachID, false}} {
+		isCoach, err := store.IsCoachOf(c.coachID, c.clientID)
+		if err != nil || isCoach != c.want {
+			return fmt.Errorf("IsCoachOf(%d, %d) = %v, %v; want %v", c.coachID, c.clientID, isCoach, err, c.want)
+		}
+	}
+
+	if err := store.UnassignClient(coachID, clientID); err != nil {
+		return err
+	}
+	if err := expectNotFound("unassigning twice", store.UnassignClient(coachID, clientID)); err != nil {
+		return err
+	}
+	isCoach, err := store.IsCoachOf(coachID, clientID)
+	if err != nil || isCoach {
+		return fmt.Errorf("IsCoachOf after unassigning = %v, %v; want false", isCoach, err)
+	}
+	return nil
+}
+
+func checkWorkouts(store services.Store) error {
+	userID, err := createUser(store, "conformance_workouts")
+	if err != nil {
+		return err
+	}
+
+	workout := sampleWorkout()
+	if err := store.SaveWorkout(userID, workout); err != nil {
+		return err
+	}
+	if workout.ID == 0 || workout.UserID != userID {
+		return fmt.Errorf("SaveWorkout did not fill in ids: %+v", workout)
+	}
+	bench := workout.Exercises[0]
+	if bench.ID == 0 || bench.WorkoutID != workout.ID || bench.Sets[2].ID == 0 || bench.Sets[2].SetNumber != 3 {
+		return fmt.Errorf("SaveWorkout did not fill in exercise and set ids: %+v", bench)
+	}
+
+	workouts, err := store.GetWorkouts(userID)
+	if err != nil {
+		return err
+	}
+	if len(workouts) != 1 || len(workouts[0].Exercises) != 2 || len(workouts[0].Exercises[0].Sets) != 3 {
+		return fmt.Errorf("GetWorkouts = %+v", workouts)
+	}
+	if workouts[0].CreatedAt.IsZero() {
+		return fmt.Errorf("GetWorkouts did not load created_at")
+	}
+	if set := workouts[0].Exercises[0].Sets[1]; set.Weight != 102.5 || set.RPE != 8.5 || set.SetNumber != 2 {
+		return fmt.Errorf("second bench set = %+v", set)
+	}
+
+	name := "Push Day"
+	if err := store.PatchWorkout(userID, workout.ID, &models.WorkoutPatch{Name: &name}); err != nil {
+		return err
+	}
+	loaded, err := store.GetWorkout(userID, workout.ID)
+	if err != nil {
+		return err
+	}
+	if loaded.Name != name || len(loaded.Exercises) != 2 {
+		return fmt.Errorf("GetWorkout after patch = %+v", loaded)
+	}
+
+	replacement := models.ExerciseLog{Exercise: "Incline Bench Press", Sets: []models.Set{{Reps: 10, Weight: 60, RPE: 7}}}
+	if err := store.ReplaceExercise(userID, workout.ID, bench.ID, &replacement); err != nil {
+		return err
+	}
+	exercise, err := store.GetExercise(userID, workout.ID, bench.ID)
+	if err != nil {
+		return err
+	}
+	if exercise.Exercise != "Incline Bench Press" || len(exercise.Sets) != 1 {
+		return fmt.Errorf("GetExercise after replace = %+v", exercise)
+	}
+
+	press := workout.Exercises[1]
+	weight := 52.5
+	if err := store.PatchSet(userID, workout.ID, press.ID, press.Sets[0].ID, &models.SetPatch{Weight: &weight}); err != nil {
+		return err
+	}
+	set, err := store.GetSet(userID, workout.ID, press.ID, press.Sets[0].ID)
+	if err != nil {
+		return err
+	}
+	if set.Weight != weight || set.Reps != 8 {
+		return fmt.Errorf("GetSet after patch = %+v", set)
+	}
+
+	replaced := sampleWorkout()
+	replaced.Name = "Push B"
+	if err := store.ReplaceWorkout(userID, workout.ID, replaced); err != nil {
+		return err
+	}
+	loaded, err = store.GetWorkout(userID, workout.ID)
+	if err != nil {
+		return err
+	}
+	first := loaded.Exercises[0]
+	if err := store.DeleteSet(userID, workout.ID, first.ID, first.Sets[0].ID); err != nil {
+		return err
+	}
+	exercise, err = store.GetExercise(userID, workout.ID, first.ID)
+	if err != nil {
+		return err
+	}
+	if len(exercise.Sets) != 2 || exercise.Sets[0].SetNumber != 1 || exercise.Sets[1].SetNumber != 2 {
+		return fmt.Errorf("sets were not renumbered after DeleteSet: %+v", exercise.Sets)
+	}
+
+	if err := store.DeleteExercise(userID, workout.ID, first.ID); err != nil {
+		return err
+	}
+	_, err = store.GetExercise(userID, workout.ID, first.ID)
+	if err := expectNotFound("GetExercise after delete", err); err != nil {
+		return err
+	}
+	if err := store.DeleteWorkout(userID, workout.ID); err != nil {
+		return err
+	}
+	_, err = store.GetWorkout(userID, workout.ID)
+	return expectNotFound("GetWorkout after delete", err)
+}
+
+func checkWorkoutOwnership(store services.Store) error {
+	ownerID, err := createUser(store, "conformance_owner")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_other")
+	if err != nil {
+		return err
+	}
+
+	workout := sampleWorkout()
+	if err := store.SaveWorkout(ownerID, workout); err != nil {
+		return err
+	}
+	exercise := workout.Exercises[0]
+	set := exercise.Sets[0]
+	name := "stolen"
+	reps := 1
+
+	workouts, err := store.GetWorkouts(otherID)
+	if err != nil {
+		return err
+	}
+	if len(workouts) != 0 {
+		return fmt.Errorf("GetWorkouts returned another user's workouts")
+	}
+	_, err = store.GetWorkout(otherID, workout.ID)
+	if err := expectNotFound("GetWorkout", err); err != nil {
+		return err
+	}
+	if err := expectNotFound("PatchWorkout", store.PatchWorkout(otherID, workout.ID, &models.WorkoutPatch{Name: &name})); err != nil {
+		return err
+	}
+	if err := expectNotFound("ReplaceWorkout", store.ReplaceWorkout(otherID, workout.ID, sampleWorkout())); err != nil {
+		return err
+	}
+	_, err = store.GetExercise(otherID, workout.ID, exercise.ID)
+	if err := expectNotFound("GetExercise", err); err != nil {
+		return err
+	}
+	if err := expectNotFound("DeleteExercise", store.DeleteExercise(otherID, workout.ID, exercise.ID)); err != nil {
+		return err
+	}
+	_, err = store.GetSet(otherID, workout.ID, exercise.ID, set.ID)
+	if err := expectNotFound("GetSet", err); err != nil {
+		return err
+	}
+	if err := expectNotFound("PatchSet", store.PatchSet(otherID, workout.ID, exercise.ID, set.ID, &models.SetPatch{Reps: &reps})); err != nil {
+		return err
+	}
+	if err := expectNotFound("DeleteSet", store.DeleteSet(otherID, workout.ID, exercise.ID, set.ID)); err != nil {
+		return err
+	}
+	if err := expectNotFound("DeleteWorkout", store.DeleteWorkout(otherID, workout.ID)); err != nil {
+		return err
+	}
+
+	//mismatched parents are not found either, even for the owner
+	_, err = store.GetSet(ownerID, workout.ID, workout.Exercises[1].ID, set.ID)
+	if err := expectNotFound("GetSet under the wrong exercise", err); err != nil {
+		return err
+	}
+	_, err = store.GetWorkout(ownerID, workout.ID)
+	return err
+}
+
+func checkSetMetadata(store services.Store) error {
+	userID, err := createUser(store, "conformance_sets")
+	if err != nil {
+		return err
+	}
+
+	rir, notDone := 2.0, false
+	workout := &models.Workout{Name: "Sets", Exercises: []models.ExerciseLog{{Exercise: "Bench Press", Sets: []models.Set{
+		{Reps: 5, Weight: 200, Type: "Warm-up"},
+		{Reps: 5, Weight: 100, RIR: &rir, Tempo: "31x0", Remarks: "paused"},
+		{Reps: 5, Weight: 150, Completed: &notDone},
+	}}}}
+	if err := store.SaveWorkout(userID, workout); err != nil {
+		return err
+	}
+	exercise := workout.Exercises[0]
+	stored, err := store.GetExercise(userID, workout.ID, exercise.ID)
+	if err != nil {
+		return err
+	}
+	working := stored.Sets[1]
+	if stored.Sets[0].Type != models.SetTypeWarmup || working.Type != models.SetTypeWorking || working.RPE != 8 || working.RIR == nil || *working.RIR != 2 {
+		return fmt.Errorf("stored sets: %+v", stored.Sets)
+	}
+	if working.Tempo != "3-1-X-0" || working.Remarks != "paused" || working.Completed == nil || !*working.Completed || *stored.Sets[2].Completed {
+		return fmt.Errorf("stored set metadata: %+v", working)
+	}
+
+	setRep, err := store.GetSetRep(userID, "Bench Press", 5)
+	if err != nil || setRep.Weight != 100 {
+		return fmt.Errorf("GetSetRep counted a warm-up or unfinished set: %+v, %v", setRep, err)
+	}
+
+	rir = 0
+	if err := store.PatchSet(userID, workout.ID, exercise.ID, working.ID, &models.SetPatch{RIR: &rir}); err != nil {
+		return err
+	}
+	patched, err := store.GetSet(userID, workout.ID, exercise.ID, working.ID)
+	if err != nil {
+		return err
+	}
+	if patched.RPE != 10 || patched.Tempo != "3-1-X-0" {
+		return fmt.Errorf("patching rir: %+v", patched)
+	}
+	invalid := "giant"
+	if err := store.PatchSet(userID, workout.ID, exercise.ID, working.ID, &models.SetPatch{Type: &invalid}); !errors.Is(err, services.ErrInvalidSet) {
+		return fmt.Errorf("patching an unknown set type: want ErrInvalidSet, got %v", err)
+	}
+	return store.DeleteWorkout(userID, workout.ID)
+}
+
+func checkCardioSets(store services.Store) error {
+	userID, err := createUser(store, "conformance_cardio")
+	if err != nil {
+		return err
+	}
+
+	rejected := []models.ExerciseLog{
+		{Exercise: "Running", Sets: []models.Set{{Reps: 10}}},
+		{Exercise: "Plank", Sets: []models.Set{{DistanceMeters: 20}}},
+		{Exercise: "Squat", Sets: []models.Set{{DurationSeconds: 60}}},
+		{Exercise: "Running", Sets: []models.Set{{DistanceMeters: 5000, AvgHeartRate: 170, MaxHeartRate: 160}}},
+	}
+	for _, exercise := range rejected {
+		workout := &models.Workout{Name: "Rejected", Exercises: []models.ExerciseLog{exercise}}
+		if err := store.SaveWorkout(userID, workout); !errors.Is(err, services.ErrInvalidSet) {
+			return fmt.Errorf("saving %s %+v: want ErrInvalidSet, got %v", exercise.Exercise, exercise.Sets[0], err)
+		}
+	}
+
+	workout := &models.Workout{Name: "Cardio", Exercises: []models.ExerciseLog{
+		{Exercise: "run", Sets: []models.Set{{DistanceMeters: 5000, PaceSecondsPerKm: 300, AvgHeartRate: 150, MaxHeartRate: 172, Calories: 400}}},
+		{Exercise: "Plank", Sets: []models.Set{{DurationSeconds: 90}}},
+		{Exercise: "Farmer's Walk", Sets: []models.Set{{Weight: 32, DistanceMeters: 40}}},
+	}}
+	if err := store.SaveWorkout(userID, workout); err != nil {
+		return err
+	}
+	run, err := store.GetExercise(userID, workout.ID, workout.Exercises[0].ID)
+	if err != nil {
+		return err
+	}
+	set := run.Sets[0]
+	if run.Exercise != "Running" || set.DurationSeconds != 1500 || set.PaceSecondsPerKm != 300 || set.AvgHeartRate != 150 || set.Calories != 400 {
+		return fmt.Errorf("stored run: %s %+v", run.Exercise, set)
+	}
+
+	duration := 1200
+	if err := store.PatchSet(userID, workout.ID, run.ID, set.ID, &models.SetPatch{DurationSeconds: &duration}); err != nil {
+		return err
+	}
+	patched, err := store.GetSet(userID, workout.ID, run.ID, set.ID)
+	if err != nil {
+		return err
+	}
+	if patched.PaceSecondsPerKm != 240 || patched.DistanceMeters != 5000 {
+		return fmt.Errorf("patching duration: %+v", patched)
+	}
+
+	history, err := store.GetSetHistory(userID, "Running")
+	if err != nil {
+		return err
+	}
+	if len(history) != 1 || history[0].DistanceMeters != 5000 || history[0].DurationSeconds != 1200 {
+		return fmt.Errorf("running history: %+v", history)
+	}
+	return store.DeleteWorkout(userID, workout.ID)
+}
+
+func checkExerciseGroups(store services.Store) error {
+	userID, err := createUser(store, "conformance_groups")
+	if err != nil {
+		return err
+	}
+
+	set := []models.Set{{Reps: 10, Weight: 20}}
+	workout := &models.Workout{Name: "Arms", Groups: []models.ExerciseGroup{
+		{Label: "B", Type: "Giant Set", Rounds: 3, RestSeconds: 90},
+		{Label: "A"},
+	}, Exercises: []models.ExerciseLog{
+		{Exercise: "Bench Press", Sets: set},
+		{Exercise: "Barbell Curl", Group: "A", Sets: set},
+		{Exercise: "Tricep Pushdown", Group: "A", Sets: set},
+		{Exercise: "Lateral Raise", Group: "B", Sets: set},
+		{Exercise: "Face Pull", Group: "B", Sets: set},
+		{Exercise: "Hammer Curl", Group: "B", Sets: set},
+	}}
+	if err := store.SaveWorkout(userID, workout); err != nil {
+		return err
+	}
+	stored, err := store.GetWorkout(userID, workout.ID)
+	if err != nil {
+		return err
+	}
+	want := []models.ExerciseGroup{
+		{Label: "A", Type: models.GroupTypeSuperset, Rounds: 1},
+		{Label: "B", Type: models.GroupTypeGiantSet, Rounds: 3, RestSeconds: 90},
+	}
+	if len(stored.Groups) != len(want) || stored.Groups[0] != want[0] || stored.Groups[1] != want[1] {
+		return fmt.Errorf("stored groups: %+v", stored.Groups)
+	}
+	if stored.Exercises[0].Group != "" || stored.Exercises[1].Group != "A" || stored.Exercises[5].Group != "B" {
+		return fmt.Errorf("stored exercises lost their groups: %+v", stored.Exercises)
+	}
+
+	invalid := []*models.Workout{
+		{Name: "Split", Groups: []models.ExerciseGroup{{Label: "A"}}, Exercises: []models.ExerciseLog{
+			{Exercise: "Bench Press", Group: "A", Sets: set}, {Exercise: "Squat", Sets: set}, {Exercise: "Deadlift", Group: "A", Sets: set},
+		}},
+		{Name: "Undefined", Exercises: []models.ExerciseLog{{Exercise: "Bench Press", Group: "A", Sets: set}, {Exercise: "Squat", Group: "A", Sets: set}}},
+		{Name: "Three", Groups: []models.ExerciseGroup{{Label: "A", Type: "superset"}}, Exercises: []models.ExerciseLog{
+			{Exercise: "Bench Press", Group: "A", Sets: set}, {Exercise: "Squat", Group: "A", Sets: set}, {Exercise: "Deadlift", Group: "A", Sets: set},
+		}},
+	}
+	for _, w := range invalid {
+		if err := store.SaveWorkout(userID, w); !errors.Is(err, services.ErrInvalidGroup) {
+			return fmt.Errorf("saving workout %s: want ErrInvalidGroup, got %v", w.Name, err)
+		}
+	}
+
+	//removing an exercise dissolves a superset and shrinks a giant set to a superset
+	if err := store.DeleteExercise(userID, workout.ID, stored.Exercises[1].ID); err != nil {
+		return err
+	}
+	if err := store.DeleteExercise(userID, workout.ID, stored.Exercises[5].ID); err != nil {
+		return err
+	}
+	pruned, err := store.GetWorkout(userID, workout.ID)
+	if err != nil {
+		return err
+	}
+	if len(pruned.Groups) != 1 || pruned.Groups[0].Label != "B" || pruned.Groups[0].Type != models.GroupTypeSuperset || pruned.Exercises[1].Group != "" {
+		return fmt.Errorf("groups after deleting exercises: %+v %+v", pruned.Groups, pruned.Exercises)
+	}
+
+	template := &models.WorkoutTemplate{Name: "Circuit", Groups: []models.ExerciseGroup{{Label: "C", Type: models.GroupTypeCircuit, Rounds: 4}}, Exercises: []models.TemplateExercise{
+		{Exercise: "Kettlebell Swing", Group: "C", TargetSets: 1, MinReps: 15},
+		{Exercise: "Push Up", Group: "C", TargetSets: 1, MinReps: 10},
+	}}
+	if err := models.ValidateTemplate(template); err != nil {
+		return err
+	}
+	if err := store.SaveTemplate(userID, template); err != nil {
+		return err
+	}
+	templates, err := store.GetTemplates(userID)
+	if err != nil {
+		return err
+	}
+	if len(templates) != 1 || len(templates[0].Groups) != 1 || templates[0].Groups[0].Rounds != 4 || templates[0].Exercises[1].Group != "C" {
+		return fmt.Errorf("template groups: %+v", templates)
+	}
+	if err := store.DeleteTemplate(userID, template.ID); err != nil {
+		return err
+	}
+	return store.DeleteWorkout(userID, workout.ID)
+}
+
+func checkImports(store services.Store) error {
+	userID, err := createUser(store, "conformance_imports")
+	if err != nil {
+		return err
+	}
+
+	startedAt := time.Date(2024, 3, 1, 18, 30, 0, 0, time.UTC)
+	session := func(key string) models.ImportedSession {
+		return models.ImportedSession{Key: key, Workout: models.Workout{Name: "Imported", CreatedAt: startedAt, Exercises: []models.ExerciseLog{
+			{Exercise: "Squat", Sets: []models.Set{{Reps: 5, Weight: 100}}},
+		}}}
+	}
+	ids, duplicates, err := store.ImportWorkouts(userID, "strong", []models.ImportedSession{session("strong:1"), session("strong:2")})
+	if err != nil {
+		return err
+	}
+	if len(ids) != 2 || duplicates != 0 {
+		return fmt.Errorf("first import: ids %v, %d duplicates", ids, duplicates)
+	}
+	stored, err := store.GetWorkout(userID, ids[0])
+	if err != nil {
+		return err
+	}
+	if !stored.CreatedAt.Equal(startedAt) || len(stored.Exercises) != 1 || stored.Exercises[0].CatalogID == 0 {
+		return fmt.Errorf("imported workout: %+v", stored)
+	}
+
+	//a session is only imported once per source
+	again, duplicates, err := store.ImportWorkouts(userID, "strong", []models.ImportedSession{session("strong:1"), session("strong:3")})
+	if err != nil {
+		return err
+	}
+	if len(again) != 1 || duplicates != 1 {
+		return fmt.Errorf("second import: ids %v, %d duplicates", again, duplicates)
+	}
+	keys, err := store.GetImportedSessionKeys(userID, "strong")
+	if err != nil {
+		return err
+	}
+	if len(keys) != 3 || !keys["strong:2"] {
+		return fmt.Errorf("imported keys: %v", keys)
+	}
+	if keys, err := store.GetImportedSessionKeys(userID, "hevy"); err != nil || len(keys) != 0 {
+		return fmt.Errorf("hevy keys: %v %v", keys, err)
+	}
+
+	//deleting an imported workout allows importing its session again
+	if err := store.DeleteWorkout(userID, ids[0]); err != nil {
+		return err
+	}
+	if keys, err = store.GetImportedSessionKeys(userID, "strong"); err != nil || keys["strong:1"] {
+		return fmt.Errorf("keys after delete: %v %v", keys, err)
+	}
+
+	//a failing session rolls back the whole import
+	broken := session("strong:4")
+	broken.Workout.Exercises[0].CatalogID = -1
+	if _, _, err := store.ImportWorkouts(userID, "strong", []models.ImportedSession{session("strong:1"), broken}); !errors.Is(err, services.ErrUnknownExercise) {
+		return fmt.Errorf("importing an unknown catalog id: want ErrUnknownExercise, got %v", err)
+	}
+	if keys, err = store.GetImportedSessionKeys(userID, "strong"); err != nil || len(keys) != 2 {
+		return fmt.Errorf("keys after failed import: %v %v", keys, err)
+	}
+	for _, id := range append(ids[1:], again...) {
+		if err := store.DeleteWorkout(userID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkStreamWorkouts(store services.Store) error {
+	userID, err := createUser(store, "conformance_stream")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_stream_other")
+	if err != nil {
+		return err
+	}
+
+	set := []models.Set{{Reps: 5, Weight: 100}, {Reps: 5, Weight: 105}}
+	older := &models.Workout{Name: "Older", CreatedAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Groups: []models.ExerciseGroup{{Label: "A", Type: models.GroupTypeSuperset, Rounds: 1}}, Exercises: []models.ExerciseLog{
+		{Exercise: "Squat", Sets: set},
+		{Exercise: "Bench Press", Group: "A", Sets: set},
+		{Exercise: "Barbell Row", Group: "A", Sets: set[:1]},
+	}}
+	newer := &models.Workout{Name: "Newer", CreatedAt: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC), Exercises: []models.ExerciseLog{{Exercise: "Deadlift", Sets: set}}}
+	//imported workouts keep their dates, which puts them out of insertion order
+	for _, workout := range []*models.Workout{newer, older} {
+		if _, _, err := store.ImportWorkouts(userID, "conformance", []models.ImportedSession{{Key: workout.Name, Workout: *workout}}); err != nil {
+			return err
+		}
+	}
+	if err := store.SaveWorkout(otherID, sampleWorkout()); err != nil {
+		return err
+	}
+	//a workout whose exercises were all removed still streams
+	empty := sampleWorkout()
+	if err := store.SaveWorkout(userID, empty); err != nil {
+		return err
+	}
+	for _, exercise := range empty.Exercises {
+		if err := store.DeleteExercise(userID, empty.ID, exercise.ID); err != nil {
+			return err
+		}
+	}
+
+	var streamed []models.Workout
+	err = store.StreamWorkouts(userID, func(workout *models.Workout) error {
+		streamed = append(streamed, *workout)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(streamed) != 3 || streamed[0].Name != "Older" || streamed[1].Name != "Newer" || streamed[2].ID != empty.ID {
+		return fmt.Errorf("streamed workouts: %+v", streamed)
+	}
+	first := streamed[0]
+	if len(first.Exercises) != 3 || len(first.Exercises[0].Sets) != 2 || first.Exercises[0].Sets[1].Weight != 105 || len(first.Exercises[2].Sets) != 1 {
+		return fmt.Errorf("streamed exercises: %+v", first.Exercises)
+	}
+	if len(first.Groups) != 1 || first.Groups[0].Type != models.GroupTypeSuperset || first.Exercises[1].Group != "A" {
+		return fmt.Errorf("streamed groups: %+v", first.Groups)
+	}
+	if len(streamed[2].Exercises) != 0 {
+		return fmt.Errorf("emptied workout streamed exercises: %+v", streamed[2].Exercises)
+	}
+	stored, err := store.GetWorkout(userID, first.ID)
+	if err != nil {
+		return err
+	}
+	if stored.Exercises[1].Sets[0].ID != first.Exercises[1].Sets[0].ID || stored.Exercises[1].CatalogID != first.Exercises[1].CatalogID {
+		return fmt.Errorf("streamed workout differs from stored: %+v vs %+v", first, stored)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = store.StreamWorkouts(userID, func(*models.Workout) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		return fmt.Errorf("stopping the stream: %v after %d calls", err, calls)
+	}
+
+	//more workouts than a page, all at the same time, still stream once each in order
+	pagedID, err := createUser(store, "conformance_stream_paged")
+	if err != nil {
+		return err
+	}
+	sessions := make([]models.ImportedSession, services.StreamPageSize+1)
+	for i := range sessions {
+		sessions[i] = models.ImportedSession{Key: strconv.Itoa(i), Workout: models.Workout{Name: "Paged", CreatedAt: older.CreatedAt, Exercises: []models.ExerciseLog{{Exercise: "Squat", Sets: set[:1]}}}}
+	}
+	if _, _, err := store.ImportWorkouts(pagedID, "conformance", sessions); err != nil {
+		return err
+	}
+	streamed = nil
+	err = store.StreamWorkouts(pagedID, func(workout *models.Workout) error {
+		if n := len(streamed); n > 0 && workout.ID <= streamed[n-1].ID {
+			return fmt.Errorf("workout %d streamed after %d", workout.ID, streamed[n-1].ID)
+		}
+		if len(workout.Exercises) != 1 || len(workout.Exercises[0].Sets) != 1 {
+			return fmt.Errorf("paged workout %d: %+v", workout.ID, workout.Exercises)
+		}
+		streamed = append(streamed, *workout)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(streamed) != len(sessions) {
+		return fmt.Errorf("streamed %d of %d workouts over pages", len(streamed), len(sessions))
+	}
+	return nil
+}
+
+func checkWorkoutHistory(store services.Store) error {
+	userID, err := createUser(store, "conformance_history")
+	if err != nil {
+		return err
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 18, 0, 0, 0, time.UTC) }
+	warmup := models.SetTypeWarmup
+	workouts := []*models.Workout{
+		{Name: "Push A", CreatedAt: day(1), Exercises: []models.ExerciseLog{{Exercise: "Bench Press", Sets: []models.Set{{Reps: 10, Weight: 50, Type: warmup}, {Reps: 5, Weight: 100}}}}},
+		{Name: "legs", CreatedAt: day(3), Exercises: []models.ExerciseLog{{Exercise: "Squat", Sets: []models.Set{{Reps: 5, Weight: 140}}}}},
+		{Name: "Pull", CreatedAt: day(5), Exercises: []models.ExerciseLog{{Exercise: "Deadlift", Sets: []models.Set{{Reps: 3, Weight: 180}}}, {Exercise: "Barbell Row", Sets: []models.Set{{Reps: 8, Weight: 70}}}}},
+		{Name: "Push B", CreatedAt: day(7), Exercises: []models.ExerciseLog{{Exercise: "bench", Sets: []models.Set{{Reps: 8, Weight: 90}}}}},
+		//logged without a date, so both get the current time and tie on it
+		{Name: "Today 1", Exercises: []models.ExerciseLog{{Exercise: "Squat", Sets: []models.Set{{Reps: 5, Weight: 100}}}}},
+		{Name: "Today 2", Exercises: []models.ExerciseLog{{Exercise: "Squat", Sets: []models.Set{{Reps: 5, Weight: 100}}}}},
+	}
+	for _, workout := range workouts {
+		if err := store.SaveWorkout(userID, workout); err != nil {
+			return err
+		}
+	}
+
+	//pages through the whole history and returns the workout names in order
+	names := func(query models.WorkoutQuery) ([]string, error) {
+		if err := models.ValidateWorkoutQuery(&query); err != nil {
+			return nil, err
+		}
+		var names []string
+		for pages := 0; pages < 10; pages++ {
+			page, err := store.ListWorkouts(userID, query)
+			if err != nil {
+				return nil, err
+			}
+			if len(page.Workouts) > query.Limit {
+				return nil, fmt.Errorf("page of %d workouts with limit %d", len(page.Workouts), query.Limit)
+			}
+			for _, workout := range page.Workouts {
+				names = append(names, workout.Name)
+			}
+			if page.NextCursor == "" {
+				return names, nil
+			}
+			query.Cursor = page.NextCursor
+		}
+		return nil, fmt.Errorf("history does not end")
+	}
+	cases := []struct {
+		query models.WorkoutQuery
+		want  string
+	}{
+		{models.WorkoutQuery{Limit: 2}, "Today 2,Today 1,Push B,Pull,legs,Push A"},
+		{models.WorkoutQuery{Limit: 4, Order: models.SortAscending}, "Push A,legs,Pull,Push B,Today 1,Today 2"},
+		{models.WorkoutQuery{Limit: 1, Sort: models.SortByName, To: day(31)}, "legs,Pull,Push A,Push B"},
+		{models.WorkoutQuery{Limit: 1, Name: "push"}, "Push B,Push A"},
+		{models.WorkoutQuery{Name: "%"}, ""},
+		{models.WorkoutQuery{Exercise: "Bench Press"}, "Push B,Push A"},
+		{models.WorkoutQuery{From: day(2), To: day(6)}, "Pull,legs"},
+	}
+	for _, c := range cases {
+		got, err := names(c.query)
+		if err != nil {
+			return err
+		}
+		if strings.Join(got, ",") != c.want {
+			return fmt.Errorf("history for %+v: got %v, want %s", c.query, got, c.want)
+		}
+	}
+
+	query := models.WorkoutQuery{Limit: 2, From: day(1), To: day(31)}
+	if err := models.ValidateWorkoutQuery(&query); err != nil {
+		return err
+	}
+	first, err := store.ListWorkouts(userID, query)
+	if err != nil {
+		return err
+	}
+	if len(first.Workouts) != 2 || len(first.Workouts[1].Exercises) != 2 || first.Workouts[1].Exercises[1].Sets[0].Weight != 70 {
+		return fmt.Errorf("first page: %+v", first.Workouts)
+	}
+	//the cursor survives the deletion of the workout it points after
+	if err := store.DeleteWorkout(userID, first.Workouts[1].ID); err != nil {
+		return err
+	}
+	query.Cursor = first.NextCursor
+	second, err := store.ListWorkouts(userID, query)
+	if err != nil {
+		return err
+	}
+	if len(second.Workouts) != 2 || second.Workouts[0].Name != "legs" || second.NextCursor != "" {
+		return fmt.Errorf("page after a deleted workout: %+v", second)
+	}
+
+	summaries, err := store.ListWorkoutSummaries(userID, models.WorkoutQuery{Name: "Push A", Sort: models.SortByDate, Order: models.SortDescending, Limit: 5})
+	if err != nil {
+		return err
+	}
+	if len(summaries.Workouts) != 1 || summaries.Workouts[0].Sets != 2 || summaries.Workouts[0].Volume != 500 || summaries.Workouts[0].Exercises[0] != "Bench Press" {
+		return fmt.Errorf("summaries: %+v", summaries)
+	}
+
+	sorted := models.WorkoutQuery{Sort: models.SortByName, Order: models.SortAscending, Limit: 5, Cursor: first.NextCursor}
+	if _, err := store.ListWorkouts(userID, sorted); !errors.Is(err, services.ErrInvalidCursor) {
+		return fmt.Errorf("cursor of another sort: want ErrInvalidCursor, got %v", err)
+	}
+	sorted.Cursor = "not a cursor"
+	if _, err := store.ListWorkouts(userID, sorted); !errors.Is(err, services.ErrInvalidCursor) {
+		return fmt.Errorf("malformed cursor: want ErrInvalidCursor, got %v", err)
+	}
+	return nil
+}
+
+func checkCatalog(store services.Store) error {
+	userID, err := createUser(store, "conformance_catalog")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_catalog_other")
+	if err != nil {
+		return err
+	}
+
+	library, err := store.ListCatalogExercises(userID, models.CatalogFilter{})
+	if err != nil {
+		return err
+	}
+	if len(library) == 0 {
+		return fmt.Errorf("the exercise library was not seeded")
+	}
+	bench, err := store.ResolveExercise(userID, "BB Bench")
+	if err != nil {
+		return fmt.Errorf("ResolveExercise(BB Bench): %w", err)
+	}
+	if bench.Name != "Bench Press" || bench.Custom || len(bench.PrimaryMuscles) == 0 {
+		return fmt.Errorf("BB Bench resolved to %+v", bench)
+	}
+	chest, err := store.ListCatalogExercises(userID, models.CatalogFilter{Muscle: "chest", Query: "bench"})
+	if err != nil {
+		return err
+	}
+	if len(chest) == 0 || len(chest) >= len(library) {
+		return fmt.Errorf("filtering by muscle and query returned %d of %d exercises", len(chest), len(library))
+	}
+
+	custom := &models.CatalogExercise{Name: "Zercher Carry", Aliases: []string{"zercher walk"}, PrimaryMuscles: []string{"upper back"}, Equipment: "barbell", MovementPattern: "carry"}
+	if err := store.CreateCustomExercise(userID, custom); err != nil {
+		return err
+	}
+	if custom.ID == 0 || !custom.Custom {
+		return fmt.Errorf("CreateCustomExercise did not fill in the id")
+	}
+	clash := &models.CatalogExercise{Name: "My Bench", Aliases: []string{"flat bench"}, PrimaryMuscles: []string{"chest"}}
+	if err := store.CreateCustomExercise(userID, clash); !errors.Is(err, services.ErrExerciseExists) {
+		return fmt.Errorf("custom exercise shadowing a library alias: want ErrExerciseExists, got %v", err)
+	}
+	if _, err := store.ResolveExercise(otherID, "zercher walk"); !errors.Is(err, services.ErrUnknownExercise) {
+		return fmt.Errorf("another user's custom exercise resolved: %v", err)
+	}
+	_, err = store.GetCatalogExercise(otherID, custom.ID)
+	if err := expectNotFound("GetCatalogExercise of another user's custom exercise", err); err != nil {
+		return err
+	}
+
+	workout := &models.Workout{Name: "Catalog", Exercises: []models.ExerciseLog{
+		{Exercise: "bb bench", Sets: []models.Set{{Reps: 5, Weight: 100, RPE: 8}}},
+		{CatalogID: custom.ID, Sets: []models.Set{{Reps: 1, Weight: 80, RPE: 8}}},
+	}}
+	if err := store.SaveWorkout(userID, workout); err != nil {
+		return err
+	}
+	if workout.Exercises[0].Exercise != "Bench Press" || workout.Exercises[0].CatalogID != bench.ID || workout.Exercises[1].Exercise != "Zercher Carry" {
+		return fmt.Errorf("logged exercises were not resolved: %+v", workout.Exercises)
+	}
+	//names the catalog does not know are kept as free text
+	unknown := &models.Workout{Name: "Unknown", Exercises: []models.ExerciseLog{{Exercise: " Underwater Basket Weaving ", Sets: []models.Set{{Reps: 1}}}}}
+	if err := store.SaveWorkout(userID, unknown); err != nil {
+		return fmt.Errorf("logging an unknown exercise: %v", err)
+	}
+	saved, err := store.GetWorkout(userID, unknown.ID)
+	if err != nil {
+		return err
+	}
+	if got := saved.Exercises[0]; got.Exercise != "Underwater Basket Weaving" || got.CatalogID != 0 || !got.Unlinked {
+		return fmt.Errorf("unknown exercise was not kept unlinked: %+v", got)
+	}
+	if err := store.SaveWorkout(userID, &models.Workout{Name: "Unknown", Exercises: []models.ExerciseLog{{CatalogID: -1}}}); !errors.Is(err, services.ErrUnknownExercise) {
+		return fmt.Errorf("logging an unknown catalog id: want ErrUnknownExercise, got %v", err)
+	}
+	if err := store.SaveWorkout(otherID, &models.Workout{Name: "Other", Exercises: []models.ExerciseLog{{CatalogID: custom.ID}}}); !errors.Is(err, services.ErrUnknownExercise) {
+		return fmt.Errorf("logging another user's custom exercise: want ErrUnknownExercise, got %v", err)
+	}
+	for _, name := range []string{"Bench", "bench press", "Barbell Bench Press"} {
+		setRep, err := store.GetSetRep(userID, name, 5)
+		if err != nil || setRep.Weight != 100 {
+			return fmt.Errorf("GetSetRep(%s) = %+v, %v", name, setRep, err)
+		}
+	}
+
+	if err := store.DeleteCustomExercise(userID, custom.ID); !errors.Is(err, services.ErrExerciseInUse) {
+		return fmt.Errorf("deleting a logged custom exercise: want ErrExerciseInUse, got %v", err)
+	}
+	if err := expectNotFound("deleting a library exercise", store.DeleteCustomExercise(userID, bench.ID)); err != nil {
+		return err
+	}
+	for _, id := range []int{workout.ID, unknown.ID} {
+		if err := store.DeleteWorkout(userID, id); err != nil {
+			return err
+		}
+	}
+	return store.DeleteCustomExercise(userID, custom.ID)
+}
+
+func checkTemplates(store services.Store) error {
+	userID, err := createUser(store, "conformance_templates")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_templates_other")
+	if err != nil {
+		return err
+	}
+
+	template := &models.WorkoutTemplate{Name: "Push Day A", Exercises: []models.TemplateExercise{
+		{Exercise: "bb bench", TargetSets: 3, MinReps: 5, MaxReps: 8, TargetRPE: 8, RestSeconds: 180},
+		{Exercise: "OHP", TargetSets: 2, MinReps: 8, MaxReps: 12},
+	}}
+	if err := store.SaveTemplate(userID, template); err != nil {
+		return err
+	}
+	if template.ID == 0 || template.Exercises[0].Exercise != "Bench Press" || template.Exercises[1].Position != 2 {
+		return fmt.Errorf("SaveTemplate did not resolve the exercises: %+v", template)
+	}
+	unknown := &models.WorkoutTemplate{Name: "Unknown", Exercises: []models.TemplateExercise{{Exercise: "Underwater Basket Weaving", TargetSets: 1, MinReps: 1}}}
+	if err := store.SaveTemplate(userID, unknown); !errors.Is(err, services.ErrUnknownExercise) {
+		return fmt.Errorf("template with an unknown exercise: want ErrUnknownExercise, got %v", err)
+	}
+
+	templates, err := store.GetTemplates(userID)
+	if err != nil {
+		return err
+	}
+	if len(templates) != 1 || len(templates[0].Exercises) != 2 || templates[0].Exercises[0].TargetRPE != 8 {
+		return fmt.Errorf("GetTemplates returned %+v", templates)
+	}
+	_, err = store.GetTemplate(otherID, template.ID)
+	if err := expectNotFound("GetTemplate of another user's template", err); err != nil {
+		return err
+	}
+
+	if _, err := store.GetLastPerformance(userID, template.Exercises[0].CatalogID); !errors.Is(err, services.ErrNotFound) {
+		return fmt.Errorf("GetLastPerformance without history: want ErrNotFound, got %v", err)
+	}
+	workout := &models.Workout{Name: "Push", Exercises: []models.ExerciseLog{
+		{Exercise: "Bench Press", Sets: []models.Set{{Reps: 6, Weight: 90, RPE: 7}, {Reps: 5, Weight: 95, RPE: 8}}},
+	}}
+	if err := store.SaveWorkout(userID, workout); err != nil {
+		return err
+	}
+	last, err := store.GetLastPerformance(userID, template.Exercises[0].CatalogID)
+	if err != nil {
+		return err
+	}
+	if last.WorkoutID != workout.ID || len(last.Sets) != 2 || last.Sets[1].Weight != 95 {
+		return fmt.Errorf("GetLastPerformance returned %+v", last)
+	}
+
+	draft, err := services.NewTemplateService(store).StartWorkout(userID, template.ID)
+	if err != nil {
+		return err
+	}
+	if len(draft.Workout.Exercises) != 2 || len(draft.Workout.Exercises[0].Sets) != 3 {
+		return fmt.Errorf("StartWorkout returned %+v", draft.Workout)
+	}
+	if sets := draft.Workout.Exercises[0].Sets; sets[0].Weight != 90 || sets[2].Weight != 95 || sets[2].Reps != 5 {
+		return fmt.Errorf("StartWorkout did not pre-fill the last performance: %+v", sets)
+	}
+	if sets := draft.Workout.Exercises[1].Sets; sets[0].Reps != 8 || sets[0].Weight != 0 {
+		return fmt.Errorf("StartWorkout without history: %+v", sets)
+	}
+
+	template.Name = "Push Day B"
+	template.Exercises = template.Exercises[:1]
+	if err := store.ReplaceTemplate(userID, template.ID, template); err != nil {
+		return err
+	}
+	replaced, err := store.GetTemplate(userID, template.ID)
+	if err != nil {
+		return err
+	}
+	if replaced.Name != "Push Day B" || len(replaced.Exercises) != 1 {
+		return fmt.Errorf("ReplaceTemplate left %+v", replaced)
+	}
+	if err := expectNotFound("ReplaceTemplate of another user's template", store.ReplaceTemplate(otherID, template.ID, template)); err != nil {
+		return err
+	}
+	if err := expectNotFound("DeleteTemplate of another user's template", store.DeleteTemplate(otherID, template.ID)); err != nil {
+		return err
+	}
+	if err := store.DeleteTemplate(userID, template.ID); err != nil {
+		return err
+	}
+	_, err = store.GetTemplate(userID, template.ID)
+	return expectNotFound("GetTemplate after DeleteTemplate", err)
+}
+
+func checkPrograms(store services.Store) error {
+	userID, err := createUser(store, "conformance_programs")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_programs_other")
+	if err != nil {
+		return err
+	}
+	programService := services.NewProgramService(store)
+
+	_, err = store.GetActiveProgram(userID)
+	if err := expectNotFound("GetActiveProgram before enrolling", err); err != nil {
+		return err
+	}
+	if _, err := programService.Enroll(userID, "linear", map[string]float64{"squat": 100}); !errors.Is(err, services.ErrTrainingMaxRequired) {
+		return fmt.Errorf("enrolling without history or training maxes: want ErrTrainingMaxRequired, got %v", err)
+	}
+
+	//bench, press and deadlift are estimated from history, squat is given
+	history := &models.Workout{Name: "Testing", Exercises: []models.ExerciseLog{
+		{Exercise: "Bench Press", Sets: []models.Set{{Reps: 5, Weight: 75}}},
+		{Exercise: "Overhead Press", Sets: []models.Set{{Reps: 5, Weight: 45}}},
+		{Exercise: "Deadlift", Sets: []models.Set{{Reps: 5, Weight: 120}}},
+	}}
+	if err := store.SaveWorkout(userID, history); err != nil {
+		return err
+	}
+	active, err := programService.Enroll(userID, "linear", map[string]float64{"squat": 100})
+	if err != nil {
+		return err
+	}
+	if active.Next.DayName != "A" || len(active.Next.Exercises) != 3 || active.Next.Exercises[0].Sets[0].Weight != 100 {
+		return fmt.Errorf("first session: %+v", active.Next)
+	}
+	if bench := active.Next.Exercises[1].Sets[0].Weight; bench != 75 {
+		return fmt.Errorf("bench estimated from a 75x5 starts at %v", bench)
+	}
+
+	//squat and bench as prescribed, deadlift missed
+	session := &models.Workout{Name: "Day A", Exercises: []models.ExerciseLog{
+		{Exercise: "Back Squat", Sets: []models.Set{{Reps: 5, Weight: 60}, {Reps: 5, Weight: 100}, {Reps: 5, Weight: 100}, {Reps: 5, Weight: 100}}},
+		{Exercise: "Bench Press", Sets: []models.Set{{Reps: 5, Weight: 75}, {Reps: 5, Weight: 75}, {Reps: 5, Weight: 75}}},
+		{Exercise: "Deadlift", Sets: []models.Set{{Reps: 3, Weight: 120}}},
+	}}
+	if err := store.SaveWorkout(userID, session); err != nil {
+		return err
+	}
+	result, err := programService.CompleteSession(userID, session.ID)
+	if err != nil {
+		return err
+	}
+	outcomes := make(map[string]models.LiftProgress)
+	for _, progress := range result.Progress {
+		outcomes[progress.Lift] = progress
+	}
+	if outcomes["squat"].Outcome != models.OutcomeProgressed || outcomes["squat"].TrainingMax != 102.5 || outcomes["deadlift"].Outcome != models.OutcomeFailed {
+		return fmt.Errorf("progress after day A: %+v", result.Progress)
+	}
+	if result.Next.DayName != "B" || result.Next.Exercises[0].Sets[0].Weight != 102.5 {
+		return fmt.Errorf("session after day A: %+v", result.Next)
+	}
+	if _, err := programService.CompleteSession(userID, session.ID); !errors.Is(err, services.ErrSessionRecorded) {
+		return fmt.Errorf("completing a session twice with one workout: want ErrSessionRecorded, got %v", err)
+	}
+
+	stored, err := store.GetActiveProgram(userID)
+	if err != nil {
+		return err
+	}
+	if stored.Day != 2 || len(stored.Lifts) != 4 {
+		return fmt.Errorf("stored enrollment: %+v", stored)
+	}
+	for _, lift := range stored.Lifts {
+		if lift.Lift == "deadlift" && (lift.Failures != 1 || lift.TrainingMax != outcomes["deadlift"].TrainingMax) {
+			return fmt.Errorf("stored deadlift state: %+v", lift)
+		}
+	}
+	if _, err := programService.CompleteSession(otherID, session.ID); !errors.Is(err, services.ErrNotFound) {
+		return fmt.Errorf("completing a session without a program: want ErrNotFound, got %v", err)
+	}
+
+	if _, err := programService.Enroll(userID, "531", map[string]float64{"squat": 140, "bench": 100, "deadlift": 180, "press": 60}); err != nil {
+		return err
+	}
+	replaced, err := store.GetActiveProgram(userID)
+	if err != nil {
+		return err
+	}
+	if replaced.ProgramKey != "531" || replaced.Day != 1 {
+		return fmt.Errorf("enrolling again did not replace the program: %+v", replaced)
+	}
+	if err := store.EndProgram(userID); err != nil {
+		return err
+	}
+	return expectNotFound("EndProgram without a program", store.EndProgram(userID))
+}
+
+func checkProfiles(store services.Store) error {
+	userID, err := createUser(store, "conformance_profile")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_profile_other")
+	if err != nil {
+		return err
+	}
+
+	_, err = store.GetUserProfile(userID)
+	if err := expectNotFound("GetUserProfile before saving", err); err != nil {
+		return err
+	}
+	profile := &models.UserProfile{Height: 180, Weight: 82, Bodyfat: 18.5, TargetWeight: 78, Sex: models.SexFemale}
+	if err := store.SaveUserProfile(userID, profile); err != nil {
+		return err
+	}
+	profile.Weight = 81
+	if err := store.SaveUserProfile(userID, profile); err != nil {
+		return err
+	}
+	loaded, err := store.GetUserProfile(userID)
+	if err != nil {
+		return err
+	}
+	if loaded.Weight != 81 || loaded.Bodyfat != 18.5 || loaded.TargetWeight != 78 || loaded.Sex != models.SexFemale || loaded.UserID != userID {
+		return fmt.Errorf("GetUserProfile = %+v", loaded)
+	}
+	//bodyfat and target weight can be cleared again
+	cleared := &models.UserProfile{Height: 180, Weight: 81, Sex: models.SexFemale}
+	if err := store.SaveUserProfile(userID, cleared); err != nil {
+		return err
+	}
+	loaded, err = store.GetUserProfile(userID)
+	if err != nil {
+		return err
+	}
+	if loaded.Bodyfat != 0 || loaded.TargetWeight != 0 {
+		return fmt.Errorf("GetUserProfile after clearing = %+v", loaded)
+	}
+
+	//both profile saves changed the weight, so both appended a measurement
+	measurements, err := store.GetMeasurements(userID, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	if len(measurements) != 2 {
+		return fmt.Errorf("profile saves appended %d measurements, want 2", len(measurements))
+	}
+
+	monday := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i, weight := range []float64{80, 79, 78.5} {
+		w := weight
+		waist := 85 - float64(i)
+		measurement := &models.BodyMeasurement{MeasuredAt: monday.AddDate(0, 0, 7*i), Weight: &w, Waist: &waist, Notes: "check-in"}
+		if err := store.AddMeasurement(userID, measurement); err != nil {
+			return err
+		}
+		if measurement.ID == 0 {
+			return fmt.Errorf("AddMeasurement did not fill in the id")
+		}
+	}
+
+	negative := -1.0
+	for _, measurement := range []*models.BodyMeasurement{{Notes: "nothing measured"}, {Waist: &negative}} {
+		if err := store.AddMeasurement(userID, measurement); !errors.Is(err, services.ErrInvalidMeasurement) {
+			return fmt.Errorf("AddMeasurement(%+v): want ErrInvalidMeasurement, got %v", measurement, err)
+		}
+	}
+
+	january, err := store.GetMeasurements(userID, monday, monday.AddDate(0, 0, 8))
+	if err != nil {
+		return err
+	}
+	if len(january) != 2 || *january[0].Weight != 80 || *january[1].Weight != 79 || january[0].Neck != nil {
+		return fmt.Errorf("GetMeasurements in range = %+v", january)
+	}
+	if !january[0].MeasuredAt.Equal(monday) {
+		return fmt.Errorf("measured_at = %v, want %v", january[0].MeasuredAt, monday)
+	}
+
+	trend, err := store.GetMeasurementTrend(userID, "waist", analytics.PeriodWeek, monday, monday.AddDate(0, 1, 0))
+	if err != nil {
+		return err
+	}
+	if len(trend.Points) != 3 || trend.Change != -2 {
+		return fmt.Errorf("waist trend = %+v", trend)
+	}
+
+	if err := expectNotFound("DeleteMeasurement of another user", store.DeleteMeasurement(otherID, january[0].ID)); err != nil {
+		return err
+	}
+	if err := store.DeleteMeasurement(userID, january[0].ID); err != nil {
+		return err
+	}
+	others, err := store.GetMeasurements(otherID, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	if len(others) != 0 {
+		return fmt.Errorf("GetMeasurements returned another user's measurements")
+	}
+	return nil
+}
+
+func checkAnalytics(store services.Store) error {
+	userID, err := createUser(store, "conformance_analytics")
+	if err != nil {
+		return err
+	}
+
+	first := sampleWorkout()
+	if err := store.SaveWorkout(userID, first); err != nil {
+		return err
+	}
+	second := sampleWorkout()
+	second.Exercises[0].Sets[0].Weight = 110
+	if err := store.SaveWorkout(userID, second); err != nil {
+		return err
+	}
+
+	setRep, err := store.GetSetRep(userID, "bench press", 5)
+	if err != nil {
+		return err
+	}
+	if setRep.Weight != 110 {
+		return fmt.Errorf("GetSetRep(5) = %v, want 110", setRep.Weight)
+	}
+	_, err = store.GetSetRep(userID, "bench press", 20)
+	if err := expectNotFound("GetSetRep without a matching set", err); err != nil {
+		return err
+	}
+
+	history, err := store.GetSetHistory(userID, "BENCH PRESS")
+	if err != nil {
+		return err
+	}
+	if len(history) != 6 || history[0].WorkoutID != first.ID || history[5].WorkoutID != second.ID || history[1].SetNumber != 2 {
+		return fmt.Errorf("GetSetHistory = %+v", history)
+	}
+	all, err := store.GetSetHistory(userID, "")
+	if err != nil {
+		return err
+	}
+	if len(all) != 8 {
+		return fmt.Errorf("GetSetHistory for all exercises returned %d sets, want 8", len(all))
+	}
+
+	records := []models.PersonalRecord{
+		{UserID: userID, WorkoutID: second.ID, SetID: second.Exercises[0].Sets[0].ID, Exercise: "Bench Press", RecordType: analytics.RecordWeightAtReps, Reps: 5, Weight: 110, Value: 110, PreviousValue: 102.5, AchievedAt: time.Now().UTC()},
+		{UserID: userID, WorkoutID: second.ID, Exercise: "Bench Press", RecordType: analytics.RecordSessionVolume, Value: 1652.5, PreviousValue: 1932.5, AchievedAt: time.Now().UTC()},
+	}
+	if err := store.ReplacePersonalRecords(userID, []string{"Bench Press"}, records); err != nil {
+		return err
+	}
+	if records[0].ID == 0 || records[1].ID == 0 {
+		return fmt.Errorf("ReplacePersonalRecords did not fill in ids")
+	}
+	saved, err := store.GetPersonalRecords(userID, "bench press", analytics.RecordWeightAtReps)
+	if err != nil {
+		return err
+	}
+	if len(saved) != 1 || saved[0].SetID != records[0].SetID || saved[0].Weight != 110 {
+		return fmt.Errorf("GetPersonalRecords by type = %+v", saved)
+	}
+	saved, err = store.GetPersonalRecords(userID, "", "")
+	if err != nil {
+		return err
+	}
+	if len(saved) != 2 {
+		return fmt.Errorf("GetPersonalRecords returned %d records, want 2", len(saved))
+	}
+
+	//replacing matches the exercise case insensitively, leaves other exercises alone
+	//and keeps the rows of records that did not change
+	kept := records[0].ID
+	if err := store.ReplacePersonalRecords(userID, []string{"Squat"}, nil); err != nil {
+		return err
+	}
+	if err := store.ReplacePersonalRecords(userID, []string{"bench press"}, records[:1]); err != nil {
+		return err
+	}
+	saved, err = store.GetPersonalRecords(userID, "", "")
+	if err != nil {
+		return err
+	}
+	if len(saved) != 1 || saved[0].RecordType != analytics.RecordWeightAtReps || saved[0].ID != kept || records[0].ID != kept {
+		return fmt.Errorf("GetPersonalRecords after replacing = %+v, want the record with id %d", saved, kept)
+	}
+	return nil
+}
+
+func checkVolumeLandmarks(store services.Store) error {
+	userID, err := createUser(store, "conformance_landmarks")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_landmarks_other")
+	if err != nil {
+		return err
+	}
+
+	landmarks, err := store.GetVolumeLandmarks(userID)
+	if err != nil {
+		return err
+	}
+	if len(landmarks) != 0 {
+		return fmt.Errorf("GetVolumeLandmarks before saving = %+v", landmarks)
+	}
+	if err := store.SaveVolumeLandmarks(userID, []models.VolumeLandmark{{Muscle: "quads", MEV: 6, MRV: 18}, {Muscle: "chest", MEV: 10, MRV: 20}}); err != nil {
+		return err
+	}
+	if err := store.SaveVolumeLandmarks(otherID, []models.VolumeLandmark{{Muscle: "chest", MEV: 4, MRV: 12}}); err != nil {
+		return err
+	}
+
+	//saving again replaces every landmark of the user
+	if err := store.SaveVolumeLandmarks(userID, []models.VolumeLandmark{{Muscle: "chest", MEV: 12, MRV: 24.5}}); err != nil {
+		return err
+	}
+	landmarks, err = store.GetVolumeLandmarks(userID)
+	if err != nil {
+		return err
+	}
+	if len(landmarks) != 1 || landmarks[0].Muscle != "chest" || landmarks[0].MEV != 12 || landmarks[0].MRV != 24.5 || !landmarks[0].Custom {
+		return fmt.Errorf("GetVolumeLandmarks after replacing = %+v", landmarks)
+	}
+	landmarks, err = store.GetVolumeLandmarks(otherID)
+	if err != nil {
+		return err
+	}
+	if len(landmarks) != 1 || landmarks[0].MEV != 4 {
+		return fmt.Errorf("GetVolumeLandmarks of another user = %+v", landmarks)
+	}
+	return nil
+}
+
+func checkInsights(store services.Store) error {
+	userID, err := createUser(store, "conformance_insights")
+	if err != nil {
+		return err
+	}
+	otherID, err := createUser(store, "conformance_insights_other")
+	if err != nil {
+		return err
+	}
+	workout := sampleWorkout()
+	if err := store.SaveWorkout(userID, workout); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	bench := models.Insight{
+		Exercise: workout.Exercises[0].Exercise, CatalogID: workout.Exercises[0].CatalogID, Kind: models.InsightPlateau,
+		PeakWorkoutID: workout.ID, PeakAt: now.AddDate(0, 0, -30), PeakOneRepMax: 120, CurrentOneRepMax: 118.5, ChangePercent: -1.3,
+		SessionsSince: 5, WeeksSince: 4, Interventions: []models.Intervention{{Kind: models.InterventionDeload, Description: "deload"}},
+	}
+	squat := models.Insight{Exercise: "Unlinked Squat", Kind: models.InsightRegression, PeakWorkoutID: workout.ID, PeakAt: now, PeakOneRepMax: 150, CurrentOneRepMax: 130}
+	if err := store.SaveInsights(userID, []models.Insight{bench, squat}, now); err != nil {
+		return err
+	}
+	insights, err := store.GetInsights(userID, models.InsightFilter{})
+	if err != nil {
+		return err
+	}
+	if len(insights) != 2 {
+		return fmt.Errorf("GetInsights returned %d insights, want 2", len(insights))
+	}
+
+	//scanning again updates the open insight, resolves the one no longer found
+	bench.CurrentOneRepMax, bench.SessionsSince = 117, 6
+	if err := store.SaveInsights(userID, []models.Insight{bench}, now.Add(time.Hour)); err != nil {
+		return err
+	}
+	insights, err = store.GetInsights(userID, models.InsightFilter{Exercise: "bench press"})
+	if err != nil {
+		return err
+	}
+	if len(insights) != 1 || insights[0].CurrentOneRepMax != 117 || insights[0].SessionsSince != 6 || insights[0].ResolvedAt != nil ||
+		len(insights[0].Interventions) != 1 || insights[0].Interventions[0].Kind != models.InterventionDeload {
+		return fmt.Errorf("GetInsights after rescanning = %+v", insights)
+	}
+	insights, err = store.GetInsights(userID, models.InsightFilter{Kind: models.InsightRegression, IncludeResolved: true})
+	if err != nil {
+		return err
+	}
+	if len(insights) != 1 || insights[0].Exercise != "Unlinked Squat" || insights[0].ResolvedAt == nil {
+		return fmt.Errorf("GetInsights of resolved regressions = %+v", insights)
+	}
+	insights, err = store.GetInsights(userID, models.InsightFilter{Kind: models.InsightRegression})
+	if err != nil {
+		return err
+	}
+	if len(insights) != 0 {
+		return fmt.Errorf("GetInsights listed %d resolved insights without asking for them", len(insights))
+	}
+
+	//an empty scan resolves everything, and never reaches other users
+	if err := store.SaveInsights(otherID, nil, now); err != nil {
+		return err
+	}
+	insights, err = store.GetInsights(userID, models.InsightFilter{})
+	if err != nil {
+		return err
+	}
+	if len(insights) != 1 {
+		return fmt.Errorf("scanning another user resolved %d insights", 1-len(insights))
+	}
+	if err := store.SaveInsights(userID, nil, now); err != nil {
+		return err
+	}
+	insights, err = store.GetInsights(userID, models.InsightFilter{})
+	if err != nil {
+		return err
+	}
+	if len(insights) != 0 {
+		return fmt.Errorf("GetInsights after an empty scan = %+v", insights)
+	}
+	return nil
+}
+
+func checkPlateInventories(store services.Store) error {
+	userID, err := createUser(store, "conformance_plates")
+	if err != nil {
+		return err
+	}
+
+	_, err = store.GetPlateInventory(userID)
+	if err := expectNotFound("GetPlateInventory before saving", err); err != nil {
+		return err
+	}
+	inventory := models.DefaultPlateInventory(models.UnitKg)
+	if err := store.SavePlateInventory(userID, &inventory); err != nil {
+		return err
+	}
+	inventory = models.PlateInventory{Unit: models.UnitLb, BarWeight: 35, Plates: []models.PlateCount{{Weight: 45, Count: 4}, {Weight: 2.5, Count: 2}}}
+	if err := store.SavePlateInventory(userID, &inventory); err != nil {
+		return err
+	}
+	loaded, err := store.GetPlateInventory(userID)
+	if err != nil {
+		return err
+	}
+	if loaded.Unit != models.UnitLb || loaded.BarWeight != 35 || len(loaded.Plates) != 2 || loaded.Plates[1].Weight != 2.5 || loaded.Plates[0].Count != 4 {
+		return fmt.Errorf("GetPlateInventory after replacing = %+v", loaded)
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"the-gym-app/internal/analytics"
+	"the-gym-app/internal/models"
+	"the-gym-app/internal/plates"
+	"time"
+)
+
+// TemplateService starts workouts from saved templates.
+type TemplateService struct {
+	dbService Store
+}
+
+func NewTemplateService(dbService Store) *TemplateService {
+	return &TemplateService{dbService: dbService}
+}
+
+// StartWorkout builds a draft workout from a template. Every planned exercise gets
+// its target number of sets, filled in with the reps and weights of the last time
+// the user performed that exercise; without history the sets start at the bottom of
+// the rep range with no weight. The sets are not completed until the user ticks them.
+// Groups of the template are carried over to the draft.
+func (t *TemplateService) StartWorkout(userID, templateID int) (*models.WorkoutDraft, error) {
+	template, err := t.dbService.GetTemplate(userID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	draft := &models.WorkoutDraft{
+		TemplateID: template.ID,
+		Workout:    models.Workout{Name: template.Name, UserID: userID, Exercises: []models.ExerciseLog{}, Groups: template.Groups},
+		Plan:       template.Exercises,
+	}
+	for _, planned := range template.Exercises {
+		last, err := t.dbService.GetLastPerformance(userID, planned.CatalogID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		draft.Workout.Exercises = append(draft.Workout.Exercises, prefillExercise(planned, last))
+	}
+	return draft, nil
+}
+
+// Recommendations suggests the next session of every planned exercise of a template
+// from the user's history, aiming for the rep range, sets and RPE the template plans.
+// increment is the weight jump to use, or 0 for the default.
+func (t *TemplateService) Recommendations(userID, templateID int, increment float64) ([]models.OverloadRecommendation, error) {
+	template, err := t.dbService.GetTemplate(userID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := make([]models.OverloadRecommendation, 0, len(template.Exercises))
+	for _, planned := range template.Exercises {
+		target := models.OverloadTarget{Sets: planned.TargetSets, MinReps: planned.MinReps, MaxReps: planned.MaxReps, TargetRPE: planned.TargetRPE, Increment: increment}
+		if err := models.ValidateOverloadTarget(&target); err != nil {
+			return nil, err
+		}
+		history, err := t.dbService.GetSetHistory(userID, planned.Exercise)
+		if err != nil {
+			return nil, err
+		}
+		recommendation := analytics.RecommendNext(history, target)
+		recommendation.Exercise = planned.Exercise
+		recommendation.CatalogID = planned.CatalogID
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations, nil
+}
+
+// AddWarmups puts a warm-up ramp, loadable with the user's plates, before the sets of
+// every barbell exercise of a draft. The ramp leads up to the first working set with
+// a weight; exercises without one are left alone.
+func (t *TemplateService) AddWarmups(userID int, draft *models.WorkoutDraft) error {
+	inventory, err := plateInventory(t.dbService, userID)
+	if err != nil {
+		return err
+	}
+	for i := range draft.Workout.Exercises {
+		exercise := &draft.Workout.Exercises[i]
+		catalog, err := t.dbService.GetCatalogExercise(userID, exercise.CatalogID)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if catalog.Equipment != "barbell" {
+			continue
+		}
+		var working *models.Set
+		for j := range exercise.Sets {
+			if exercise.Sets[j].Type == models.SetTypeWorking && exercise.Sets[j].Weight > 0 {
+				working = &exercise.Sets[j]
+				break
+			}
+		}
+		if working == nil {
+			continue
+		}
+
+		warmups := plates.Warmups(working.Weight, working.Reps, inventory)
+		sets := make([]models.Set, 0, len(warmups)+len(exercise.Sets))
+		for _, warmup := range warmups {
+			sets = append(sets, models.Set{Reps: warmup.Reps, Weight: warmup.Weight, Type: models.SetTypeWarmup, Completed: new(bool)})
+		}
+		sets = append(sets, exercise.Sets...)
+		for n := range sets {
+			sets[n].SetNumber = n + 1
+		}
+		exercise.Sets = sets
+	}
+	return nil
+}
+
+// prefillExercise plans the sets of one exercise. Set n repeats working set n of the
+// last performance, or its final working set when fewer sets were done then.
+func prefillExercise(planned models.TemplateExercise, last *models.ExerciseLog) models.ExerciseLog {
+	var done []models.Set
+	if last != nil {
+		for _, set := range last.Sets {
+			if set.Counted() {
+				done = append(done, set)
+			}
+		}
+	}
+
+	exercise := models.ExerciseLog{CatalogID: planned.CatalogID, Exercise: planned.Exercise, Group: planned.Group, Sets: []models.Set{}}
+	for n := 0; n < planned.TargetSets; n++ {
+		set := models.Set{Reps: planned.MinReps, RPE: planned.TargetRPE, SetNumber: n + 1, Type: models.SetTypeWorking, Completed: new(bool)}
+		if len(done) > 0 {
+			previous := done[len(done)-1]
+			if n < len(done) {
+				previous = done[n]
+			}
+			set.Reps = previous.Reps
+			set.Weight = previous.Weight
+			if set.RPE == 0 {
+				set.RPE = previous.RPE
+			}
+		}
+		exercise.Sets = append(exercise.Sets, set)
+	}
+	return exercise
+}
+
+// SaveTemplate stores a new template for the user, resolving its exercises against the catalog.
+func (s *DatabaseService) SaveTemplate(userID int, template *models.WorkoutTemplate) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	templateID, err := tx.insertID(
+		"INSERT INTO workout_templates (user_id, name, notes, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		userID, template.Name, template.Notes, now, now,
+	)
+	if err != nil {
+		return err
+	}
+	if err := insertTemplateExercises(tx, userID, templateID, template.Exercises); err != nil {
+		return err
+	}
+	if err := insertGroups(tx, templateGroups, templateID, template.Groups); err != nil {
+		return err
+	}
+	template.ID = templateID
+	template.UserID = userID
+	template.CreatedAt = now
+	template.UpdatedAt = now
+
+	return tx.Commit()
+}
+
+// GetTemplates returns all templates of the user with their planned exercises, by name.
+func (s *DatabaseService) GetTemplates(userID int) ([]models.WorkoutTemplate, error) {
+	rows, err := s.db.Query(
+		"SELECT id, user_id, name, notes, created_at, updated_at FROM workout_templates WHERE user_id = ? ORDER BY name, id",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	templates := []models.WorkoutTemplate{}
+	for rows.Next() {
+		var template models.WorkoutTemplate
+		if err := rows.Scan(&template.ID, &template.UserID, &template.Name, &template.Notes, &template.CreatedAt, &template.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		template.Exercises = []models.TemplateExercise{}
+		templates = append(templates, template)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	//one query for the exercises of every template instead of one per template
+	exercises, err := s.loadTemplateExercises(`
+		SELECT te.template_id, `+templateExerciseColumns+` FROM template_exercises te
+		JOIN workout_templates t on te.template_id = t.id
+		WHERE t.user_id = ?
+		ORDER BY te.template_id, te.position`, userID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.loadGroups(templateGroups, "g.template_id IN (SELECT id FROM workout_templates WHERE user_id = ?)", userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if planned, ok := exercises[templates[i].ID]; ok {
+			templates[i].Exercises = planned
+		}
+		templates[i].Groups = groups[templates[i].ID]
+	}
+	return templates, nil
+}
+
+// GetTemplate returns a single template if it belongs to userID.
+func (s *DatabaseService) GetTemplate(userID, templateID int) (*models.WorkoutTemplate, error) {
+	var template models.WorkoutTemplate
+	err := s.db.QueryRow(
+		"SELECT id, user_id, name, notes, created_at, updated_at FROM workout_templates WHERE id = ? AND user_id = ?",
+		templateID, userID,
+	).Scan(&template.ID, &template.UserID, &template.Name, &template.Notes, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	exercises, err := s.loadTemplateExercises(
+		"SELECT te.template_id, "+templateExerciseColumns+" FROM template_exercises te WHERE te.template_id = ? ORDER BY te.position",
+		templateID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	template.Exercises = exercises[templateID]
+	if template.Exercises == nil {
+		template.Exercises = []models.TemplateExercise{}
+	}
+	groups, err := s.loadGroups(templateGroups, "g.template_id = ?", templateID)
+	if err != nil {
+		return nil, err
+	}
+	template.Groups = groups[templateID]
+	return &template, nil
+}
+
+// ReplaceTemplate overwrites the name, notes, planned exercises and groups of a template.
+func (s *DatabaseService) ReplaceTemplate(userID, templateID int, template *models.WorkoutTemplate) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTemplateOwner(tx, userID, templateID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"UPDATE workout_templates SET name = ?, notes = ?, updated_at = ? WHERE id = ?",
+		template.Name, template.Notes, time.Now().UTC(), templateID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := deleteTemplateChildren(tx, templateID); err != nil {
+		return err
+	}
+	if err := insertTemplateExercises(tx, userID, templateID, template.Exercises); err != nil {
+		return err
+	}
+	if err := insertGroups(tx, templateGroups, templateID, template.Groups); err != nil {
+		return err
+	}
+	template.ID = templateID
+	template.UserID = userID
+
+	return tx.Commit()
+}
+
+// DeleteTemplate removes a template. Workouts started from it are not affected.
+func (s *DatabaseService) DeleteTemplate(userID, templateID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTemplateOwner(tx, userID, templateID); err != nil {
+		return err
+	}
+	if err := deleteTemplateChildren(tx, templateID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM workout_templates WHERE id = ?", templateID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetLastPerformance returns the most recent logged entry of a catalog exercise with
+// its sets, or ErrNotFound if the user never performed it.
+func (s *DatabaseService) GetLastPerformance(userID, catalogID int) (*models.ExerciseLog, error) {
+	var exercise models.ExerciseLog
+	err := s.db.QueryRow(`
+		SELECT e.id, e.exercise, COALESCE(e.catalog_id, 0), e.workout_id, e.created_at FROM exercises e
+		JOIN workouts w on e.workout_id = w.id
+		WHERE w.user_id = ? AND e.catalog_id = ?
+		ORDER BY w.created_at DESC, w.id DESC, e.id DESC
+		LIMIT 1
+	`, userID, catalogID).Scan(&exercise.ID, &exercise.Exercise, &exercise.CatalogID, &exercise.WorkoutID, &exercise.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	exercise.Sets, err = s.loadSets(exercise.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &exercise, nil
+}
+
+const templateExerciseColumns = "te.id, te.catalog_id, te.exercise, te.group_label, te.position, te.target_sets, te.min_reps, te.max_reps, te.target_rpe, te.rest_seconds, te.notes"
+
+// loadTemplateExercises runs query, which selects the template id followed by
+// templateExerciseColumns, and groups the rows by template.
+func (s *DatabaseService) loadTemplateExercises(query string, args ...interface{}) (map[int][]models.TemplateExercise, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exercises := make(map[int][]models.TemplateExercise)
+	for rows.Next() {
+		var templateID int
+		var e models.TemplateExercise
+		err := rows.Scan(&templateID, &e.ID, &e.CatalogID, &e.Exercise, &e.Group, &e.Position, &e.TargetSets, &e.MinReps, &e.MaxReps, &e.TargetRPE, &e.RestSeconds, &e.Notes)
+		if err != nil {
+			return nil, err
+		}
+		exercises[templateID] = append(exercises[templateID], e)
+	}
+	return exercises, rows.Err()
+}
+
+func insertTemplateExercises(tx *Tx, userID, templateID int, exercises []models.TemplateExercise) error {
+	for i := range exercises {
+		e := &exercises[i]
+		logged := models.ExerciseLog{CatalogID: e.CatalogID, Exercise: e.Exercise}
+		if err := resolveExercise(tx, userID, &logged); err != nil {
+			return err
+		}
+		e.CatalogID = logged.CatalogID
+		e.Exercise = logged.Exercise
+		e.Position = i + 1
+
+		id, err := tx.insertID(`
+			INSERT INTO template_exercises (template_id, catalog_id, exercise, group_label, position, target_sets, min_reps, max_reps, target_rpe, rest_seconds, notes)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, templateID, e.CatalogID, e.Exercise, e.Group, e.Position, e.TargetSets, e.MinReps, e.MaxReps, e.TargetRPE, e.RestSeconds, e.Notes)
+		if err != nil {
+			return err
+		}
+		e.ID = id
+	}
+	return nil
+}
+
+func deleteTemplateChildren(tx *Tx, templateID int) error {
+	if _, err := tx.Exec("DELETE FROM template_groups WHERE template_id = ?", templateID); err != nil {
+		return err
+	}
+	_, err := tx.Exec("DELETE FROM template_exercises WHERE template_id = ?", templateID)
+	return err
+}
+
+func checkTemplateOwner(tx *Tx, userID, templateID int) error {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM workout_templates WHERE id = ? AND user_id = ?", templateID, userID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
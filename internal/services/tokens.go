@@ -0,0 +1,176 @@
+package services
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTokenExpired is returned when a refresh token is past its expiry.
+	ErrTokenExpired = errors.New("refresh token expired")
+	// ErrTokenReused is returned when an already rotated or revoked refresh token is
+	// presented again. The whole token family is revoked when this happens.
+	ErrTokenReused = errors.New("refresh token reused")
+)
+
+// RefreshToken is the stored state of an issued refresh token.
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	Username  string
+	FamilyID  string
+	ExpiresAt time.Time
+}
+
+// HashToken returns the hex encoded sha256 of a token, which is what gets stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveRefreshToken stores the hash of a newly issued refresh token.
+func (s *DatabaseService) SaveRefreshToken(userID int, token, familyID string, expiresAt time.Time) error {
+	_, err := s.db.Exec(
+		"INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at) VALUES (?, ?, ?, ?)",
+		userID, HashToken(token), familyID, expiresAt.UTC(),
+	)
+	return err
+}
+
+// RotateRefreshToken swaps oldToken for newToken in the same family. Presenting a token
+// that was already rotated or revoked is treated as theft and revokes the whole family.
+func (s *DatabaseService) RotateRefreshToken(oldToken, newToken string, expiresAt time.Time) (*RefreshToken, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var current RefreshToken
+	var revokedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT r.id, r.user_id, u.username, r.family_id, r.expires_at, r.revoked_at
+		FROM refresh_tokens r
+		JOIN users u on r.user_id = u.id
+		WHERE r.token_hash = ?
+	`, HashToken(oldToken)).Scan(&current.ID, &current.UserID, &current.Username, &current.FamilyID, &current.ExpiresAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		return nil, reuseDetected(tx, current.FamilyID)
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+
+	//claim the old token before issuing a new one: of two concurrent refreshes with the
+	//same token only one can flip revoked_at, the other sees no row and counts as reuse
+	result, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now().UTC(), current.ID)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if claimed == 0 {
+		return nil, reuseDetected(tx, current.FamilyID)
+	}
+
+	newID, err := tx.insertID(
+		"INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at) VALUES (?, ?, ?, ?)",
+		current.UserID, HashToken(newToken), current.FamilyID, expiresAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE refresh_tokens SET replaced_by = ? WHERE id = ?", newID, current.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &RefreshToken{
+		ID:        newID,
+		UserID:    current.UserID,
+		Username:  current.Username,
+		FamilyID:  current.FamilyID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// RevokeRefreshTokenFamily revokes every token issued from the same login as token,
+// provided it belongs to userID.
+func (s *DatabaseService) RevokeRefreshTokenFamily(userID int, token string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var familyID string
+	err = tx.QueryRow(
+		"SELECT family_id FROM refresh_tokens WHERE token_hash = ? AND user_id = ?",
+		HashToken(token), userID,
+	).Scan(&familyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := revokeFamily(tx, familyID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeAccessToken blacklists an access token by its jwt id until it would have expired.
+func (s *DatabaseService) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	//expired entries can never match a valid token again, so prune them here
+	if _, err := s.db.Exec("DELETE FROM revoked_tokens WHERE expires_at < ?", time.Now().UTC()); err != nil {
+		return err
+	}
+	_, err := s.db.Exec("INSERT INTO revoked_tokens (jti, expires_at) VALUES (?, ?) ON CONFLICT (jti) DO NOTHING", jti, expiresAt.UTC())
+	return err
+}
+
+// IsTokenRevoked reports whether the access token with the given jwt id was revoked.
+func (s *DatabaseService) IsTokenRevoked(jti string) (bool, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM revoked_tokens WHERE jti = ?", jti).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// reuseDetected revokes the family of a token that was presented again and commits
+// tx, returning ErrTokenReused unless that fails.
+func reuseDetected(tx *Tx, familyID string) error {
+	if err := revokeFamily(tx, familyID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return ErrTokenReused
+}
+
+func revokeFamily(tx *Tx, familyID string) error {
+	_, err := tx.Exec(
+		"UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL",
+		time.Now().UTC(), familyID,
+	)
+	return err
+}
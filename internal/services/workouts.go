@@ -0,0 +1,455 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"the-gym-app/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when a row does not exist or is not owned by the calling user.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidSet wraps the reason a set was rejected by models.ValidateSet.
+	ErrInvalidSet = errors.New("invalid set")
+	// ErrInvalidGroup wraps the reason the exercise groups of a workout were rejected.
+	ErrInvalidGroup = errors.New("invalid exercise group")
+)
+
+// GetWorkout returns a single workout with its exercises and sets if it belongs to userID.
+func (s *DatabaseService) GetWorkout(userID, workoutID int) (*models.Workout, error) {
+	var workout models.Workout
+	err := s.db.QueryRow(
+		"SELECT id, workout_name, created_at, user_id FROM workouts WHERE id = ? AND user_id = ?",
+		workoutID, userID,
+	).Scan(&workout.ID, &workout.Name, &workout.CreatedAt, &workout.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	workouts := []models.Workout{workout}
+	if err := s.loadWorkoutDetails(workouts, "w.id = ?", workout.ID); err != nil {
+		return nil, err
+	}
+	return &workouts[0], nil
+}
+
+// ReplaceWorkout overwrites the name and the full exercise/set structure of a workout,
+// including its groups.
+func (s *DatabaseService) ReplaceWorkout(userID, workoutID int, workout *models.Workout) error {
+	if err := validateWorkoutGroups(workout); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkWorkoutOwner(tx, userID, workoutID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE workouts SET workout_name = ? WHERE id = ?", workout.Name, workoutID); err != nil {
+		return err
+	}
+	if err := deleteWorkoutChildren(tx, workoutID); err != nil {
+		return err
+	}
+	if err := insertExercises(tx, userID, workoutID, workout.Exercises); err != nil {
+		return err
+	}
+	if err := insertGroups(tx, workoutGroups, workoutID, workout.Groups); err != nil {
+		return err
+	}
+	workout.ID = workoutID
+	workout.UserID = userID
+
+	return tx.Commit()
+}
+
+// PatchWorkout updates only the fields present in the patch.
+func (s *DatabaseService) PatchWorkout(userID, workoutID int, patch *models.WorkoutPatch) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkWorkoutOwner(tx, userID, workoutID); err != nil {
+		return err
+	}
+
+	if patch.Name != nil {
+		if _, err := tx.Exec("UPDATE workouts SET workout_name = ? WHERE id = ?", *patch.Name, workoutID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteWorkout removes a workout together with its exercises and sets.
+func (s *DatabaseService) DeleteWorkout(userID, workoutID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkWorkoutOwner(tx, userID, workoutID); err != nil {
+		return err
+	}
+	if err := deleteWorkoutChildren(tx, workoutID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM personal_records WHERE workout_id = ?", workoutID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM workout_imports WHERE workout_id = ?", workoutID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM workouts WHERE id = ?", workoutID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSetHistory returns every set the user has logged, oldest first. When exercise is
+// not empty only sets of that exercise, given by name or any catalog alias, are returned.
+func (s *DatabaseService) GetSetHistory(userID int, exercise string) ([]models.LoggedSet, error) {
+	query := `
+		SELECT s.id, w.id, e.id, e.exercise, COALESCE(e.catalog_id, 0), s.reps, s.weight, s.rpe, s.set_number, s.set_type, s.completed,
+			s.duration_seconds, s.distance_meters, s.calories, w.created_at
+		FROM sets s
+		JOIN exercises e on s.exercise_id = e.id
+		JOIN workouts w on e.workout_id = w.id
+		WHERE w.user_id = ?`
+	args := []interface{}{userID}
+	if exercise != "" {
+		match, matchArgs, err := exerciseMatch(s.db, userID, exercise)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND " + match
+		args = append(args, matchArgs...)
+	}
+	query += " ORDER BY w.created_at, w.id, e.id, s.set_number"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.LoggedSet
+	for rows.Next() {
+		var set models.LoggedSet
+		err := rows.Scan(&set.SetID, &set.WorkoutID, &set.ExerciseID, &set.Exercise, &set.CatalogID, &set.Reps, &set.Weight, &set.RPE, &set.SetNumber, &set.Type, &set.Completed,
+			&set.DurationSeconds, &set.DistanceMeters, &set.Calories, &set.PerformedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, set)
+	}
+	return history, rows.Err()
+}
+
+// GetExercise returns a single exercise of a workout owned by userID.
+func (s *DatabaseService) GetExercise(userID, workoutID, exerciseID int) (*models.ExerciseLog, error) {
+	var exercise models.ExerciseLog
+	err := s.db.QueryRow(`
+		SELECT e.id, e.exercise, COALESCE(e.catalog_id, 0), e.group_label, e.workout_id, e.created_at FROM exercises e
+		JOIN workouts w on e.workout_id = w.id
+		WHERE e.id = ? AND w.id = ? AND w.user_id = ?
+	`, exerciseID, workoutID, userID).Scan(&exercise.ID, &exercise.Exercise, &exercise.CatalogID, &exercise.Group, &exercise.WorkoutID, &exercise.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	exercise.Unlinked = exercise.CatalogID == 0
+
+	exercise.Sets, err = s.loadSets(exercise.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &exercise, nil
+}
+
+// ReplaceExercise overwrites the exercise, resolved against the catalog, and all of its
+// sets. The exercise stays in its group; groups change with the whole workout.
+func (s *DatabaseService) ReplaceExercise(userID, workoutID, exerciseID int, exercise *models.ExerciseLog) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkExerciseOwner(tx, userID, workoutID, exerciseID); err != nil {
+		return err
+	}
+
+	if err := linkExercise(tx, userID, exercise); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE exercises SET exercise = ?, catalog_id = ? WHERE id = ?", exercise.Exercise, catalogRef(exercise), exerciseID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM sets WHERE exercise_id = ?", exerciseID); err != nil {
+		return err
+	}
+	if err := insertSets(tx, exerciseID, exercise.Sets); err != nil {
+		return err
+	}
+	exercise.ID = exerciseID
+	exercise.WorkoutID = workoutID
+
+	return tx.Commit()
+}
+
+// PatchExercise updates only the fields present in the patch.
+func (s *DatabaseService) PatchExercise(userID, workoutID, exerciseID int, patch *models.ExercisePatch) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkExerciseOwner(tx, userID, workoutID, exerciseID); err != nil {
+		return err
+	}
+
+	if patch.Exercise != nil || patch.CatalogID != nil {
+		var exercise models.ExerciseLog
+		if patch.CatalogID != nil {
+			exercise.CatalogID = *patch.CatalogID
+		} else {
+			exercise.Exercise = *patch.Exercise
+		}
+		if err := linkExercise(tx, userID, &exercise); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE exercises SET exercise = ?, catalog_id = ? WHERE id = ?", exercise.Exercise, catalogRef(&exercise), exerciseID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteExercise removes an exercise and its sets from a workout.
+func (s *DatabaseService) DeleteExercise(userID, workoutID, exerciseID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkExerciseOwner(tx, userID, workoutID, exerciseID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM sets WHERE exercise_id = ?", exerciseID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM exercises WHERE id = ?", exerciseID); err != nil {
+		return err
+	}
+	if err := pruneWorkoutGroups(tx, workoutID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSet returns a single set of an exercise owned by userID.
+func (s *DatabaseService) GetSet(userID, workoutID, exerciseID, setID int) (*models.Set, error) {
+	set, err := scanSet(s.db.QueryRow(`
+		SELECT `+setColumns+` FROM sets s
+		JOIN exercises e on s.exercise_id = e.id
+		JOIN workouts w on e.workout_id = w.id
+		WHERE s.id = ? AND e.id = ? AND w.id = ? AND w.user_id = ?
+	`, setID, exerciseID, workoutID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return set, nil
+}
+
+// ReplaceSet overwrites the values of a set, keeping its position in the exercise.
+func (s *DatabaseService) ReplaceSet(userID, workoutID, exerciseID, setID int, set *models.Set) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkSetOwner(tx, userID, workoutID, exerciseID, setID); err != nil {
+		return err
+	}
+	if err := updateSet(tx, exerciseID, setID, set); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PatchSet updates only the fields present in the patch. A new RIR without an RPE
+// replaces the stored RPE with the one derived from it.
+func (s *DatabaseService) PatchSet(userID, workoutID, exerciseID, setID int, patch *models.SetPatch) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkSetOwner(tx, userID, workoutID, exerciseID, setID); err != nil {
+		return err
+	}
+	set, err := scanSet(tx.QueryRow("SELECT "+setColumns+" FROM sets s WHERE s.id = ?", setID))
+	if err != nil {
+		return err
+	}
+
+	if patch.Reps != nil {
+		set.Reps = *patch.Reps
+	}
+	if patch.Weight != nil {
+		set.Weight = *patch.Weight
+	}
+	if patch.RIR != nil {
+		set.RIR = patch.RIR
+		if patch.RPE == nil {
+			set.RPE = 0
+		}
+	}
+	if patch.RPE != nil {
+		set.RPE = *patch.RPE
+	}
+	if patch.Type != nil {
+		set.Type = *patch.Type
+	}
+	if patch.Tempo != nil {
+		set.Tempo = *patch.Tempo
+	}
+	if patch.Remarks != nil {
+		set.Remarks = *patch.Remarks
+	}
+	if patch.Completed != nil {
+		set.Completed = patch.Completed
+	}
+	if patch.DurationSeconds != nil {
+		set.DurationSeconds = *patch.DurationSeconds
+	}
+	if patch.DistanceMeters != nil {
+		set.DistanceMeters = *patch.DistanceMeters
+	}
+	if patch.AvgHeartRate != nil {
+		set.AvgHeartRate = *patch.AvgHeartRate
+	}
+	if patch.MaxHeartRate != nil {
+		set.MaxHeartRate = *patch.MaxHeartRate
+	}
+	if patch.Calories != nil {
+		set.Calories = *patch.Calories
+	}
+	//pace is derived from the stored values, so it must not fill in a cleared duration
+	set.PaceSecondsPerKm = 0
+	if err := updateSet(tx, exerciseID, setID, set); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteSet removes a set and renumbers the remaining sets of the exercise.
+func (s *DatabaseService) DeleteSet(userID, workoutID, exerciseID, setID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkSetOwner(tx, userID, workoutID, exerciseID, setID); err != nil {
+		return err
+	}
+
+	var setNumber int
+	if err := tx.QueryRow("SELECT set_number FROM sets WHERE id = ?", setID).Scan(&setNumber); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM sets WHERE id = ?", setID); err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE sets SET set_number = set_number - 1 WHERE exercise_id = ? AND set_number > ?", exerciseID, setNumber)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func checkWorkoutOwner(tx *Tx, userID, workoutID int) error {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM workouts WHERE id = ? AND user_id = ?", workoutID, userID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func checkExerciseOwner(tx *Tx, userID, workoutID, exerciseID int) error {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM exercises e
+		JOIN workouts w on e.workout_id = w.id
+		WHERE e.id = ? AND w.id = ? AND w.user_id = ?
+	`, exerciseID, workoutID, userID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func checkSetOwner(tx *Tx, userID, workoutID, exerciseID, setID int) error {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM sets s
+		JOIN exercises e on s.exercise_id = e.id
+		JOIN workouts w on e.workout_id = w.id
+		WHERE s.id = ? AND e.id = ? AND w.id = ? AND w.user_id = ?
+	`, setID, exerciseID, workoutID, userID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// deleteWorkoutChildren removes every group, exercise and set that hangs off a workout.
+func deleteWorkoutChildren(tx *Tx, workoutID int) error {
+	_, err := tx.Exec("DELETE FROM sets WHERE exercise_id IN (SELECT id FROM exercises WHERE workout_id = ?)", workoutID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM workout_groups WHERE workout_id = ?", workoutID); err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM exercises WHERE workout_id = ?", workoutID)
+	return err
+}